@@ -10,6 +10,8 @@ import (
 type CleanCandidate struct {
 	Text        string
 	IssueNumber uint64
+	// ID is the hidden ID of the item, or empty if it has none
+	ID string
 }
 
 // NewCleanCandidate converts a checked TodoItem with an issue number into a CleanCandidate.
@@ -19,6 +21,7 @@ func NewCleanCandidate(item todo.TodoItem) (CleanCandidate, bool) {
 		return CleanCandidate{
 			Text:        item.Text,
 			IssueNumber: *item.IssueNumber,
+			ID:          item.ID,
 		}, true
 	}
 	return CleanCandidate{}, false
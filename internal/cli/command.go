@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+
+	"github.com/toms74209200/gh-atat/internal/config"
 )
 
 // Command represents CLI commands
@@ -22,17 +24,42 @@ type Whoami struct{}
 func (Whoami) command() {}
 
 // Push command
-type Push struct{}
+type Push struct {
+	Orphans  OrphanAction
+	Strategy ConflictStrategy
+	File     string
+	Config   ConfigOverrides
+	JSON     bool
+}
 
 func (Push) command() {}
 
 // Pull command
-type Pull struct{}
+type Pull struct {
+	Orphans  OrphanAction
+	Strategy ConflictStrategy
+	File     string
+	Config   ConfigOverrides
+	JSON     bool
+}
 
 func (Pull) command() {}
 
+// Sync command
+type Sync struct {
+	Orphans  OrphanAction
+	Strategy ConflictStrategy
+	File     string
+	Config   ConfigOverrides
+	JSON     bool
+}
+
+func (Sync) command() {}
+
 // RemoteList command
-type RemoteList struct{}
+type RemoteList struct {
+	JSON bool
+}
 
 func (RemoteList) command() {}
 
@@ -53,10 +80,73 @@ func (RemoteRemove) command() {}
 // Clean command
 type Clean struct {
 	DryRun bool
+	File   string
+	Config ConfigOverrides
+	JSON   bool
 }
 
 func (Clean) command() {}
 
+// Status command
+type Status struct {
+	Overdue bool
+	File    string
+	Config  ConfigOverrides
+	JSON    bool
+}
+
+func (Status) command() {}
+
+// ConfigList command
+type ConfigList struct {
+	Scope ConfigScope
+	JSON  bool
+}
+
+func (ConfigList) command() {}
+
+// ConfigGet command
+type ConfigGet struct {
+	Key   string
+	Scope ConfigScope
+	JSON  bool
+}
+
+func (ConfigGet) command() {}
+
+// ConfigSet command
+type ConfigSet struct {
+	Key   string
+	Value string
+	Scope ConfigScope
+}
+
+func (ConfigSet) command() {}
+
+// ConfigUnset command
+type ConfigUnset struct {
+	Key   string
+	Scope ConfigScope
+}
+
+func (ConfigUnset) command() {}
+
+// Undo command
+type Undo struct {
+	Force bool
+	JSON  bool
+}
+
+func (Undo) command() {}
+
+// Log command
+type Log struct {
+	JSON  bool
+	Since string
+}
+
+func (Log) command() {}
+
 // Version command
 type Version struct{}
 
@@ -74,9 +164,95 @@ type Unknown struct {
 
 func (Unknown) command() {}
 
+// OrphanAction is what to do with items referencing issues that no longer exist
+type OrphanAction string
+
+const (
+	// OrphansKeep keeps the items and reports them
+	OrphansKeep OrphanAction = "keep"
+	// OrphansUnlink removes the issue reference from the items
+	OrphansUnlink OrphanAction = "unlink"
+	// OrphansRemove removes the items
+	OrphansRemove OrphanAction = "remove"
+)
+
+// ConflictStrategy is how to settle a task whose text and issue title differ
+// when neither side can be taken automatically
+type ConflictStrategy string
+
+const (
+	// StrategyDefault asks which side to keep when a terminal is attached,
+	// and otherwise keeps both sides and reports the conflict
+	StrategyDefault ConflictStrategy = ""
+	// StrategyOurs keeps the task text and renames the issue
+	StrategyOurs ConflictStrategy = "ours"
+	// StrategyTheirs keeps the issue title and updates the task text
+	StrategyTheirs ConflictStrategy = "theirs"
+	// StrategyFail stops the command without changing anything for the file
+	StrategyFail ConflictStrategy = "fail"
+)
+
+// ConflictChoice is an answer to the prompt asking how to settle a conflict
+type ConflictChoice string
+
+const (
+	// ChoiceLocal keeps the task text
+	ChoiceLocal ConflictChoice = "local"
+	// ChoiceRemote keeps the issue title
+	ChoiceRemote ConflictChoice = "remote"
+	// ChoiceEdit sets a new title on both sides
+	ChoiceEdit ConflictChoice = "edit"
+	// ChoiceSkip leaves the conflict unresolved
+	ChoiceSkip ConflictChoice = "skip"
+)
+
+// ConfigScope is the configuration file a config subcommand works on
+type ConfigScope string
+
+const (
+	// ConfigScopeDefault reads the merged configuration and writes the project configuration
+	ConfigScopeDefault ConfigScope = ""
+	// ConfigScopeGlobal works on the user configuration
+	ConfigScopeGlobal ConfigScope = "global"
+	// ConfigScopeLocal works on the project configuration
+	ConfigScopeLocal ConfigScope = "local"
+)
+
+// ConfigOverrides are configuration values given by command line flags, as they were given
+type ConfigOverrides map[config.ConfigKey]string
+
 // validRemoteSubcommands contains valid remote subcommands
 var validRemoteSubcommands = []string{"add", "remove"}
 
+// flagSpec describes a flag accepted by a command
+type flagSpec struct {
+	name     string
+	hasValue bool
+}
+
+// commandFlags contains the flags accepted by each command.
+// Commands reading the configuration also accept a flag for each configuration key.
+var commandFlags = map[string][]flagSpec{
+	"push":   append([]flagSpec{{name: "orphans", hasValue: true}, {name: "strategy", hasValue: true}, {name: "file", hasValue: true}, {name: "json"}}, configFlags()...),
+	"pull":   append([]flagSpec{{name: "orphans", hasValue: true}, {name: "strategy", hasValue: true}, {name: "file", hasValue: true}, {name: "json"}}, configFlags()...),
+	"sync":   append([]flagSpec{{name: "orphans", hasValue: true}, {name: "strategy", hasValue: true}, {name: "file", hasValue: true}, {name: "json"}}, configFlags()...),
+	"clean":  append([]flagSpec{{name: "dry-run"}, {name: "file", hasValue: true}, {name: "json"}}, configFlags()...),
+	"status": append([]flagSpec{{name: "overdue"}, {name: "file", hasValue: true}, {name: "json"}}, configFlags()...),
+	"remote": {{name: "json"}},
+	"config": {{name: "global"}, {name: "local"}, {name: "json"}},
+	"undo":   {{name: "force"}, {name: "json"}},
+	"log":    {{name: "json"}, {name: "since", hasValue: true}},
+}
+
+// configFlags returns the flags overriding configuration keys
+func configFlags() []flagSpec {
+	var specs []flagSpec
+	for _, key := range config.AllConfigKeys() {
+		specs = append(specs, flagSpec{name: config.FlagName(key), hasValue: true})
+	}
+	return specs
+}
+
 // ParseArgs parses command line arguments and returns a Command
 //
 // Arguments:
@@ -85,6 +261,35 @@ var validRemoteSubcommands = []string{"add", "remove"}
 // Returns:
 //   - Command: The parsed command
 func ParseArgs(args []string) Command {
+	flags := make(map[string]string)
+	if len(args) > 2 {
+		if specs, ok := commandFlags[args[1]]; ok {
+			positional, parsed, err := extractFlags(specs, args[2:])
+			if err != nil {
+				return Unknown{Message: err.Error()}
+			}
+			args = append([]string{args[0], args[1]}, positional...)
+			flags = parsed
+		}
+	}
+
+	orphans, err := parseOrphanAction(flags)
+	if err != nil {
+		return Unknown{Message: err.Error()}
+	}
+	strategy, err := parseConflictStrategy(flags)
+	if err != nil {
+		return Unknown{Message: err.Error()}
+	}
+	file := flags["file"]
+	overrides := parseConfigOverrides(flags)
+	_, jsonOutput := flags["json"]
+
+	scope, err := parseConfigScope(flags)
+	if err != nil {
+		return Unknown{Message: err.Error()}
+	}
+
 	switch len(args) {
 	case 0, 1:
 		return Help{}
@@ -95,13 +300,26 @@ func ParseArgs(args []string) Command {
 		case "whoami":
 			return Whoami{}
 		case "push":
-			return Push{}
+			return Push{Orphans: orphans, Strategy: strategy, File: file, Config: overrides, JSON: jsonOutput}
 		case "pull":
-			return Pull{}
+			return Pull{Orphans: orphans, Strategy: strategy, File: file, Config: overrides, JSON: jsonOutput}
+		case "sync":
+			return Sync{Orphans: orphans, Strategy: strategy, File: file, Config: overrides, JSON: jsonOutput}
 		case "clean":
-			return Clean{DryRun: false}
+			_, dryRun := flags["dry-run"]
+			return Clean{DryRun: dryRun, File: file, Config: overrides, JSON: jsonOutput}
+		case "status":
+			_, overdue := flags["overdue"]
+			return Status{Overdue: overdue, File: file, Config: overrides, JSON: jsonOutput}
+		case "undo":
+			_, force := flags["force"]
+			return Undo{Force: force, JSON: jsonOutput}
+		case "log":
+			return Log{JSON: jsonOutput, Since: flags["since"]}
 		case "remote":
-			return RemoteList{}
+			return RemoteList{JSON: jsonOutput}
+		case "config":
+			return ConfigList{Scope: scope, JSON: jsonOutput}
 		case "help":
 			return Help{}
 		case "--version":
@@ -110,9 +328,6 @@ func ParseArgs(args []string) Command {
 			return Unknown{Message: args[1]}
 		}
 	case 3:
-		if args[1] == "clean" && args[2] == "--dry-run" {
-			return Clean{DryRun: true}
-		}
 		if args[1] == "remote" {
 			subCmd := args[2]
 			if slices.Contains(validRemoteSubcommands, subCmd) {
@@ -122,6 +337,17 @@ func ParseArgs(args []string) Command {
 			}
 			return Unknown{Message: fmt.Sprintf("remote %s", subCmd)}
 		}
+		if args[1] == "config" {
+			switch args[2] {
+			case "list":
+				return ConfigList{Scope: scope, JSON: jsonOutput}
+			case "get", "unset":
+				return Unknown{Message: fmt.Sprintf("Missing key argument. Usage: atat config %s <key>", args[2])}
+			case "set":
+				return Unknown{Message: "Missing key argument. Usage: atat config set <key> <value>"}
+			}
+			return Unknown{Message: fmt.Sprintf("config %s", args[2])}
+		}
 		return Unknown{Message: args[1]}
 	default:
 		// 4 or more arguments
@@ -145,6 +371,165 @@ func ParseArgs(args []string) Command {
 			}
 			return Unknown{Message: "Invalid repository format. Please use <owner>/<repo>."}
 		}
+		if args[1] == "config" {
+			return parseConfigSubcommand(args[2], args[3:], scope, jsonOutput)
+		}
 		return Unknown{Message: fmt.Sprintf("%s %s", args[1], args[2])}
 	}
 }
+
+// extractFlags separates flags from positional arguments.
+// Flags are written as --name, or as --name=value and --name value for flags with a value.
+func extractFlags(specs []flagSpec, args []string) ([]string, map[string]string, error) {
+	var positional []string
+	flags := make(map[string]string)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") {
+			positional = append(positional, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		index := slices.IndexFunc(specs, func(spec flagSpec) bool { return spec.name == name })
+		if index < 0 {
+			return nil, nil, fmt.Errorf("unknown flag: --%s", name)
+		}
+
+		spec := specs[index]
+		switch {
+		case !spec.hasValue && hasValue:
+			return nil, nil, fmt.Errorf("flag --%s does not take a value", name)
+		case spec.hasValue && !hasValue:
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("flag --%s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		flags[name] = value
+	}
+
+	return positional, flags, nil
+}
+
+// parseOrphanAction returns the action given by the --orphans flag, defaulting to keep
+func parseOrphanAction(flags map[string]string) (OrphanAction, error) {
+	value, ok := flags["orphans"]
+	if !ok {
+		return OrphansKeep, nil
+	}
+
+	action := OrphanAction(value)
+	switch action {
+	case OrphansKeep, OrphansUnlink, OrphansRemove:
+		return action, nil
+	default:
+		return "", fmt.Errorf("invalid value for --orphans: %s. Use keep, unlink or remove", value)
+	}
+}
+
+// parseConflictStrategy returns the strategy given by the --strategy flag
+func parseConflictStrategy(flags map[string]string) (ConflictStrategy, error) {
+	value, ok := flags["strategy"]
+	if !ok {
+		return StrategyDefault, nil
+	}
+
+	strategy := ConflictStrategy(value)
+	switch strategy {
+	case StrategyOurs, StrategyTheirs, StrategyFail:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid value for --strategy: %s. Use ours, theirs or fail", value)
+	}
+}
+
+// ParseConflictChoice parses an answer to the conflict prompt, such as "l" or "local".
+// Returns false if the answer is not a choice.
+func ParseConflictChoice(input string) (ConflictChoice, bool) {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "l", "local":
+		return ChoiceLocal, true
+	case "r", "remote":
+		return ChoiceRemote, true
+	case "e", "edit":
+		return ChoiceEdit, true
+	case "s", "skip":
+		return ChoiceSkip, true
+	default:
+		return "", false
+	}
+}
+
+// ParseConfirmation parses an answer to a yes/no prompt that defaults to no.
+// Returns false as the second value if the answer is neither.
+func ParseConfirmation(input string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true, true
+	case "", "n", "no":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// parseConfigOverrides returns the configuration values given by flags, or nil if there are none
+func parseConfigOverrides(flags map[string]string) ConfigOverrides {
+	var overrides ConfigOverrides
+	for _, key := range config.AllConfigKeys() {
+		if value, ok := flags[config.FlagName(key)]; ok {
+			if overrides == nil {
+				overrides = make(ConfigOverrides)
+			}
+			overrides[key] = value
+		}
+	}
+	return overrides
+}
+
+// parseConfigSubcommand parses a config subcommand with its key and value arguments
+func parseConfigSubcommand(subCmd string, rest []string, scope ConfigScope, jsonOutput bool) Command {
+	switch subCmd {
+	case "get":
+		if len(rest) == 1 {
+			return ConfigGet{Key: rest[0], Scope: scope, JSON: jsonOutput}
+		}
+		return Unknown{Message: "Too many arguments. Usage: atat config get <key>"}
+	case "unset":
+		if len(rest) == 1 {
+			return ConfigUnset{Key: rest[0], Scope: scope}
+		}
+		return Unknown{Message: "Too many arguments. Usage: atat config unset <key>"}
+	case "set":
+		switch len(rest) {
+		case 1:
+			return Unknown{Message: "Missing value argument. Usage: atat config set <key> <value>"}
+		case 2:
+			return ConfigSet{Key: rest[0], Value: rest[1], Scope: scope}
+		default:
+			return Unknown{Message: "Too many arguments. Usage: atat config set <key> <value>"}
+		}
+	default:
+		return Unknown{Message: fmt.Sprintf("config %s", subCmd)}
+	}
+}
+
+// parseConfigScope returns the scope given by the --global and --local flags
+func parseConfigScope(flags map[string]string) (ConfigScope, error) {
+	_, global := flags["global"]
+	_, local := flags["local"]
+
+	switch {
+	case global && local:
+		return "", fmt.Errorf("--global and --local cannot be used together")
+	case global:
+		return ConfigScopeGlobal, nil
+	case local:
+		return ConfigScopeLocal, nil
+	default:
+		return ConfigScopeDefault, nil
+	}
+}
@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/toms74209200/gh-atat/internal/config"
 )
 
 func TestParseLoginCommand(t *testing.T) {
@@ -327,6 +330,289 @@ func TestParseCleanDryRunCommand(t *testing.T) {
 	}
 }
 
+func TestParseStatusCommand(t *testing.T) {
+	args := []string{"program", "status"}
+	result := ParseArgs(args)
+	cmd, ok := result.(Status)
+	if !ok {
+		t.Fatalf("Expected Status, got %T", result)
+	}
+	if cmd.Overdue {
+		t.Error("Expected Overdue to be false")
+	}
+}
+
+func TestParseStatusOverdueCommand(t *testing.T) {
+	args := []string{"program", "status", "--overdue"}
+	result := ParseArgs(args)
+	cmd, ok := result.(Status)
+	if !ok {
+		t.Fatalf("Expected Status, got %T", result)
+	}
+	if !cmd.Overdue {
+		t.Error("Expected Overdue to be true")
+	}
+}
+
+func TestParsePushOrphansDefaultsToKeep(t *testing.T) {
+	args := []string{"program", "push"}
+	result := ParseArgs(args)
+	cmd, ok := result.(Push)
+	if !ok {
+		t.Fatalf("Expected Push, got %T", result)
+	}
+	if cmd.Orphans != OrphansKeep {
+		t.Errorf("Expected Orphans 'keep', got '%s'", cmd.Orphans)
+	}
+}
+
+func TestParsePushOrphansFlag(t *testing.T) {
+	args := []string{"program", "push", "--orphans=unlink"}
+	result := ParseArgs(args)
+	cmd, ok := result.(Push)
+	if !ok {
+		t.Fatalf("Expected Push, got %T", result)
+	}
+	if cmd.Orphans != OrphansUnlink {
+		t.Errorf("Expected Orphans 'unlink', got '%s'", cmd.Orphans)
+	}
+}
+
+func TestParsePullOrphansFlagWithSeparateValue(t *testing.T) {
+	args := []string{"program", "pull", "--orphans", "remove"}
+	result := ParseArgs(args)
+	cmd, ok := result.(Pull)
+	if !ok {
+		t.Fatalf("Expected Pull, got %T", result)
+	}
+	if cmd.Orphans != OrphansRemove {
+		t.Errorf("Expected Orphans 'remove', got '%s'", cmd.Orphans)
+	}
+}
+
+func TestParseConflictStrategy(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected Command
+	}{
+		{[]string{"program", "push"}, Push{Orphans: OrphansKeep, Strategy: StrategyDefault}},
+		{[]string{"program", "push", "--strategy=ours"}, Push{Orphans: OrphansKeep, Strategy: StrategyOurs}},
+		{[]string{"program", "pull", "--strategy", "theirs"}, Pull{Orphans: OrphansKeep, Strategy: StrategyTheirs}},
+		{[]string{"program", "pull", "--strategy=fail", "--orphans=unlink"}, Pull{Orphans: OrphansUnlink, Strategy: StrategyFail}},
+	}
+
+	for _, tt := range tests {
+		result := ParseArgs(tt.args)
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("ParseArgs(%v): expected %+v, got %+v", tt.args, tt.expected, result)
+		}
+	}
+}
+
+func TestParseSync(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected Command
+	}{
+		{[]string{"program", "sync"}, Sync{Orphans: OrphansKeep}},
+		{
+			[]string{"program", "sync", "--strategy=theirs", "--orphans=unlink", "--file", "TODO.md", "--json"},
+			Sync{Orphans: OrphansUnlink, Strategy: StrategyTheirs, File: "TODO.md", JSON: true},
+		},
+		{
+			[]string{"program", "sync", "--repositories=owner/repo"},
+			Sync{Orphans: OrphansKeep, Config: ConfigOverrides{config.Repositories: "owner/repo"}},
+		},
+	}
+
+	for _, tt := range tests {
+		result := ParseArgs(tt.args)
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("ParseArgs(%v): expected %+v, got %+v", tt.args, tt.expected, result)
+		}
+	}
+}
+
+func TestParseInvalidConflictStrategy(t *testing.T) {
+	result := ParseArgs([]string{"program", "push", "--strategy=mine"})
+	cmd, ok := result.(Unknown)
+	if !ok {
+		t.Fatalf("Expected Unknown, got %T", result)
+	}
+	expected := "invalid value for --strategy: mine. Use ours, theirs or fail"
+	if cmd.Message != expected {
+		t.Errorf("Expected message %q, got %q", expected, cmd.Message)
+	}
+}
+
+func TestParseConflictStrategyNotOnClean(t *testing.T) {
+	result := ParseArgs([]string{"program", "clean", "--strategy=ours"})
+	if _, ok := result.(Unknown); !ok {
+		t.Errorf("Expected Unknown, got %T", result)
+	}
+}
+
+func TestParseConflictChoice(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ConflictChoice
+		ok       bool
+	}{
+		{"l", ChoiceLocal, true},
+		{"Local\n", ChoiceLocal, true},
+		{"r", ChoiceRemote, true},
+		{" remote ", ChoiceRemote, true},
+		{"e", ChoiceEdit, true},
+		{"s", ChoiceSkip, true},
+		{"", "", false},
+		{"x", "", false},
+	}
+
+	for _, tt := range tests {
+		choice, ok := ParseConflictChoice(tt.input)
+		if choice != tt.expected || ok != tt.ok {
+			t.Errorf("ParseConflictChoice(%q): expected (%q, %v), got (%q, %v)", tt.input, tt.expected, tt.ok, choice, ok)
+		}
+	}
+}
+
+func TestParseConfirmation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+		ok       bool
+	}{
+		{"y", true, true},
+		{"Yes\n", true, true},
+		{"n", false, true},
+		{"\n", false, true},
+		{"maybe", false, false},
+	}
+
+	for _, tt := range tests {
+		confirmed, ok := ParseConfirmation(tt.input)
+		if confirmed != tt.expected || ok != tt.ok {
+			t.Errorf("ParseConfirmation(%q): expected (%v, %v), got (%v, %v)", tt.input, tt.expected, tt.ok, confirmed, ok)
+		}
+	}
+}
+
+func TestParseFileFlag(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected Command
+	}{
+		{[]string{"program", "push", "--file", "docs/ROADMAP.md"}, Push{Orphans: OrphansKeep, File: "docs/ROADMAP.md"}},
+		{[]string{"program", "pull", "--file=TODO.md", "--orphans=unlink"}, Pull{Orphans: OrphansUnlink, File: "TODO.md"}},
+		{[]string{"program", "clean", "--dry-run", "--file", "pkg/TODO.md"}, Clean{DryRun: true, File: "pkg/TODO.md"}},
+		{[]string{"program", "status", "--file=TODO.md"}, Status{File: "TODO.md"}},
+	}
+
+	for _, tt := range tests {
+		result := ParseArgs(tt.args)
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("ParseArgs(%v): expected %+v, got %+v", tt.args, tt.expected, result)
+		}
+	}
+}
+
+func TestParseConfigOverrideFlags(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected Command
+	}{
+		{
+			[]string{"program", "push", "--repositories", "owner/repo"},
+			Push{Orphans: OrphansKeep, Config: ConfigOverrides{config.Repositories: "owner/repo"}},
+		},
+		{
+			[]string{"program", "pull", "--due=milestone", "--merged-pull-requests", "done"},
+			Pull{Orphans: OrphansKeep, Config: ConfigOverrides{config.Due: "milestone", config.MergedPullRequests: "done"}},
+		},
+		{
+			[]string{"program", "status", "--files", "TODO.md,docs/TODO.md", "--overdue"},
+			Status{Overdue: true, Config: ConfigOverrides{config.Files: "TODO.md,docs/TODO.md"}},
+		},
+	}
+
+	for _, tt := range tests {
+		result := ParseArgs(tt.args)
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("ParseArgs(%v): expected %+v, got %+v", tt.args, tt.expected, result)
+		}
+	}
+}
+
+func TestParseConfigOverrideFlagNotOnConfigCommand(t *testing.T) {
+	result := ParseArgs([]string{"program", "config", "--due", "milestone"})
+	cmd, ok := result.(Unknown)
+	if !ok {
+		t.Fatalf("Expected Unknown, got %T", result)
+	}
+	expected := "unknown flag: --due"
+	if cmd.Message != expected {
+		t.Errorf("Expected message '%s', got '%s'", expected, cmd.Message)
+	}
+}
+
+func TestParseHistoryCommands(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected Command
+	}{
+		{[]string{"program", "undo"}, Undo{}},
+		{[]string{"program", "undo", "--force"}, Undo{Force: true}},
+		{[]string{"program", "log"}, Log{}},
+		{[]string{"program", "log", "--json", "--since", "7d"}, Log{JSON: true, Since: "7d"}},
+	}
+
+	for _, tt := range tests {
+		result := ParseArgs(tt.args)
+		if result != tt.expected {
+			t.Errorf("ParseArgs(%v): expected %+v, got %+v", tt.args, tt.expected, result)
+		}
+	}
+}
+
+func TestParseOrphansFlagInvalidValue(t *testing.T) {
+	args := []string{"program", "push", "--orphans=delete"}
+	result := ParseArgs(args)
+	cmd, ok := result.(Unknown)
+	if !ok {
+		t.Fatalf("Expected Unknown, got %T", result)
+	}
+	expected := "invalid value for --orphans: delete. Use keep, unlink or remove"
+	if cmd.Message != expected {
+		t.Errorf("Expected message '%s', got '%s'", expected, cmd.Message)
+	}
+}
+
+func TestParseOrphansFlagMissingValue(t *testing.T) {
+	args := []string{"program", "pull", "--orphans"}
+	result := ParseArgs(args)
+	cmd, ok := result.(Unknown)
+	if !ok {
+		t.Fatalf("Expected Unknown, got %T", result)
+	}
+	expected := "flag --orphans requires a value"
+	if cmd.Message != expected {
+		t.Errorf("Expected message '%s', got '%s'", expected, cmd.Message)
+	}
+}
+
+func TestParseUnknownFlag(t *testing.T) {
+	args := []string{"program", "clean", "--force"}
+	result := ParseArgs(args)
+	cmd, ok := result.(Unknown)
+	if !ok {
+		t.Fatalf("Expected Unknown, got %T", result)
+	}
+	expected := "unknown flag: --force"
+	if cmd.Message != expected {
+		t.Errorf("Expected message '%s', got '%s'", expected, cmd.Message)
+	}
+}
+
 func TestParseVersionCommand(t *testing.T) {
 	args := []string{"program", "--version"}
 	result := ParseArgs(args)
@@ -347,3 +633,51 @@ func TestParseNonRemoteCommandWithFourOrMoreArgs(t *testing.T) {
 		t.Errorf("Expected message '%s', got '%s'", expected, cmd.Message)
 	}
 }
+
+func TestParseConfigCommands(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected Command
+	}{
+		{[]string{"program", "config"}, ConfigList{}},
+		{[]string{"program", "config", "list", "--global"}, ConfigList{Scope: ConfigScopeGlobal}},
+		{[]string{"program", "config", "get", "due"}, ConfigGet{Key: "due"}},
+		{[]string{"program", "config", "--local", "get", "due"}, ConfigGet{Key: "due", Scope: ConfigScopeLocal}},
+		{[]string{"program", "config", "set", "repositories", "owner/repo"}, ConfigSet{Key: "repositories", Value: "owner/repo"}},
+		{[]string{"program", "config", "set", "--global", "due", "milestone"}, ConfigSet{Key: "due", Value: "milestone", Scope: ConfigScopeGlobal}},
+		{[]string{"program", "config", "unset", "due"}, ConfigUnset{Key: "due"}},
+	}
+
+	for _, tt := range tests {
+		result := ParseArgs(tt.args)
+		if result != tt.expected {
+			t.Errorf("ParseArgs(%v): expected %+v, got %+v", tt.args, tt.expected, result)
+		}
+	}
+}
+
+func TestParseConfigCommandErrors(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"program", "config", "get"}, "Missing key argument. Usage: atat config get <key>"},
+		{[]string{"program", "config", "set", "due"}, "Missing value argument. Usage: atat config set <key> <value>"},
+		{[]string{"program", "config", "set", "due", "a", "b"}, "Too many arguments. Usage: atat config set <key> <value>"},
+		{[]string{"program", "config", "rename", "a", "b"}, "config rename"},
+		{[]string{"program", "config", "--global", "--local", "list"}, "--global and --local cannot be used together"},
+		{[]string{"program", "config", "list", "--system"}, "unknown flag: --system"},
+	}
+
+	for _, tt := range tests {
+		result := ParseArgs(tt.args)
+		cmd, ok := result.(Unknown)
+		if !ok {
+			t.Errorf("ParseArgs(%v): expected Unknown, got %T", tt.args, result)
+			continue
+		}
+		if cmd.Message != tt.expected {
+			t.Errorf("ParseArgs(%v): expected message '%s', got '%s'", tt.args, tt.expected, cmd.Message)
+		}
+	}
+}
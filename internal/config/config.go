@@ -12,6 +12,86 @@ type ConfigKey string
 const (
 	// Repositories is the key for repository configuration
 	Repositories ConfigKey = "repositories"
+	// Due is the key for the due date synchronization target
+	Due ConfigKey = "due"
+	// Projects is the key for GitHub Projects v2 board configuration
+	Projects ConfigKey = "projects"
+	// MergedPullRequests is the key for how merged pull requests affect their tasks
+	MergedPullRequests ConfigKey = "merged_pull_requests"
+	// Files is the key for the TODO files tracked by the project
+	Files ConfigKey = "files"
+	// Match is the key for how todo items are linked to existing issues
+	Match ConfigKey = "match"
+	// InlineCode is the key for how code spans in tasks appear in issue titles
+	InlineCode ConfigKey = "inline_code"
+	// IgnoreSections is the key for the headings whose tasks are not synced
+	IgnoreSections ConfigKey = "ignore_sections"
+	// ItemIDs is the key for how hidden IDs are added to tasks
+	ItemIDs ConfigKey = "item_ids"
+	// Placement is the key for where tasks of pulled issues are placed
+	Placement ConfigKey = "placement"
+	// Order is the key for the order of tasks of pulled issues
+	Order ConfigKey = "order"
+	// PriorityLabels is the key for the labels giving the priority of issues
+	PriorityLabels ConfigKey = "priority_labels"
+)
+
+// Values for the Due configuration key
+const (
+	// DueMilestone synchronizes due dates with the due date of a milestone
+	DueMilestone = "milestone"
+	// DueProject synchronizes due dates with the Due date field of the project board
+	DueProject = "project"
+)
+
+// Values for the MergedPullRequests configuration key
+const (
+	// MergedPullRequestsDone marks tasks as done when a linked pull request is merged
+	MergedPullRequestsDone = "done"
+)
+
+// Values for the Match configuration key
+const (
+	// MatchExact links todo items only to issues with the same title
+	MatchExact = "exact"
+	// MatchFuzzy also suggests issues with similar titles as links for todo items
+	MatchFuzzy = "fuzzy"
+)
+
+// Values for the InlineCode configuration key
+const (
+	// InlineCodePlain writes code spans in issue titles as plain text
+	InlineCodePlain = "plain"
+	// InlineCodeKeep keeps code spans with their backticks in issue titles
+	InlineCodeKeep = "keep"
+)
+
+// Values for the ItemIDs configuration key
+const (
+	// ItemIDsComment adds a hidden ID to each task as an HTML comment
+	ItemIDsComment = "comment"
+)
+
+// Values for the Placement configuration key
+const (
+	// PlacementEnd adds tasks of pulled issues at the end of the file
+	PlacementEnd = "end"
+	// PlacementInbox adds tasks of pulled issues under an Inbox heading
+	PlacementInbox = "inbox"
+	// PlacementLabel adds tasks of pulled issues to the section named after one of their labels
+	PlacementLabel = "label"
+	// PlacementMilestone adds tasks of pulled issues to the section named after their milestone
+	PlacementMilestone = "milestone"
+)
+
+// Values for the Order configuration key
+const (
+	// OrderNumber orders tasks of pulled issues by issue number
+	OrderNumber = "number"
+	// OrderCreated orders tasks of pulled issues by the date their issues were created
+	OrderCreated = "created"
+	// OrderPriority orders tasks of pulled issues by their priority labels
+	OrderPriority = "priority"
 )
 
 // Constants for configuration file paths
@@ -20,11 +100,40 @@ const (
 	ProjectConfigFilename = "config.json"
 	// ProjectConfigDir is the directory name for project-specific configuration
 	ProjectConfigDir = ".atat"
+	// UserConfigDir is the directory name for user and system configuration
+	// under the XDG config directory and /etc
+	UserConfigDir = "gh-atat"
 )
 
+// Scope represents the configuration file a value comes from
+type Scope string
+
+const (
+	// ScopeSystem is the system-wide configuration
+	ScopeSystem Scope = "system"
+	// ScopeUser is the configuration of the current user
+	ScopeUser Scope = "user"
+	// ScopeProject is the configuration of the current project
+	ScopeProject Scope = "project"
+	// ScopeEnv is an ATAT_<KEY> environment variable
+	ScopeEnv Scope = "env"
+	// ScopeFlag is a command line flag
+	ScopeFlag Scope = "flag"
+)
+
+// Layer is the configuration read from a single scope
+type Layer struct {
+	Scope  Scope
+	Config map[ConfigKey]any
+}
+
 // AllConfigKeys returns all available configuration keys
 func AllConfigKeys() []ConfigKey {
-	return []ConfigKey{Repositories}
+	keys := make([]ConfigKey, len(registry))
+	for i, spec := range registry {
+		keys[i] = spec.Key
+	}
+	return keys
 }
 
 // ParseConfig parses a JSON configuration file content into a map of configuration values.
@@ -32,32 +141,52 @@ func AllConfigKeys() []ConfigKey {
 // Expects content to be a byte slice representing a JSON object with configuration keys.
 // Returns a map of ConfigKey to any containing all parsed configuration values.
 // Returns an empty map if the input content is empty or contains only whitespace.
-// Returns an error if the JSON parsing fails.
+// Returns an error if the JSON parsing fails or a value is invalid.
+// Unknown keys are skipped; use ParseConfigWithWarnings to report them.
 func ParseConfig(content []byte) (map[ConfigKey]any, error) {
+	configMap, _, err := ParseConfigWithWarnings(content)
+	return configMap, err
+}
+
+// ParseConfigWithWarnings parses a JSON configuration file content like ParseConfig.
+//
+// Files of older schema versions are migrated to CurrentVersion.
+// Returns a warning for each unknown key, and an error with the key path of each
+// invalid value.
+func ParseConfigWithWarnings(content []byte) (map[ConfigKey]any, []string, error) {
 	// Check if content is empty or only whitespace
 	if len(content) == 0 || isWhitespace(content) {
-		return make(map[ConfigKey]any), nil
+		return make(map[ConfigKey]any), nil, nil
 	}
 
 	// Parse JSON content
 	var value any
 	if err := json.Unmarshal(content, &value); err != nil {
-		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse config JSON: %w", err)
 	}
 
-	configMap := make(map[ConfigKey]any)
-
 	// Check if value is an object
-	if obj, ok := value.(map[string]any); ok {
-		for _, key := range AllConfigKeys() {
-			if val, exists := obj[string(key)]; exists {
-				configMap[key] = val
-			}
-		}
-		return configMap, nil
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("config must be a JSON object")
 	}
 
-	return nil, fmt.Errorf("config must be a JSON object")
+	obj, err := Migrate(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings, err := Validate(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	configMap := make(map[ConfigKey]any)
+	for _, key := range AllConfigKeys() {
+		if val, exists := obj[string(key)]; exists {
+			configMap[key] = val
+		}
+	}
+	return configMap, warnings, nil
 }
 
 // UpdateConfig merges updates into baseConfig and returns a new configuration map.
@@ -78,6 +207,24 @@ func UpdateConfig(baseConfig map[ConfigKey]any, updates map[ConfigKey]any) map[C
 	return newConfig
 }
 
+// MergeLayers merges configuration layers in order of increasing precedence,
+// so values of later layers override values of earlier ones.
+//
+// Returns the merged configuration and the scope each value came from.
+func MergeLayers(layers []Layer) (map[ConfigKey]any, map[ConfigKey]Scope) {
+	merged := make(map[ConfigKey]any)
+	origins := make(map[ConfigKey]Scope)
+
+	for _, layer := range layers {
+		merged = UpdateConfig(merged, layer.Config)
+		for key := range layer.Config {
+			origins[key] = layer.Scope
+		}
+	}
+
+	return merged, origins
+}
+
 // isWhitespace checks if all bytes in the slice are ASCII whitespace
 func isWhitespace(content []byte) bool {
 	for _, b := range content {
@@ -61,6 +61,41 @@ func TestParseConfig(t *testing.T) {
 			checkKey:  Repositories,
 			keyExists: false,
 		},
+		{
+			name:        "due key",
+			input:       []byte(`{"due": "milestone"}`),
+			wantErr:     false,
+			checkKey:    Due,
+			keyExists:   true,
+			expectedVal: "milestone",
+		},
+		{
+			name:        "projects key",
+			input:       []byte(`{"projects": ["owner/1"]}`),
+			wantErr:     false,
+			checkKey:    Projects,
+			keyExists:   true,
+			expectedVal: []any{"owner/1"},
+		},
+		{
+			name:        "merged pull requests key",
+			input:       []byte(`{"merged_pull_requests": "done"}`),
+			wantErr:     false,
+			checkKey:    MergedPullRequests,
+			keyExists:   true,
+			expectedVal: "done",
+		},
+		{
+			name:    "repository is a number",
+			input:   []byte(`{"repositories": [1]}`),
+			wantErr: true,
+		},
+		{
+			name:       "version key not a value",
+			input:      []byte(`{"version": 1}`),
+			wantErr:    false,
+			wantConfig: map[ConfigKey]any{},
+		},
 		{
 			name:    "valid JSON array",
 			input:   []byte(`["value1", "value2"]`),
@@ -214,3 +249,39 @@ func TestUpdateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeLayers(t *testing.T) {
+	layers := []Layer{
+		{Scope: ScopeSystem, Config: map[ConfigKey]any{Due: "milestone", Repositories: []any{"system/repo"}}},
+		{Scope: ScopeUser, Config: map[ConfigKey]any{Repositories: []any{"user/repo"}}},
+		{Scope: ScopeProject, Config: map[ConfigKey]any{Repositories: []any{"project/repo"}, Projects: []any{"owner/1"}}},
+	}
+
+	merged, origins := MergeLayers(layers)
+
+	expected := map[ConfigKey]any{
+		Due:          "milestone",
+		Repositories: []any{"project/repo"},
+		Projects:     []any{"owner/1"},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("MergeLayers() = %v, want %v", merged, expected)
+	}
+
+	expectedOrigins := map[ConfigKey]Scope{
+		Due:          ScopeSystem,
+		Repositories: ScopeProject,
+		Projects:     ScopeProject,
+	}
+	if !reflect.DeepEqual(origins, expectedOrigins) {
+		t.Errorf("MergeLayers() origins = %v, want %v", origins, expectedOrigins)
+	}
+}
+
+func TestMergeLayersEmpty(t *testing.T) {
+	merged, origins := MergeLayers(nil)
+
+	if len(merged) != 0 || len(origins) != 0 {
+		t.Errorf("expected empty results, got %v and %v", merged, origins)
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultTodoFile is the TODO file tracked when no files are configured
+const DefaultTodoFile = "TODO.md"
+
+// TrackedFile is a TODO file synchronized with GitHub issues.
+// Repository and Label are empty if the file is not bound to them.
+type TrackedFile struct {
+	// Path is relative to the project root, using forward slashes
+	Path string
+	// Repository overrides the first configured repository for this file
+	Repository string
+	// Label is added to issues created from this file, and only new issues
+	// with this label are pulled into it
+	Label string
+}
+
+// ParseTrackedFiles parses the value of the Files configuration key.
+//
+// Each entry is either a path, or an object with "path" and optional
+// "repository" and "label" fields:
+//
+//	["TODO.md", {"path": "docs/ROADMAP.md", "repository": "owner/roadmap", "label": "roadmap"}]
+//
+// Returns the default TODO file if value is nil.
+// Returns a ValidationError if an entry is invalid or a path is listed twice.
+func ParseTrackedFiles(value any) ([]TrackedFile, error) {
+	if value == nil {
+		return []TrackedFile{{Path: DefaultTodoFile}}, nil
+	}
+
+	files, err := parseTrackedFiles(value)
+	if err != nil {
+		return nil, atPath(string(Files), err)
+	}
+	return files, nil
+}
+
+// parseTrackedFiles parses a non-nil value of the Files configuration key.
+// Error paths are relative to the key.
+func parseTrackedFiles(value any) ([]TrackedFile, error) {
+	entries, ok := value.([]any)
+	if !ok {
+		return nil, &ValidationError{Reason: fmt.Sprintf("expected an array, got %s", jsonType(value))}
+	}
+	if len(entries) == 0 {
+		return nil, &ValidationError{Reason: "no files listed"}
+	}
+
+	var files []TrackedFile
+	for i, entry := range entries {
+		index := fmt.Sprintf("[%d]", i)
+		file, err := parseTrackedFile(entry)
+		if err != nil {
+			return nil, atPath(index, err)
+		}
+		for _, existing := range files {
+			if existing.Path == file.Path {
+				return nil, &ValidationError{Path: index, Reason: fmt.Sprintf("%s is listed twice", file.Path)}
+			}
+		}
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// parseTrackedFile parses a single entry of the Files configuration key
+func parseTrackedFile(entry any) (TrackedFile, error) {
+	switch v := entry.(type) {
+	case string:
+		path, err := NormalizeFilePath(v)
+		if err != nil {
+			return TrackedFile{}, err
+		}
+		return TrackedFile{Path: path}, nil
+	case map[string]any:
+		rawPath, exists := v["path"]
+		if !exists {
+			return TrackedFile{}, &ValidationError{Reason: "entry without a path"}
+		}
+		pathStr, ok := rawPath.(string)
+		if !ok {
+			return TrackedFile{}, &ValidationError{Path: "path", Reason: fmt.Sprintf("expected a string, got %s", jsonType(rawPath))}
+		}
+		path, err := NormalizeFilePath(pathStr)
+		if err != nil {
+			return TrackedFile{}, atPath("path", err)
+		}
+		file := TrackedFile{Path: path}
+
+		if repository, exists := v["repository"]; exists {
+			repo, ok := repository.(string)
+			if !ok {
+				return TrackedFile{}, &ValidationError{Path: "repository", Reason: fmt.Sprintf("expected a string, got %s", jsonType(repository))}
+			}
+			if err := validateRepository(repo); err != nil {
+				return TrackedFile{}, atPath("repository", err)
+			}
+			file.Repository = repo
+		}
+		if label, exists := v["label"]; exists {
+			labelStr, ok := label.(string)
+			if !ok || strings.TrimSpace(labelStr) == "" {
+				return TrackedFile{}, &ValidationError{Path: "label", Reason: "must be a non-empty string"}
+			}
+			file.Label = labelStr
+		}
+		return file, nil
+	default:
+		return TrackedFile{}, &ValidationError{Reason: fmt.Sprintf("expected a path or an object, got %s", jsonType(entry))}
+	}
+}
+
+// NormalizeFilePath cleans a path relative to the project root.
+// Returns an error if the path is empty, absolute or outside the project.
+func NormalizeFilePath(path string) (string, error) {
+	if strings.TrimSpace(path) == "" {
+		return "", fmt.Errorf("invalid file path: empty")
+	}
+	if filepath.IsAbs(path) || strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("invalid file path %s: must be relative to the project root", path)
+	}
+
+	cleaned := filepath.ToSlash(filepath.Clean(filepath.FromSlash(path)))
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("invalid file path %s: outside the project root", path)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTrackedFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected []TrackedFile
+		wantErr  bool
+	}{
+		{
+			name:     "not configured",
+			value:    nil,
+			expected: []TrackedFile{{Path: "TODO.md"}},
+		},
+		{
+			name:  "paths and objects",
+			value: []any{"TODO.md", "./pkg/a/../b/TODO.md", map[string]any{"path": "docs/ROADMAP.md", "repository": "owner/roadmap", "label": "roadmap"}},
+			expected: []TrackedFile{
+				{Path: "TODO.md"},
+				{Path: "pkg/b/TODO.md"},
+				{Path: "docs/ROADMAP.md", Repository: "owner/roadmap", Label: "roadmap"},
+			},
+		},
+		{name: "not an array", value: "TODO.md", wantErr: true},
+		{name: "empty array", value: []any{}, wantErr: true},
+		{name: "listed twice", value: []any{"TODO.md", "./TODO.md"}, wantErr: true},
+		{name: "absolute path", value: []any{"/TODO.md"}, wantErr: true},
+		{name: "outside project", value: []any{"../TODO.md"}, wantErr: true},
+		{name: "empty path", value: []any{""}, wantErr: true},
+		{name: "object without path", value: []any{map[string]any{"label": "x"}}, wantErr: true},
+		{name: "invalid repository", value: []any{map[string]any{"path": "TODO.md", "repository": "owner"}}, wantErr: true},
+		{name: "empty label", value: []any{map[string]any{"path": "TODO.md", "label": " "}}, wantErr: true},
+		{name: "number entry", value: []any{1.0}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ParseTrackedFiles(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrackedFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(files, tt.expected) {
+				t.Errorf("ParseTrackedFiles() = %+v, want %+v", files, tt.expected)
+			}
+		})
+	}
+}
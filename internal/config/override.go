@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// EnvPrefix is the prefix of environment variables overriding configuration keys
+const EnvPrefix = "ATAT_"
+
+// EnvName returns the environment variable overriding key, such as ATAT_REPOSITORIES
+func EnvName(key ConfigKey) string {
+	return EnvPrefix + strings.ToUpper(string(key))
+}
+
+// FlagName returns the command line flag overriding key, without the leading dashes,
+// such as merged-pull-requests
+func FlagName(key ConfigKey) string {
+	return strings.ReplaceAll(string(key), "_", "-")
+}
+
+// ApplyOverrides applies values given by environment variables and command line flags
+// on top of the configuration loaded from files.
+//
+// Values are looked up with lookupEnv under EnvName of each key, and in flags by key.
+// Flags take precedence over environment variables, which take precedence over files.
+// Empty environment variables are ignored. Values are parsed like `config set` values,
+// so list keys accept comma-separated values.
+//
+// Returns new configuration and origin maps; configMap and origins are not modified.
+// Returns an error if a value is invalid for its key.
+func ApplyOverrides(
+	configMap map[ConfigKey]any,
+	origins map[ConfigKey]Scope,
+	lookupEnv func(string) (string, bool),
+	flags map[ConfigKey]string,
+) (map[ConfigKey]any, map[ConfigKey]Scope, error) {
+	resolved := maps.Clone(configMap)
+	if resolved == nil {
+		resolved = make(map[ConfigKey]any)
+	}
+	resolvedOrigins := maps.Clone(origins)
+	if resolvedOrigins == nil {
+		resolvedOrigins = make(map[ConfigKey]Scope)
+	}
+
+	for _, spec := range registry {
+		if input, ok := lookupEnv(EnvName(spec.Key)); ok && strings.TrimSpace(input) != "" {
+			value, err := spec.ParseValue(input)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s: %w", EnvName(spec.Key), err)
+			}
+			resolved[spec.Key] = value
+			resolvedOrigins[spec.Key] = ScopeEnv
+		}
+
+		if input, ok := flags[spec.Key]; ok {
+			value, err := spec.ParseValue(input)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid --%s: %w", FlagName(spec.Key), err)
+			}
+			resolved[spec.Key] = value
+			resolvedOrigins[spec.Key] = ScopeFlag
+		}
+	}
+
+	return resolved, resolvedOrigins, nil
+}
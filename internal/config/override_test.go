@@ -0,0 +1,71 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvAndFlagNames(t *testing.T) {
+	if name := EnvName(MergedPullRequests); name != "ATAT_MERGED_PULL_REQUESTS" {
+		t.Errorf("unexpected env name %s", name)
+	}
+	if name := FlagName(MergedPullRequests); name != "merged-pull-requests" {
+		t.Errorf("unexpected flag name %s", name)
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	configMap := map[ConfigKey]any{Repositories: []any{"file/repo"}, Due: "milestone"}
+	origins := map[ConfigKey]Scope{Repositories: ScopeProject, Due: ScopeUser}
+	env := map[string]string{
+		"ATAT_REPOSITORIES":         "env/one, env/two",
+		"ATAT_PROJECTS":             "owner/1",
+		"ATAT_MERGED_PULL_REQUESTS": "",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+	flags := map[ConfigKey]string{Projects: "owner/2"}
+
+	resolved, resolvedOrigins, err := ApplyOverrides(configMap, origins, lookupEnv, flags)
+	if err != nil {
+		t.Fatalf("ApplyOverrides failed: %v", err)
+	}
+
+	expected := map[ConfigKey]any{
+		Repositories: []any{"env/one", "env/two"},
+		Due:          "milestone",
+		Projects:     []any{"owner/2"},
+	}
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("expected %v, got %v", expected, resolved)
+	}
+	expectedOrigins := map[ConfigKey]Scope{Repositories: ScopeEnv, Due: ScopeUser, Projects: ScopeFlag}
+	if !reflect.DeepEqual(resolvedOrigins, expectedOrigins) {
+		t.Errorf("expected origins %v, got %v", expectedOrigins, resolvedOrigins)
+	}
+	if !reflect.DeepEqual(configMap, map[ConfigKey]any{Repositories: []any{"file/repo"}, Due: "milestone"}) {
+		t.Errorf("configMap was modified: %v", configMap)
+	}
+}
+
+func TestApplyOverridesInvalidValue(t *testing.T) {
+	lookupEnv := func(name string) (string, bool) {
+		if name == "ATAT_DUE" {
+			return "label", true
+		}
+		return "", false
+	}
+
+	_, _, err := ApplyOverrides(nil, nil, lookupEnv, nil)
+	expected := `invalid ATAT_DUE: due: expected "milestone" or "project", got "label"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+
+	_, _, err = ApplyOverrides(nil, nil, func(string) (string, bool) { return "", false }, map[ConfigKey]string{Repositories: "owner"})
+	if err == nil {
+		t.Error("expected error for invalid flag value, got nil")
+	}
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KeySpec describes a configuration key
+type KeySpec struct {
+	Key         ConfigKey
+	Description string
+	// Default is the value used when the key is not set, or nil if there is none
+	Default any
+	// List reports whether the value is an array, so command line values are split on commas
+	List bool
+	// Validate checks a value as it is stored in the configuration file.
+	// The paths of returned ValidationErrors are relative to the key.
+	Validate func(value any) error
+}
+
+// registry lists the configuration keys in the order they are documented
+var registry = []KeySpec{
+	{
+		Key:         Repositories,
+		Description: "Repositories to sync with, as <owner>/<repo>. The first one is used.",
+		List:        true,
+		Validate:    validateStringList(validateRepository),
+	},
+	{
+		Key:         Due,
+		Description: `Where due dates are synced to: "milestone", or "project" for the Due date field of the project board.`,
+		Validate:    validateOneOf(DueMilestone, DueProject),
+	},
+	{
+		Key:         Projects,
+		Description: "GitHub Projects v2 boards to sync sections with, as <owner>/<number>. The first one is used.",
+		List:        true,
+		Validate:    validateStringList(validateProject),
+	},
+	{
+		Key:         MergedPullRequests,
+		Description: `What to do with a task when a linked pull request is merged. Only "done" is supported.`,
+		Validate:    validateOneOf(MergedPullRequestsDone),
+	},
+	{
+		Key:         Files,
+		Description: "TODO files to sync, as paths relative to the project root or objects with path, repository and label.",
+		Default:     []any{DefaultTodoFile},
+		List:        true,
+		Validate: func(value any) error {
+			_, err := parseTrackedFiles(value)
+			return err
+		},
+	},
+	{
+		Key:         Match,
+		Description: `How tasks are linked to existing issues: "exact" titles, or "fuzzy" to also suggest similar titles.`,
+		Default:     MatchExact,
+		Validate:    validateOneOf(MatchExact, MatchFuzzy),
+	},
+	{
+		Key:         InlineCode,
+		Description: `How code spans in tasks appear in issue titles: as "plain" text, or "keep" their backticks.`,
+		Default:     InlineCodePlain,
+		Validate:    validateOneOf(InlineCodePlain, InlineCodeKeep),
+	},
+	{
+		Key:         IgnoreSections,
+		Description: "Headings whose tasks are not synced, such as Notes. Subheadings are skipped too.",
+		List:        true,
+		Validate:    validateStringList(validateHeading),
+	},
+	{
+		Key:         ItemIDs,
+		Description: `How hidden IDs are added to tasks, so edited tasks are found again. Only "comment" is supported.`,
+		Validate:    validateOneOf(ItemIDsComment),
+	},
+	{
+		Key:         Placement,
+		Description: `Where tasks of pulled issues are added: at the "end", under an "inbox" heading, or in the section named after their "label" or "milestone".`,
+		Default:     PlacementEnd,
+		Validate:    validateOneOf(PlacementEnd, PlacementInbox, PlacementLabel, PlacementMilestone),
+	},
+	{
+		Key:         Order,
+		Description: `How tasks of pulled issues are ordered: by issue "number", by "created" date, or by "priority" labels.`,
+		Validate:    validateOneOf(OrderNumber, OrderCreated, OrderPriority),
+	},
+	{
+		Key:         PriorityLabels,
+		Description: "Labels giving the priority of issues, the highest first, such as P0, P1, P2.",
+		List:        true,
+		Validate:    validateStringList(validateLabel),
+	},
+}
+
+// Registry returns the specs of all configuration keys
+func Registry() []KeySpec {
+	specs := make([]KeySpec, len(registry))
+	copy(specs, registry)
+	return specs
+}
+
+// LookupKey returns the spec of the configuration key with the given name
+func LookupKey(name string) (KeySpec, bool) {
+	for _, spec := range registry {
+		if string(spec.Key) == name {
+			return spec, true
+		}
+	}
+	return KeySpec{}, false
+}
+
+// String returns the value of a string key, or its default if it is not set.
+// Returns "" if the key is not set and has no default.
+// Returns a ValidationError if the value is not valid for the key.
+func String(configMap map[ConfigKey]any, key ConfigKey) (string, error) {
+	value, err := lookupValue(configMap, key)
+	if err != nil || value == nil {
+		return "", err
+	}
+	s, _ := value.(string)
+	return s, nil
+}
+
+// StringList returns the value of a list key holding strings, or its default if it
+// is not set. Returns nil if the key is not set and has no default.
+// Returns a ValidationError if the value is not valid for the key.
+func StringList(configMap map[ConfigKey]any, key ConfigKey) ([]string, error) {
+	value, err := lookupValue(configMap, key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	items, _ := value.([]any)
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
+
+// lookupValue returns the value of key in configMap, or its default, checked with the
+// validator of the key
+func lookupValue(configMap map[ConfigKey]any, key ConfigKey) (any, error) {
+	spec, ok := LookupKey(string(key))
+	if !ok {
+		return nil, fmt.Errorf("unknown config key: %s", key)
+	}
+	value, ok := configMap[key]
+	if !ok {
+		return spec.Default, nil
+	}
+	if err := spec.Validate(value); err != nil {
+		return nil, atPath(string(key), err)
+	}
+	return value, nil
+}
+
+// ParseValue converts a value given on the command line to the value stored for the key.
+//
+// Values starting with "[" or "{" are parsed as JSON. Otherwise, values of list keys
+// are split on commas and other values are used as strings.
+// Returns a ValidationError if the value is not valid for the key.
+func (spec KeySpec) ParseValue(input string) (any, error) {
+	trimmed := strings.TrimSpace(input)
+
+	var value any
+	switch {
+	case strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{"):
+		if err := json.Unmarshal([]byte(trimmed), &value); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case spec.List:
+		items := []any{}
+		for _, item := range strings.Split(trimmed, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value = items
+	default:
+		value = trimmed
+	}
+
+	if err := spec.Validate(value); err != nil {
+		return nil, atPath(string(spec.Key), err)
+	}
+	return value, nil
+}
+
+// FormatValue formats a configuration value for display.
+// Strings are shown as is and other values as JSON.
+func FormatValue(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// validateStringList returns a validator for arrays of strings checked by validateItem
+func validateStringList(validateItem func(string) error) func(any) error {
+	return func(value any) error {
+		items, ok := value.([]any)
+		if !ok {
+			return &ValidationError{Reason: fmt.Sprintf("expected an array of strings, got %s", jsonType(value))}
+		}
+		for i, item := range items {
+			index := fmt.Sprintf("[%d]", i)
+			s, ok := item.(string)
+			if !ok {
+				return &ValidationError{Path: index, Reason: fmt.Sprintf("expected a string, got %s", jsonType(item))}
+			}
+			if err := validateItem(s); err != nil {
+				return atPath(index, err)
+			}
+		}
+		return nil
+	}
+}
+
+// validateOneOf returns a validator for strings with one of the allowed values
+func validateOneOf(allowed ...string) func(any) error {
+	return func(value any) error {
+		s, ok := value.(string)
+		if ok {
+			for _, a := range allowed {
+				if s == a {
+					return nil
+				}
+			}
+		}
+		quoted := make([]string, len(allowed))
+		for i, a := range allowed {
+			quoted[i] = strconv.Quote(a)
+		}
+		if !ok {
+			return fmt.Errorf("expected %s, got %s", strings.Join(quoted, " or "), jsonType(value))
+		}
+		return fmt.Errorf("expected %s, got %q", strings.Join(quoted, " or "), s)
+	}
+}
+
+// validateRepository checks a repository given as <owner>/<repo>
+func validateRepository(repo string) error {
+	parts := strings.Split(repo, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("invalid repository %q. Please use <owner>/<repo>", repo)
+	}
+	return nil
+}
+
+// validateHeading checks a heading title given to skip
+func validateHeading(heading string) error {
+	if strings.TrimSpace(heading) == "" {
+		return fmt.Errorf("empty heading")
+	}
+	return nil
+}
+
+// validateLabel checks a label name
+func validateLabel(label string) error {
+	if strings.TrimSpace(label) == "" {
+		return fmt.Errorf("empty label")
+	}
+	return nil
+}
+
+// validateProject checks a project board given as <owner>/<number>
+func validateProject(project string) error {
+	owner, numberStr, found := strings.Cut(project, "/")
+	number, err := strconv.Atoi(numberStr)
+	if !found || owner == "" || err != nil || number <= 0 {
+		return fmt.Errorf("invalid project %q. Please use <owner>/<number>", project)
+	}
+	return nil
+}
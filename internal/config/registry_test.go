@@ -0,0 +1,124 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRegistryCoversAllConfigKeys(t *testing.T) {
+	for _, key := range AllConfigKeys() {
+		spec, ok := LookupKey(string(key))
+		if !ok {
+			t.Errorf("key %s is not in the registry", key)
+			continue
+		}
+		if spec.Description == "" || spec.Validate == nil {
+			t.Errorf("key %s has no description or validator", key)
+		}
+	}
+
+	if _, ok := LookupKey("unknown"); ok {
+		t.Error("expected unknown key not to be found")
+	}
+}
+
+func TestKeySpecParseValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      ConfigKey
+		input    string
+		expected any
+		wantErr  bool
+	}{
+		{name: "list from commas", key: Repositories, input: "owner/a, owner/b", expected: []any{"owner/a", "owner/b"}},
+		{name: "list from JSON", key: Repositories, input: `["owner/a"]`, expected: []any{"owner/a"}},
+		{name: "invalid repository", key: Repositories, input: "owner", wantErr: true},
+		{name: "string value", key: Due, input: "milestone", expected: "milestone"},
+		{name: "invalid string value", key: Due, input: "calendar", wantErr: true},
+		{name: "project", key: Projects, input: "owner/3", expected: []any{"owner/3"}},
+		{name: "invalid project", key: Projects, input: "owner/x", wantErr: true},
+		{name: "merged pull requests", key: MergedPullRequests, input: "done", expected: "done"},
+		{name: "files with objects", key: Files, input: `["TODO.md", {"path": "docs/ROADMAP.md", "label": "roadmap"}]`, expected: []any{"TODO.md", map[string]any{"path": "docs/ROADMAP.md", "label": "roadmap"}}},
+		{name: "match", key: Match, input: "fuzzy", expected: "fuzzy"},
+		{name: "invalid match", key: Match, input: "similar", wantErr: true},
+		{name: "inline code", key: InlineCode, input: "keep", expected: "keep"},
+		{name: "invalid inline code", key: InlineCode, input: "strip", wantErr: true},
+		{name: "item ids", key: ItemIDs, input: "comment", expected: "comment"},
+		{name: "invalid item ids", key: ItemIDs, input: "marker", wantErr: true},
+		{name: "placement", key: Placement, input: "label", expected: "label"},
+		{name: "invalid placement", key: Placement, input: "top", wantErr: true},
+		{name: "order", key: Order, input: "created", expected: "created"},
+		{name: "invalid order", key: Order, input: "title", wantErr: true},
+		{name: "priority labels", key: PriorityLabels, input: "P0, P1", expected: []any{"P0", "P1"}},
+		{name: "empty priority label", key: PriorityLabels, input: `["P0", ""]`, wantErr: true},
+		{name: "ignore sections", key: IgnoreSections, input: "Notes, Examples", expected: []any{"Notes", "Examples"}},
+		{name: "empty ignored section", key: IgnoreSections, input: `["Notes", " "]`, wantErr: true},
+		{name: "invalid files", key: Files, input: "../TODO.md", wantErr: true},
+		{name: "invalid JSON", key: Files, input: `["TODO.md"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, ok := LookupKey(string(tt.key))
+			if !ok {
+				t.Fatalf("key %s not found", tt.key)
+			}
+
+			value, err := spec.ParseValue(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(value, tt.expected) {
+				t.Errorf("ParseValue() = %#v, want %#v", value, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{"milestone", "milestone"},
+		{[]any{"owner/a", "owner/b"}, `["owner/a","owner/b"]`},
+		{map[string]any{"path": "TODO.md"}, `{"path":"TODO.md"}`},
+	}
+
+	for _, tt := range tests {
+		if actual := FormatValue(tt.value); actual != tt.expected {
+			t.Errorf("FormatValue(%v) = %s, want %s", tt.value, actual, tt.expected)
+		}
+	}
+}
+
+func TestTypedAccessors(t *testing.T) {
+	configMap := map[ConfigKey]any{
+		Match:          MatchFuzzy,
+		IgnoreSections: []any{"Notes", "Examples"},
+		Due:            "label",
+	}
+
+	if match, err := String(configMap, Match); err != nil || match != MatchFuzzy {
+		t.Errorf("expected %q, got %q (%v)", MatchFuzzy, match, err)
+	}
+	if inlineCode, err := String(configMap, InlineCode); err != nil || inlineCode != InlineCodePlain {
+		t.Errorf("expected the default %q, got %q (%v)", InlineCodePlain, inlineCode, err)
+	}
+	if itemIDs, err := String(configMap, ItemIDs); err != nil || itemIDs != "" {
+		t.Errorf("expected an unset key without default to be empty, got %q (%v)", itemIDs, err)
+	}
+	if sections, err := StringList(configMap, IgnoreSections); err != nil || !reflect.DeepEqual(sections, []string{"Notes", "Examples"}) {
+		t.Errorf("expected [Notes Examples], got %v (%v)", sections, err)
+	}
+	if files, err := StringList(map[ConfigKey]any{}, Files); err != nil || !reflect.DeepEqual(files, []string{DefaultTodoFile}) {
+		t.Errorf("expected the default files, got %v (%v)", files, err)
+	}
+
+	_, err := String(configMap, Due)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Path != string(Due) {
+		t.Errorf("expected a validation error at %s, got %v", Due, err)
+	}
+}
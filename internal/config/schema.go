@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// VersionKey is the key holding the schema version of a configuration file.
+// It is not a configuration value, so it is not part of the registry.
+const VersionKey = "version"
+
+// CurrentVersion is the schema version written to configuration files.
+//
+// Files without a version were written before versioning. Their format is version 1.
+const CurrentVersion = 1
+
+// migrations upgrade a configuration file by one schema version.
+// migrations[i] upgrades version i+1 to version i+2, so a migration is added here
+// whenever CurrentVersion is raised.
+var migrations = []func(obj map[string]any) map[string]any{}
+
+// ValidationError reports an invalid configuration value and where it is
+type ValidationError struct {
+	// Path is the location of the value, such as "files[1].repository"
+	Path string
+	// Reason describes what is wrong with the value
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Reason
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Reason)
+}
+
+// Migrate upgrades a configuration object to CurrentVersion.
+//
+// Returns a new object with VersionKey set to CurrentVersion; obj is not modified.
+// Returns an error if the version is invalid or newer than CurrentVersion.
+func Migrate(obj map[string]any) (map[string]any, error) {
+	version := 1
+	if value, exists := obj[VersionKey]; exists {
+		number, ok := value.(float64)
+		if !ok || number != float64(int(number)) || number < 1 {
+			return nil, &ValidationError{Path: VersionKey, Reason: fmt.Sprintf("expected a positive integer, got %s", jsonType(value))}
+		}
+		version = int(number)
+	}
+	if version > CurrentVersion {
+		return nil, &ValidationError{
+			Path:   VersionKey,
+			Reason: fmt.Sprintf("version %d is newer than the supported version %d; please upgrade gh-atat", version, CurrentVersion),
+		}
+	}
+
+	migrated := make(map[string]any, len(obj)+1)
+	for key, value := range obj {
+		migrated[key] = value
+	}
+	for ; version < CurrentVersion; version++ {
+		migrated = migrations[version-1](migrated)
+	}
+	migrated[VersionKey] = float64(CurrentVersion)
+
+	return migrated, nil
+}
+
+// Validate checks the values of a configuration object against the registry.
+//
+// Returns a warning for each unknown key, and an error listing every invalid
+// value with its key path.
+func Validate(obj map[string]any) ([]string, error) {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var warnings []string
+	var errs []error
+	for _, key := range keys {
+		if key == VersionKey {
+			continue
+		}
+		spec, ok := LookupKey(key)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("unknown key %q is ignored", key))
+			continue
+		}
+		if err := spec.Validate(obj[key]); err != nil {
+			errs = append(errs, atPath(key, err))
+		}
+	}
+
+	return warnings, errors.Join(errs...)
+}
+
+// atPath prefixes the path of a validation error with prefix.
+// Other errors become validation errors at prefix.
+func atPath(prefix string, err error) error {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return &ValidationError{Path: prefix, Reason: err.Error()}
+	}
+
+	path := prefix
+	switch {
+	case validationErr.Path == "":
+	case strings.HasPrefix(validationErr.Path, "["):
+		path += validationErr.Path
+	default:
+		path += "." + validationErr.Path
+	}
+	return &ValidationError{Path: path, Reason: validationErr.Reason}
+}
+
+// jsonType returns the JSON type name of a decoded JSON value
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
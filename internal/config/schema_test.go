@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    map[string]any
+		expected map[string]any
+		wantErr  bool
+	}{
+		{
+			name:     "unversioned file",
+			input:    map[string]any{"repositories": []any{"owner/repo"}},
+			expected: map[string]any{"version": float64(1), "repositories": []any{"owner/repo"}},
+		},
+		{
+			name:     "current version unchanged",
+			input:    map[string]any{"version": float64(CurrentVersion), "due": "milestone"},
+			expected: map[string]any{"version": float64(CurrentVersion), "due": "milestone"},
+		},
+		{
+			name:    "newer version",
+			input:   map[string]any{"version": float64(CurrentVersion + 1)},
+			wantErr: true,
+		},
+		{
+			name:    "fractional version",
+			input:   map[string]any{"version": 1.5},
+			wantErr: true,
+		},
+		{
+			name:    "string version",
+			input:   map[string]any{"version": "1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := make(map[string]any)
+			for key, value := range tt.input {
+				original[key] = value
+			}
+
+			migrated, err := Migrate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Migrate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.input, original) {
+				t.Errorf("input was modified: got %v, want %v", tt.input, original)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(migrated, tt.expected) {
+				t.Errorf("Migrate() = %v, want %v", migrated, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        map[string]any
+		wantErr      string
+		wantWarnings []string
+	}{
+		{
+			name:  "valid",
+			input: map[string]any{"version": float64(1), "repositories": []any{"owner/repo"}, "files": []any{"TODO.md"}},
+		},
+		{
+			name:    "repository is a number",
+			input:   map[string]any{"repositories": []any{"owner/repo", float64(42)}},
+			wantErr: "repositories[1]: expected a string, got number",
+		},
+		{
+			name:    "repositories is an object",
+			input:   map[string]any{"repositories": map[string]any{}},
+			wantErr: "repositories: expected an array of strings, got object",
+		},
+		{
+			name:    "invalid repository",
+			input:   map[string]any{"repositories": []any{"owner"}},
+			wantErr: `repositories[0]: invalid repository "owner". Please use <owner>/<repo>`,
+		},
+		{
+			name:    "invalid due",
+			input:   map[string]any{"due": "label"},
+			wantErr: `due: expected "milestone" or "project", got "label"`,
+		},
+		{
+			name:    "invalid nested file repository",
+			input:   map[string]any{"files": []any{"TODO.md", map[string]any{"path": "docs/TODO.md", "repository": "owner"}}},
+			wantErr: `files[1].repository: invalid repository "owner". Please use <owner>/<repo>`,
+		},
+		{
+			name:         "unknown keys",
+			input:        map[string]any{"repository": "owner/repo", "colour": "red"},
+			wantWarnings: []string{`unknown key "colour" is ignored`, `unknown key "repository" is ignored`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := Validate(tt.input)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+			} else if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+			if !slices.Equal(warnings, tt.wantWarnings) {
+				t.Errorf("Validate() warnings = %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryInvalidKey(t *testing.T) {
+	_, err := Validate(map[string]any{"due": float64(1), "projects": []any{"owner/x"}})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	expected := "due: expected \"milestone\" or \"project\", got number\nprojects[0]: invalid project \"owner/x\". Please use <owner>/<number>"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestParseConfigWithWarnings(t *testing.T) {
+	configMap, warnings, err := ParseConfigWithWarnings([]byte(`{"repositories": ["owner/repo"], "unknown": true}`))
+	if err != nil {
+		t.Fatalf("ParseConfigWithWarnings failed: %v", err)
+	}
+
+	expected := map[ConfigKey]any{Repositories: []any{"owner/repo"}}
+	if !reflect.DeepEqual(configMap, expected) {
+		t.Errorf("expected %v, got %v", expected, configMap)
+	}
+	if !slices.Equal(warnings, []string{`unknown key "unknown" is ignored`}) {
+		t.Errorf("unexpected warnings: %q", warnings)
+	}
+}
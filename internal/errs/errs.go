@@ -0,0 +1,124 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Exit codes of gh-atat, documented in README.md
+const (
+	// ExitOK means the command succeeded
+	ExitOK = 0
+	// ExitError is any error without a more specific exit code
+	ExitError = 1
+	// ExitParse means a TODO file or configuration is invalid
+	ExitParse = 2
+	// ExitNotFound means a repository, issue, file or other resource doesn't exist
+	ExitNotFound = 3
+	// ExitAuth means GitHub rejected the credentials or denied access
+	ExitAuth = 4
+	// ExitRateLimited means the GitHub API rate limit was exceeded
+	ExitRateLimited = 5
+	// ExitNetwork means GitHub couldn't be reached
+	ExitNetwork = 6
+	// ExitConflict means another run or a concurrent edit kept the command from running
+	ExitConflict = 7
+	// ExitPartialFailure means the command failed after changing issues or files
+	ExitPartialFailure = 8
+)
+
+// NotFoundError reports that a repository, issue, file or other resource doesn't exist
+type NotFoundError struct {
+	Err error
+}
+
+func (e *NotFoundError) Error() string { return e.Err.Error() }
+func (e *NotFoundError) Unwrap() error { return e.Err }
+
+// AuthError reports that GitHub rejected the credentials or denied access
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string { return e.Err.Error() }
+func (e *AuthError) Unwrap() error { return e.Err }
+
+// RateLimitError reports that the GitHub API rate limit was exceeded
+type RateLimitError struct {
+	Err error
+}
+
+func (e *RateLimitError) Error() string { return e.Err.Error() }
+func (e *RateLimitError) Unwrap() error { return e.Err }
+
+// NetworkError reports that GitHub couldn't be reached
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string { return e.Err.Error() }
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// ConflictError reports that another run or a concurrent change kept the command from running
+type ConflictError struct {
+	Err error
+}
+
+func (e *ConflictError) Error() string { return e.Err.Error() }
+func (e *ConflictError) Unwrap() error { return e.Err }
+
+// ParseError reports that a TODO file or configuration is invalid
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string { return e.Err.Error() }
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// PartialFailureError reports that a command failed after it changed issues or files.
+// Err is the error that stopped the command, so its own type can still be checked.
+type PartialFailureError struct {
+	// Changes is the number of changes made before the failure
+	Changes int
+	Err     error
+}
+
+func (e *PartialFailureError) Error() string {
+	return fmt.Sprintf("%v (%d changes were made before the error; see `gh atat log`)", e.Err, e.Changes)
+}
+
+func (e *PartialFailureError) Unwrap() error { return e.Err }
+
+// ExitCode returns the exit code for err.
+// A partial failure takes precedence over the type of the error that caused it.
+func ExitCode(err error) int {
+	var (
+		partialErr   *PartialFailureError
+		conflictErr  *ConflictError
+		parseErr     *ParseError
+		authErr      *AuthError
+		rateLimitErr *RateLimitError
+		networkErr   *NetworkError
+		notFoundErr  *NotFoundError
+	)
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &partialErr):
+		return ExitPartialFailure
+	case errors.As(err, &conflictErr):
+		return ExitConflict
+	case errors.As(err, &parseErr):
+		return ExitParse
+	case errors.As(err, &authErr):
+		return ExitAuth
+	case errors.As(err, &rateLimitErr):
+		return ExitRateLimited
+	case errors.As(err, &networkErr):
+		return ExitNetwork
+	case errors.As(err, &notFoundErr):
+		return ExitNotFound
+	default:
+		return ExitError
+	}
+}
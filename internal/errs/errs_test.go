@@ -0,0 +1,66 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	cause := errors.New("failed")
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "nil", err: nil, want: ExitOK},
+		{name: "plain error", err: cause, want: ExitError},
+		{name: "parse", err: &ParseError{Err: cause}, want: ExitParse},
+		{name: "not found", err: &NotFoundError{Err: cause}, want: ExitNotFound},
+		{name: "auth", err: &AuthError{Err: cause}, want: ExitAuth},
+		{name: "rate limited", err: &RateLimitError{Err: cause}, want: ExitRateLimited},
+		{name: "network", err: &NetworkError{Err: cause}, want: ExitNetwork},
+		{name: "conflict", err: &ConflictError{Err: cause}, want: ExitConflict},
+		{name: "partial failure", err: &PartialFailureError{Changes: 2, Err: cause}, want: ExitPartialFailure},
+		{name: "wrapped", err: fmt.Errorf("failed to fetch issues: %w", &NotFoundError{Err: cause}), want: ExitNotFound},
+		{
+			name: "partial failure caused by a rate limit",
+			err:  &PartialFailureError{Changes: 1, Err: &RateLimitError{Err: cause}},
+			want: ExitPartialFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestErrorsAs(t *testing.T) {
+	cause := errors.New("gh: Not Found (HTTP 404)")
+	err := fmt.Errorf("failed to fetch issues: %w", &PartialFailureError{Changes: 1, Err: &NotFoundError{Err: cause}})
+
+	var notFoundErr *NotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Fatalf("expected a NotFoundError in %v", err)
+	}
+	if notFoundErr.Error() != cause.Error() {
+		t.Errorf("expected %q, got %q", cause.Error(), notFoundErr.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("expected %v to wrap the cause", err)
+	}
+}
+
+func TestPartialFailureErrorMessage(t *testing.T) {
+	err := &PartialFailureError{Changes: 3, Err: errors.New("gh api failed")}
+
+	want := "gh api failed (3 changes were made before the error; see `gh atat log`)"
+	if err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+}
@@ -0,0 +1,91 @@
+package github
+
+import (
+	"slices"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+// TitleConflict is an issue whose title and todo item text differ, and which
+// side to keep can't be decided from the rename history alone.
+type TitleConflict struct {
+	Number uint64
+	// Local is the text of the todo item
+	Local string
+	// Remote is the title of the issue
+	Remote string
+}
+
+// FindTitleConflicts returns the conflicts of the given issues, in the order of the todo items.
+// Issues that are not referenced by a todo item or that are not open are skipped.
+func FindTitleConflicts(todoItems []todo.TodoItem, githubIssues []GitHubIssue, issueNumbers []uint64) []TitleConflict {
+	githubIssuesMap := make(map[uint64]GitHubIssue)
+	for _, issue := range githubIssues {
+		githubIssuesMap[issue.Number] = issue
+	}
+
+	var conflicts []TitleConflict
+	seen := make(map[uint64]bool)
+	for _, todoItem := range todoItems {
+		if todoItem.IssueNumber == nil || seen[*todoItem.IssueNumber] {
+			continue
+		}
+		if !slices.Contains(issueNumbers, *todoItem.IssueNumber) {
+			continue
+		}
+		ghIssue, exists := githubIssuesMap[*todoItem.IssueNumber]
+		if !exists || ghIssue.State != IssueStateOpen {
+			continue
+		}
+		seen[ghIssue.Number] = true
+		conflicts = append(conflicts, TitleConflict{
+			Number: ghIssue.Number,
+			Local:  trimString(todoItem.Text),
+			Remote: trimString(ghIssue.Title),
+		})
+	}
+
+	return conflicts
+}
+
+// ResolveTitleConflicts applies the titles chosen for conflicting issues, given by issue number.
+// The text of each todo item referencing a resolved issue is set to the chosen title, and
+// a RenameIssueOp is returned for each issue whose title differs from the chosen title.
+func ResolveTitleConflicts(todoItems []todo.TodoItem, githubIssues []GitHubIssue, titles map[uint64]string) ([]todo.TodoItem, []TodoOperation) {
+	githubIssuesMap := make(map[uint64]GitHubIssue)
+	for _, issue := range githubIssues {
+		githubIssuesMap[issue.Number] = issue
+	}
+
+	updatedItems := make([]todo.TodoItem, len(todoItems))
+	copy(updatedItems, todoItems)
+
+	var operations []TodoOperation
+	renamed := make(map[uint64]bool)
+	for i, todoItem := range updatedItems {
+		if todoItem.IssueNumber == nil {
+			continue
+		}
+		title, ok := titles[*todoItem.IssueNumber]
+		if !ok {
+			continue
+		}
+		title = trimString(title)
+		updatedItems[i].Text = title
+
+		ghIssue, exists := githubIssuesMap[*todoItem.IssueNumber]
+		if !exists || renamed[ghIssue.Number] || normalizeTitle(ghIssue.Title) == normalizeTitle(title) {
+			continue
+		}
+		renamed[ghIssue.Number] = true
+		operations = append(operations, TodoOperation{
+			Todo: updatedItems[i],
+			Operation: RenameIssueOp{
+				Number: ghIssue.Number,
+				Title:  title,
+			},
+		})
+	}
+
+	return updatedItems, operations
+}
@@ -0,0 +1,105 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+func TestFindTitleConflicts(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Unrelated", IssueNumber: uint64Ptr(1)},
+		{Text: " Local title ", IssueNumber: uint64Ptr(2)},
+		{Text: "Closed locally", IssueNumber: uint64Ptr(3)},
+		{Text: "Duplicate reference", IssueNumber: uint64Ptr(2)},
+		{Text: "No issue"},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "Unrelated", State: IssueStateOpen},
+		{Number: 2, Title: "Remote title", State: IssueStateOpen},
+		{Number: 3, Title: "Closed remotely", State: IssueStateClosed},
+	}
+
+	conflicts := FindTitleConflicts(todoItems, githubIssues, []uint64{2, 3, 4})
+
+	expected := []TitleConflict{{Number: 2, Local: "Local title", Remote: "Remote title"}}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("expected %+v, got %+v", expected, conflicts)
+	}
+}
+
+func TestResolveTitleConflictsKeepingRemoteTitle(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Old title", IssueNumber: uint64Ptr(2)},
+		{Text: "Other", IssueNumber: uint64Ptr(5)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 2, Title: "New title", State: IssueStateOpen},
+		{Number: 5, Title: "Other", State: IssueStateOpen},
+	}
+
+	items, operations := ResolveTitleConflicts(todoItems, githubIssues, map[uint64]string{2: "New title"})
+
+	if items[0].Text != "New title" {
+		t.Errorf("expected text 'New title', got '%s'", items[0].Text)
+	}
+	if items[1].Text != "Other" {
+		t.Errorf("expected other item unchanged, got '%s'", items[1].Text)
+	}
+	if todoItems[0].Text != "Old title" {
+		t.Errorf("expected input items unchanged, got '%s'", todoItems[0].Text)
+	}
+	if len(operations) != 0 {
+		t.Errorf("expected no operations, got %+v", operations)
+	}
+}
+
+func TestResolveTitleConflictsKeepingLocalText(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Local title", IssueNumber: uint64Ptr(2)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 2, Title: "Remote title", State: IssueStateOpen},
+	}
+
+	items, operations := ResolveTitleConflicts(todoItems, githubIssues, map[uint64]string{2: "Local title"})
+
+	if items[0].Text != "Local title" {
+		t.Errorf("expected text 'Local title', got '%s'", items[0].Text)
+	}
+	if len(operations) != 1 {
+		t.Fatalf("expected 1 operation, got %d", len(operations))
+	}
+	renameOp, ok := operations[0].Operation.(RenameIssueOp)
+	if !ok {
+		t.Fatalf("expected RenameIssueOp, got %T", operations[0].Operation)
+	}
+	if renameOp.Number != 2 || renameOp.Title != "Local title" {
+		t.Errorf("expected rename of #2 to 'Local title', got %+v", renameOp)
+	}
+}
+
+func TestResolveTitleConflictsWithEditedTitle(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Local title", IssueNumber: uint64Ptr(2)},
+		{Text: "Local title again", IssueNumber: uint64Ptr(2)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 2, Title: "Remote title", State: IssueStateOpen},
+	}
+
+	items, operations := ResolveTitleConflicts(todoItems, githubIssues, map[uint64]string{2: " Edited title "})
+
+	for i, item := range items {
+		if item.Text != "Edited title" {
+			t.Errorf("items[%d]: expected text 'Edited title', got '%s'", i, item.Text)
+		}
+	}
+	if len(operations) != 1 {
+		t.Fatalf("expected 1 operation, got %d", len(operations))
+	}
+	if renameOp := operations[0].Operation.(RenameIssueOp); renameOp.Title != "Edited title" {
+		t.Errorf("expected rename to 'Edited title', got '%s'", renameOp.Title)
+	}
+}
@@ -0,0 +1,97 @@
+package github
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+// CalculateDueDateOperations determines milestone assignments for todo items with a due date.
+// An operation is generated for each open issue whose milestone is missing or due on a
+// different date than the todo item.
+func CalculateDueDateOperations(todoItems []todo.TodoItem, githubIssues []GitHubIssue) []TodoOperation {
+	githubIssuesMap := make(map[uint64]GitHubIssue)
+	for _, issue := range githubIssues {
+		githubIssuesMap[issue.Number] = issue
+	}
+
+	var operations []TodoOperation
+	for _, todoItem := range todoItems {
+		if todoItem.IssueNumber == nil || todoItem.DueDate == nil {
+			continue
+		}
+		ghIssue, exists := githubIssuesMap[*todoItem.IssueNumber]
+		if !exists || ghIssue.State != IssueStateOpen {
+			continue
+		}
+		if ghIssue.Milestone != nil && ghIssue.Milestone.DueOn != nil && sameDate(*ghIssue.Milestone.DueOn, *todoItem.DueDate) {
+			continue
+		}
+
+		operations = append(operations, TodoOperation{
+			Todo: todoItem,
+			Operation: SetMilestoneOp{
+				Number:  ghIssue.Number,
+				DueDate: dateOf(*todoItem.DueDate),
+			},
+		})
+	}
+
+	return operations
+}
+
+// SynchronizeDueDates updates the due date of todo items from the due date of
+// the milestone their GitHub issue belongs to. Items whose issue has no milestone
+// due date are kept as-is.
+func SynchronizeDueDates(todoItems []todo.TodoItem, githubIssues []GitHubIssue) []todo.TodoItem {
+	githubIssuesMap := make(map[uint64]GitHubIssue)
+	for _, issue := range githubIssues {
+		githubIssuesMap[issue.Number] = issue
+	}
+
+	updatedItems := make([]todo.TodoItem, 0, len(todoItems))
+	for _, todoItem := range todoItems {
+		updated := todoItem
+
+		if todoItem.IssueNumber != nil {
+			if ghIssue, exists := githubIssuesMap[*todoItem.IssueNumber]; exists &&
+				ghIssue.Milestone != nil && ghIssue.Milestone.DueOn != nil {
+				if todoItem.DueDate == nil || !sameDate(*todoItem.DueDate, *ghIssue.Milestone.DueOn) {
+					dueDate := dateOf(*ghIssue.Milestone.DueOn)
+					updated.DueDate = &dueDate
+				}
+			}
+		}
+
+		updatedItems = append(updatedItems, updated)
+	}
+
+	return updatedItems
+}
+
+// FindMilestoneByDueDate returns the first milestone due on the same date as dueDate.
+func FindMilestoneByDueDate(milestones []Milestone, dueDate time.Time) (Milestone, bool) {
+	for _, milestone := range milestones {
+		if milestone.DueOn != nil && sameDate(*milestone.DueOn, dueDate) {
+			return milestone, true
+		}
+	}
+	return Milestone{}, false
+}
+
+// MilestoneTitleForDueDate returns the title used for milestones created for a due date.
+func MilestoneTitleForDueDate(dueDate time.Time) string {
+	return fmt.Sprintf("Due %s", dueDate.UTC().Format(todo.DueDateLayout))
+}
+
+// sameDate reports whether a and b fall on the same calendar date in UTC.
+func sameDate(a, b time.Time) bool {
+	return dateOf(a).Equal(dateOf(b))
+}
+
+// dateOf truncates t to midnight UTC of its calendar date in UTC.
+func dateOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
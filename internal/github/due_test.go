@@ -0,0 +1,158 @@
+package github
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+func datePtr(year int, month time.Month, day int) *time.Time {
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &d
+}
+
+func TestParseGitHubIssuesParsesMilestone(t *testing.T) {
+	issuesJSON := []json.RawMessage{
+		json.RawMessage(`{
+			"number": 1,
+			"title": "With milestone",
+			"state": "open",
+			"milestone": {"number": 3, "title": "Due 2026-11-01", "due_on": "2026-11-01T07:00:00Z"}
+		}`),
+		json.RawMessage(`{
+			"number": 2,
+			"title": "Without milestone",
+			"state": "open",
+			"milestone": null
+		}`),
+	}
+
+	issues := ParseGitHubIssues(issuesJSON)
+
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d", len(issues))
+	}
+	if issues[0].Milestone == nil {
+		t.Fatal("expected milestone on first issue")
+	}
+	if issues[0].Milestone.Number != 3 {
+		t.Errorf("expected milestone number 3, got %d", issues[0].Milestone.Number)
+	}
+	if issues[0].Milestone.DueOn == nil || !sameDate(*issues[0].Milestone.DueOn, *datePtr(2026, 11, 1)) {
+		t.Errorf("expected milestone due on 2026-11-01, got %v", issues[0].Milestone.DueOn)
+	}
+	if issues[1].Milestone != nil {
+		t.Errorf("expected no milestone on second issue, got %+v", issues[1].Milestone)
+	}
+}
+
+func TestParseMilestonesSkipsInvalidEntries(t *testing.T) {
+	milestonesJSON := []json.RawMessage{
+		json.RawMessage(`{"number": 1, "title": "No due date", "due_on": null}`),
+		json.RawMessage(`{"number": 2, "title": "Due", "due_on": "2026-11-01T00:00:00Z"}`),
+		json.RawMessage(`{"title": "Missing number"}`),
+		json.RawMessage(`invalid`),
+	}
+
+	milestones := ParseMilestones(milestonesJSON)
+
+	if len(milestones) != 2 {
+		t.Fatalf("expected 2 milestones, got %d", len(milestones))
+	}
+	if milestones[0].DueOn != nil {
+		t.Errorf("expected no due date, got %v", milestones[0].DueOn)
+	}
+	if milestones[1].DueOn == nil {
+		t.Error("expected due date on second milestone")
+	}
+}
+
+func TestCalculateDueDateOperationsAssignsMilestone(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "No milestone", IssueNumber: uint64Ptr(1), DueDate: datePtr(2026, 11, 1)},
+		{Text: "Different date", IssueNumber: uint64Ptr(2), DueDate: datePtr(2026, 11, 1)},
+		{Text: "Same date", IssueNumber: uint64Ptr(3), DueDate: datePtr(2026, 11, 1)},
+		{Text: "Closed", IsChecked: true, IssueNumber: uint64Ptr(4), DueDate: datePtr(2026, 11, 1)},
+		{Text: "No due date", IssueNumber: uint64Ptr(5)},
+		{Text: "No issue", DueDate: datePtr(2026, 11, 1)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "No milestone", State: IssueStateOpen},
+		{Number: 2, Title: "Different date", State: IssueStateOpen, Milestone: &Milestone{Number: 7, DueOn: datePtr(2026, 12, 1)}},
+		{Number: 3, Title: "Same date", State: IssueStateOpen, Milestone: &Milestone{Number: 8, DueOn: datePtr(2026, 11, 1)}},
+		{Number: 4, Title: "Closed", State: IssueStateClosed},
+		{Number: 5, Title: "No due date", State: IssueStateOpen},
+	}
+
+	operations := CalculateDueDateOperations(todoItems, githubIssues)
+
+	if len(operations) != 2 {
+		t.Fatalf("expected 2 operations, got %d", len(operations))
+	}
+	for i, expected := range []uint64{1, 2} {
+		op, ok := operations[i].Operation.(SetMilestoneOp)
+		if !ok {
+			t.Fatalf("expected SetMilestoneOp, got %T", operations[i].Operation)
+		}
+		if op.Number != expected {
+			t.Errorf("expected issue #%d, got #%d", expected, op.Number)
+		}
+		if !op.DueDate.Equal(*datePtr(2026, 11, 1)) {
+			t.Errorf("expected due date 2026-11-01, got %v", op.DueDate)
+		}
+	}
+}
+
+func TestSynchronizeDueDatesUsesMilestoneDueDate(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Gets due date", IssueNumber: uint64Ptr(1)},
+		{Text: "Keeps local date", IssueNumber: uint64Ptr(2), DueDate: datePtr(2026, 11, 1)},
+		{Text: "Updated date", IssueNumber: uint64Ptr(3), DueDate: datePtr(2026, 11, 1)},
+		{Text: "No issue", DueDate: datePtr(2026, 11, 1)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "Gets due date", State: IssueStateOpen, Milestone: &Milestone{Number: 7, DueOn: datePtr(2026, 12, 1)}},
+		{Number: 2, Title: "Keeps local date", State: IssueStateOpen},
+		{Number: 3, Title: "Updated date", State: IssueStateOpen, Milestone: &Milestone{Number: 7, DueOn: datePtr(2026, 12, 1)}},
+	}
+
+	updated := SynchronizeDueDates(todoItems, githubIssues)
+
+	expected := []*time.Time{datePtr(2026, 12, 1), datePtr(2026, 11, 1), datePtr(2026, 12, 1), datePtr(2026, 11, 1)}
+	if len(updated) != len(expected) {
+		t.Fatalf("expected %d items, got %d", len(expected), len(updated))
+	}
+	for i, want := range expected {
+		if updated[i].DueDate == nil || !updated[i].DueDate.Equal(*want) {
+			t.Errorf("item[%d]: expected due date %v, got %v", i, *want, updated[i].DueDate)
+		}
+	}
+}
+
+func TestFindMilestoneByDueDate(t *testing.T) {
+	milestones := []Milestone{
+		{Number: 1, Title: "No due date"},
+		{Number: 2, Title: "Due 2026-11-01", DueOn: datePtr(2026, 11, 1)},
+	}
+
+	milestone, found := FindMilestoneByDueDate(milestones, time.Date(2026, 11, 1, 7, 0, 0, 0, time.UTC))
+	if !found {
+		t.Fatal("expected milestone to be found")
+	}
+	if milestone.Number != 2 {
+		t.Errorf("expected milestone 2, got %d", milestone.Number)
+	}
+
+	if _, found := FindMilestoneByDueDate(milestones, *datePtr(2026, 12, 1)); found {
+		t.Error("expected no milestone for 2026-12-01")
+	}
+}
+
+func TestMilestoneTitleForDueDate(t *testing.T) {
+	title := MilestoneTitleForDueDate(*datePtr(2026, 11, 1))
+	if title != "Due 2026-11-01" {
+		t.Errorf("expected 'Due 2026-11-01', got '%s'", title)
+	}
+}
@@ -0,0 +1,114 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/toms74209200/gh-atat/internal/errs"
+)
+
+// ghExitAuthRequired is the exit code of gh when it has no credentials to make a request with
+const ghExitAuthRequired = 4
+
+// APIResponse is the response of a `gh api --include` call
+type APIResponse struct {
+	// Status is the HTTP status code, or 0 if gh received no response
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// ParseAPIResponse splits output, the output of a `gh api --include` call, into the status,
+// headers and body of the response.
+// Returns a zero Status and output as the body if output doesn't start with a status line.
+func ParseAPIResponse(output []byte) APIResponse {
+	reader := bufio.NewReader(bytes.NewReader(output))
+	statusLine, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(statusLine, "HTTP/") {
+		return APIResponse{Body: output}
+	}
+	fields := strings.Fields(statusLine)
+	if len(fields) < 2 {
+		return APIResponse{Body: output}
+	}
+	status, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return APIResponse{Body: output}
+	}
+	header, err := textproto.NewReader(reader).ReadMIMEHeader()
+	if err != nil {
+		return APIResponse{Body: output}
+	}
+	body, _ := io.ReadAll(reader)
+	return APIResponse{Status: status, Header: http.Header(header), Body: body}
+}
+
+// graphQLErrorTypes returns the types of the errors in a GraphQL response body
+func graphQLErrorTypes(body []byte) []string {
+	var response struct {
+		Errors []struct {
+			Type string `json:"type"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &response) != nil {
+		return nil
+	}
+	types := make([]string, 0, len(response.Errors))
+	for _, e := range response.Errors {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+// rateLimited checks if response reports an exceeded primary or secondary rate limit
+func rateLimited(response APIResponse) bool {
+	switch response.Status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return response.Header.Get("X-Ratelimit-Remaining") == "0" || response.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// ClassifyAPIError wraps err, the error of a failed `gh api --include` call, in the errs type
+// matching the HTTP status of response, or exitCode, the exit code of gh, if there was no response.
+// Returns err unchanged if the failure has no more specific type.
+func ClassifyAPIError(err error, exitCode int, response APIResponse) error {
+	if rateLimited(response) {
+		return &errs.RateLimitError{Err: err}
+	}
+	switch response.Status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &errs.AuthError{Err: err}
+	case http.StatusNotFound, http.StatusGone:
+		return &errs.NotFoundError{Err: err}
+	case http.StatusOK:
+		for _, errorType := range graphQLErrorTypes(response.Body) {
+			switch errorType {
+			case "RATE_LIMITED":
+				return &errs.RateLimitError{Err: err}
+			case "FORBIDDEN":
+				return &errs.AuthError{Err: err}
+			case "NOT_FOUND":
+				return &errs.NotFoundError{Err: err}
+			}
+		}
+		return err
+	case 0:
+		// gh exits without a response when it can't reach GitHub
+		switch {
+		case exitCode == ghExitAuthRequired:
+			return &errs.AuthError{Err: err}
+		case exitCode > 0:
+			return &errs.NetworkError{Err: err}
+		}
+	}
+	return err
+}
@@ -0,0 +1,169 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/toms74209200/gh-atat/internal/errs"
+)
+
+func TestParseAPIResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   APIResponse
+	}{
+		{
+			name:   "response with headers",
+			output: "HTTP/2.0 404 Not Found\r\nContent-Type: application/json\r\nX-Ratelimit-Remaining: 4999\r\n\r\n{\"message\":\"Not Found\"}",
+			want: APIResponse{
+				Status: 404,
+				Header: http.Header{"Content-Type": {"application/json"}, "X-Ratelimit-Remaining": {"4999"}},
+				Body:   []byte(`{"message":"Not Found"}`),
+			},
+		},
+		{
+			name:   "LF line endings",
+			output: "HTTP/1.1 200 OK\nContent-Type: application/json\n\n[]",
+			want: APIResponse{
+				Status: 200,
+				Header: http.Header{"Content-Type": {"application/json"}},
+				Body:   []byte("[]"),
+			},
+		},
+		{
+			name:   "no response",
+			output: "",
+			want:   APIResponse{Body: []byte("")},
+		},
+		{
+			name:   "no status line",
+			output: `{"message":"Not Found"}`,
+			want:   APIResponse{Body: []byte(`{"message":"Not Found"}`)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAPIResponse([]byte(tt.output))
+
+			if got.Status != tt.want.Status || !reflect.DeepEqual(got.Header, tt.want.Header) || string(got.Body) != string(tt.want.Body) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClassifyAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		exitCode int
+		response APIResponse
+		want     int
+	}{
+		{
+			name:     "not found",
+			exitCode: 1,
+			response: APIResponse{Status: 404},
+			want:     errs.ExitNotFound,
+		},
+		{
+			name:     "gone",
+			exitCode: 1,
+			response: APIResponse{Status: 410},
+			want:     errs.ExitNotFound,
+		},
+		{
+			name:     "GraphQL not found",
+			exitCode: 1,
+			response: APIResponse{Status: 200, Body: []byte(`{"errors":[{"type":"NOT_FOUND","message":"Could not resolve to a ProjectV2"}]}`)},
+			want:     errs.ExitNotFound,
+		},
+		{
+			name:     "GraphQL forbidden",
+			exitCode: 1,
+			response: APIResponse{Status: 200, Body: []byte(`{"errors":[{"type":"FORBIDDEN","message":"Resource not accessible by integration"}]}`)},
+			want:     errs.ExitAuth,
+		},
+		{
+			name:     "bad credentials",
+			exitCode: 1,
+			response: APIResponse{Status: 401},
+			want:     errs.ExitAuth,
+		},
+		{
+			name:     "not logged in",
+			exitCode: 4,
+			want:     errs.ExitAuth,
+		},
+		{
+			name:     "forbidden",
+			exitCode: 1,
+			response: APIResponse{Status: 403, Header: http.Header{"X-Ratelimit-Remaining": {"4999"}}},
+			want:     errs.ExitAuth,
+		},
+		{
+			name:     "rate limit exceeded",
+			exitCode: 1,
+			response: APIResponse{Status: 403, Header: http.Header{"X-Ratelimit-Remaining": {"0"}}},
+			want:     errs.ExitRateLimited,
+		},
+		{
+			name:     "secondary rate limit",
+			exitCode: 1,
+			response: APIResponse{Status: 403, Header: http.Header{"Retry-After": {"60"}}},
+			want:     errs.ExitRateLimited,
+		},
+		{
+			name:     "too many requests",
+			exitCode: 1,
+			response: APIResponse{Status: 429},
+			want:     errs.ExitRateLimited,
+		},
+		{
+			name:     "GraphQL rate limited",
+			exitCode: 1,
+			response: APIResponse{Status: 200, Body: []byte(`{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`)},
+			want:     errs.ExitRateLimited,
+		},
+		{
+			name:     "network down",
+			exitCode: 1,
+			want:     errs.ExitNetwork,
+		},
+		{
+			name:     "gh not started",
+			exitCode: -1,
+			want:     errs.ExitError,
+		},
+		{
+			name:     "server error",
+			exitCode: 1,
+			response: APIResponse{Status: 500},
+			want:     errs.ExitError,
+		},
+		{
+			name:     "validation failed",
+			exitCode: 1,
+			response: APIResponse{Status: 422},
+			want:     errs.ExitError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cause := errors.New("gh api failed")
+
+			err := ClassifyAPIError(cause, tt.exitCode, tt.response)
+
+			if got := errs.ExitCode(err); got != tt.want {
+				t.Errorf("expected exit code %d, got %d", tt.want, got)
+			}
+			if !errors.Is(err, cause) {
+				t.Errorf("expected the error to wrap %v, got %v", cause, err)
+			}
+		})
+	}
+}
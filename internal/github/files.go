@@ -0,0 +1,109 @@
+package github
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+// TodoFile represents the todo items of a tracked TODO file and the
+// repository and label the file is bound to
+type TodoFile struct {
+	Path  string
+	Repo  string
+	Label string
+	Items []todo.TodoItem
+	// Sections are the sections of the file, including those without items
+	Sections []todo.Section
+}
+
+// issueKey identifies an issue across repositories
+type issueKey struct {
+	repo   string
+	number uint64
+}
+
+// referencedIssues returns the issues referenced by the items of a file
+func referencedIssues(file TodoFile) []issueKey {
+	var keys []issueKey
+	for _, item := range file.Items {
+		switch {
+		case item.IssueNumber != nil:
+			keys = append(keys, issueKey{repo: strings.ToLower(file.Repo), number: *item.IssueNumber})
+		case item.ExternalIssue != nil:
+			keys = append(keys, issueKey{repo: strings.ToLower(item.ExternalIssue.Repo), number: item.ExternalIssue.Number})
+		}
+	}
+	return keys
+}
+
+// FindDuplicateIssueReferences checks that no issue is referenced from more than one file.
+// Returns an error naming the first issue referenced from two files.
+func FindDuplicateIssueReferences(files []TodoFile) error {
+	referencedBy := make(map[issueKey]string)
+	for _, file := range files {
+		for _, key := range referencedIssues(file) {
+			if path, exists := referencedBy[key]; exists && path != file.Path {
+				return fmt.Errorf("issue %s#%d is referenced in both %s and %s", key.repo, key.number, path, file.Path)
+			}
+			referencedBy[key] = file.Path
+		}
+	}
+	return nil
+}
+
+// FilterIssuesForFile returns the GitHub issues of file's repository that belong to file.
+//
+// Issues referenced by file always belong to it, and issues referenced by other files
+// of the same repository never do. Other issues belong to file if they carry its label,
+// or, if file has no label, if they carry none of the labels of the other files.
+func FilterIssuesForFile(githubIssues []GitHubIssue, file TodoFile, others []TodoFile) []GitHubIssue {
+	own := make(map[uint64]bool)
+	for _, item := range file.Items {
+		if item.IssueNumber != nil {
+			own[*item.IssueNumber] = true
+		}
+	}
+
+	claimed := make(map[uint64]bool)
+	var otherLabels []string
+	for _, other := range others {
+		if other.Path == file.Path || !strings.EqualFold(other.Repo, file.Repo) {
+			continue
+		}
+		for _, item := range other.Items {
+			if item.IssueNumber != nil {
+				claimed[*item.IssueNumber] = true
+			}
+		}
+		if other.Label != "" {
+			otherLabels = append(otherLabels, other.Label)
+		}
+	}
+
+	var issues []GitHubIssue
+	for _, issue := range githubIssues {
+		switch {
+		case own[issue.Number]:
+		case claimed[issue.Number]:
+			continue
+		case file.Label != "":
+			if !hasLabel(issue, file.Label) {
+				continue
+			}
+		default:
+			if slices.ContainsFunc(otherLabels, func(label string) bool { return hasLabel(issue, label) }) {
+				continue
+			}
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// hasLabel checks if an issue carries a label, ignoring case like GitHub does
+func hasLabel(issue GitHubIssue, label string) bool {
+	return slices.ContainsFunc(issue.Labels, func(l string) bool { return strings.EqualFold(l, label) })
+}
@@ -0,0 +1,92 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+func TestFindDuplicateIssueReferences(t *testing.T) {
+	todoFile := TodoFile{Path: "TODO.md", Repo: "owner/repo", Items: []todo.TodoItem{
+		{Text: "A", IssueNumber: uint64Ptr(1)},
+		{Text: "A again", IssueNumber: uint64Ptr(1)},
+		{Text: "External", ExternalIssue: &todo.IssueRef{Repo: "other/repo", Number: 2}},
+	}}
+
+	t.Run("unique across files", func(t *testing.T) {
+		roadmap := TodoFile{Path: "docs/ROADMAP.md", Repo: "other/repo", Items: []todo.TodoItem{
+			{Text: "Same number, other repo", IssueNumber: uint64Ptr(1)},
+		}}
+		if err := FindDuplicateIssueReferences([]TodoFile{todoFile, roadmap}); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("same issue in two files", func(t *testing.T) {
+		pkg := TodoFile{Path: "pkg/TODO.md", Repo: "Owner/Repo", Items: []todo.TodoItem{
+			{Text: "B", IssueNumber: uint64Ptr(1)},
+		}}
+		err := FindDuplicateIssueReferences([]TodoFile{todoFile, pkg})
+		expected := "issue owner/repo#1 is referenced in both TODO.md and pkg/TODO.md"
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	})
+
+	t.Run("external reference to an issue of another file", func(t *testing.T) {
+		roadmap := TodoFile{Path: "docs/ROADMAP.md", Repo: "other/repo", Items: []todo.TodoItem{
+			{Text: "C", IssueNumber: uint64Ptr(2)},
+		}}
+		if err := FindDuplicateIssueReferences([]TodoFile{todoFile, roadmap}); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestFilterIssuesForFile(t *testing.T) {
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "Own", State: IssueStateOpen, Labels: []string{"roadmap"}},
+		{Number: 2, Title: "Other file", State: IssueStateOpen},
+		{Number: 3, Title: "Roadmap", State: IssueStateOpen, Labels: []string{"Roadmap"}},
+		{Number: 4, Title: "Unlabelled", State: IssueStateOpen},
+	}
+	todoFile := TodoFile{Path: "TODO.md", Repo: "owner/repo", Items: []todo.TodoItem{
+		{Text: "Own", IssueNumber: uint64Ptr(1)},
+	}}
+	roadmap := TodoFile{Path: "docs/ROADMAP.md", Repo: "owner/repo", Label: "roadmap", Items: []todo.TodoItem{
+		{Text: "Other file", IssueNumber: uint64Ptr(2)},
+	}}
+	files := []TodoFile{todoFile, roadmap}
+
+	numbers := func(issues []GitHubIssue) []uint64 {
+		var result []uint64
+		for _, issue := range issues {
+			result = append(result, issue.Number)
+		}
+		return result
+	}
+
+	t.Run("unlabelled file", func(t *testing.T) {
+		actual := numbers(FilterIssuesForFile(githubIssues, todoFile, files))
+		if len(actual) != 2 || actual[0] != 1 || actual[1] != 4 {
+			t.Errorf("expected [1 4], got %v", actual)
+		}
+	})
+
+	t.Run("labelled file", func(t *testing.T) {
+		actual := numbers(FilterIssuesForFile(githubIssues, roadmap, files))
+		if len(actual) != 2 || actual[0] != 2 || actual[1] != 3 {
+			t.Errorf("expected [2 3], got %v", actual)
+		}
+	})
+
+	t.Run("other repository is ignored", func(t *testing.T) {
+		elsewhere := TodoFile{Path: "other/TODO.md", Repo: "other/repo", Items: []todo.TodoItem{
+			{Text: "Other file", IssueNumber: uint64Ptr(4)},
+		}}
+		actual := numbers(FilterIssuesForFile(githubIssues, todoFile, []TodoFile{todoFile, elsewhere}))
+		if len(actual) != 4 {
+			t.Errorf("expected all issues, got %v", actual)
+		}
+	})
+}
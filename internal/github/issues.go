@@ -1,5 +1,7 @@
 package github
 
+import "time"
+
 // IssueState represents the state of a GitHub issue
 type IssueState string
 
@@ -8,9 +10,39 @@ const (
 	IssueStateClosed IssueState = "closed"
 )
 
+// IssueStateReason represents the reason for the state of a GitHub issue
+type IssueStateReason string
+
+const (
+	IssueStateReasonNone       IssueStateReason = ""
+	IssueStateReasonCompleted  IssueStateReason = "completed"
+	IssueStateReasonNotPlanned IssueStateReason = "not_planned"
+	IssueStateReasonReopened   IssueStateReason = "reopened"
+)
+
 // GitHubIssue represents a GitHub issue
 type GitHubIssue struct {
+	Number      uint64
+	NodeID      string
+	Title       string
+	State       IssueState
+	StateReason IssueStateReason
+	Milestone   *Milestone
+	Labels      []string
+	// CreatedAt is when the issue was created, or nil if unknown
+	CreatedAt *time.Time
+}
+
+// Milestone represents a GitHub milestone
+type Milestone struct {
 	Number uint64
 	Title  string
+	DueOn  *time.Time
+}
+
+// PullRequest represents a GitHub pull request
+type PullRequest struct {
+	Number uint64
 	State  IssueState
+	Merged bool
 }
@@ -0,0 +1,209 @@
+package github
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+// linkedPullRequestsBatchSize is the number of issues whose linked pull requests are
+// looked up with one GraphQL query
+const linkedPullRequestsBatchSize = 50
+
+// LinkedPullRequestsFetcher is a function type that fetches the pull requests linked to
+// issues from GitHub GraphQL API
+// Parameters: issueNumbers, to look up with the query built by LinkedPullRequestsQuery
+// Returns: GraphQL response body and error
+type LinkedPullRequestsFetcher func(issueNumbers []uint64) ([]byte, error)
+
+// linkedPullRequestsFragment selects the pull requests closing an issue and the pull
+// requests referencing it in its timeline
+const linkedPullRequestsFragment = `fragment links on Issue {
+  number
+  closedByPullRequestsReferences(first: 100, includeClosedPrs: true) {
+    nodes { number state merged repository { nameWithOwner } }
+  }
+  timelineItems(itemTypes: [CROSS_REFERENCED_EVENT], first: 100) {
+    nodes { ... on CrossReferencedEvent { source { ... on PullRequest { number state merged repository { nameWithOwner } } } } }
+  }
+}`
+
+// LinkedPullRequestsQuery builds the GraphQL query looking up the pull requests linked to
+// each of issueNumbers in the repository given by the $owner and $name variables
+func LinkedPullRequestsQuery(issueNumbers []uint64) string {
+	var builder strings.Builder
+	builder.WriteString("query($owner: String!, $name: String!) {\n  repository(owner: $owner, name: $name) {\n")
+	for _, issueNumber := range issueNumbers {
+		fmt.Fprintf(&builder, "    issue%d: issue(number: %d) { ...links }\n", issueNumber, issueNumber)
+	}
+	builder.WriteString("  }\n}\n")
+	builder.WriteString(linkedPullRequestsFragment)
+	return builder.String()
+}
+
+// linkedPullRequest is the shape of a pull request in the GraphQL response
+type linkedPullRequest struct {
+	Number     uint64 `json:"number"`
+	State      string `json:"state"`
+	Merged     bool   `json:"merged"`
+	Repository *struct {
+		NameWithOwner string `json:"nameWithOwner"`
+	} `json:"repository"`
+}
+
+// linkedPullRequestsResponse is the shape of the GraphQL response for linked pull requests
+type linkedPullRequestsResponse struct {
+	Data struct {
+		Repository map[string]*struct {
+			Number                         uint64 `json:"number"`
+			ClosedByPullRequestsReferences struct {
+				Nodes []linkedPullRequest `json:"nodes"`
+			} `json:"closedByPullRequestsReferences"`
+			TimelineItems struct {
+				Nodes []struct {
+					Source *linkedPullRequest `json:"source"`
+				} `json:"nodes"`
+			} `json:"timelineItems"`
+		} `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// ParseLinkedPullRequests parses a GraphQL response for the query built by
+// LinkedPullRequestsQuery. Returns the pull requests of repo linked to each issue, keyed by
+// issue number, the linked pull requests and an error.
+// A pull request is linked when it closes the issue or references it in the issue timeline.
+func ParseLinkedPullRequests(data []byte, repo string) (map[uint64][]uint64, []PullRequest, error) {
+	var response linkedPullRequestsResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse linked pull requests response: %w", err)
+	}
+	if len(response.Errors) > 0 {
+		return nil, nil, fmt.Errorf("failed to fetch linked pull requests: %s", response.Errors[0].Message)
+	}
+	if response.Data.Repository == nil {
+		return nil, nil, fmt.Errorf("repository %s not found", repo)
+	}
+
+	links := make(map[uint64][]uint64)
+	var pullRequests []PullRequest
+	for _, issue := range response.Data.Repository {
+		if issue == nil {
+			continue
+		}
+		candidates := slices.Clone(issue.ClosedByPullRequestsReferences.Nodes)
+		for _, node := range issue.TimelineItems.Nodes {
+			if node.Source != nil {
+				candidates = append(candidates, *node.Source)
+			}
+		}
+
+		linked := []uint64{}
+		for _, candidate := range candidates {
+			// Timeline sources that are issues have no number in the response
+			if candidate.Number == 0 || candidate.Repository == nil || !strings.EqualFold(candidate.Repository.NameWithOwner, repo) {
+				continue
+			}
+			if slices.Contains(linked, candidate.Number) {
+				continue
+			}
+			linked = append(linked, candidate.Number)
+
+			if !slices.ContainsFunc(pullRequests, func(pullRequest PullRequest) bool { return pullRequest.Number == candidate.Number }) {
+				state := IssueStateClosed
+				if candidate.State == "OPEN" {
+					state = IssueStateOpen
+				}
+				pullRequests = append(pullRequests, PullRequest{Number: candidate.Number, State: state, Merged: candidate.Merged})
+			}
+		}
+		slices.Sort(linked)
+		links[issue.Number] = linked
+	}
+	slices.SortFunc(pullRequests, func(a, b PullRequest) int { return cmp.Compare(a.Number, b.Number) })
+
+	return links, pullRequests, nil
+}
+
+// FetchLinkedPullRequests looks up the pull requests linked to the issue of each unchecked
+// todo item, with one GraphQL query per linkedPullRequestsBatchSize issues. Only issues in
+// githubIssues are looked up, so references to deleted issues don't fail the query.
+// Returns the links keyed by issue number and the linked pull requests, like
+// ParseLinkedPullRequests.
+func FetchLinkedPullRequests(todoItems []todo.TodoItem, githubIssues []GitHubIssue, repo string, fetcher LinkedPullRequestsFetcher) (map[uint64][]uint64, []PullRequest, error) {
+	var issueNumbers []uint64
+	for _, todoItem := range todoItems {
+		if todoItem.IsChecked || todoItem.IssueNumber == nil || slices.Contains(issueNumbers, *todoItem.IssueNumber) {
+			continue
+		}
+		if slices.ContainsFunc(githubIssues, func(issue GitHubIssue) bool { return issue.Number == *todoItem.IssueNumber }) {
+			issueNumbers = append(issueNumbers, *todoItem.IssueNumber)
+		}
+	}
+
+	links := make(map[uint64][]uint64)
+	var pullRequests []PullRequest
+	for batch := range slices.Chunk(issueNumbers, linkedPullRequestsBatchSize) {
+		data, err := fetcher(batch)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch linked pull requests: %w", err)
+		}
+		batchLinks, batchPullRequests, err := ParseLinkedPullRequests(data, repo)
+		if err != nil {
+			return nil, nil, err
+		}
+		maps.Copy(links, batchLinks)
+		for _, pullRequest := range batchPullRequests {
+			if !slices.ContainsFunc(pullRequests, func(known PullRequest) bool { return known.Number == pullRequest.Number }) {
+				pullRequests = append(pullRequests, pullRequest)
+			}
+		}
+	}
+
+	return links, pullRequests, nil
+}
+
+// NeedsPullRequests reports whether an unchecked todo item references an issue, so the
+// pull requests linked to it are looked up
+func NeedsPullRequests(todoItems []todo.TodoItem) bool {
+	return slices.ContainsFunc(todoItems, func(todoItem todo.TodoItem) bool {
+		return !todoItem.IsChecked && todoItem.IssueNumber != nil
+	})
+}
+
+// SynchronizePullRequests sets the linked pull requests of todo items whose issue was
+// looked up. If markMergedDone is true, unchecked items with a merged linked pull
+// request are marked as done. Items whose issue was not looked up keep their links.
+func SynchronizePullRequests(todoItems []todo.TodoItem, pullRequests []PullRequest, links map[uint64][]uint64, markMergedDone bool) []todo.TodoItem {
+	merged := make(map[uint64]bool)
+	for _, pullRequest := range pullRequests {
+		if pullRequest.Merged {
+			merged[pullRequest.Number] = true
+		}
+	}
+
+	updatedItems := make([]todo.TodoItem, 0, len(todoItems))
+	for _, todoItem := range todoItems {
+		updated := todoItem
+		if todoItem.IssueNumber != nil {
+			if linked, ok := links[*todoItem.IssueNumber]; ok {
+				updated.PullRequests = linked
+				if markMergedDone && !todoItem.IsChecked && slices.ContainsFunc(linked, func(number uint64) bool {
+					return merged[number]
+				}) {
+					updated.IsChecked = true
+				}
+			}
+		}
+		updatedItems = append(updatedItems, updated)
+	}
+
+	return updatedItems
+}
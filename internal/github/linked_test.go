@@ -0,0 +1,191 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+func TestLinkedPullRequestsQuery(t *testing.T) {
+	query := LinkedPullRequestsQuery([]uint64{1, 23})
+
+	for _, expected := range []string{
+		"repository(owner: $owner, name: $name)",
+		"issue1: issue(number: 1) { ...links }",
+		"issue23: issue(number: 23) { ...links }",
+		"fragment links on Issue",
+	} {
+		if !strings.Contains(query, expected) {
+			t.Errorf("expected query to contain %q, got:\n%s", expected, query)
+		}
+	}
+}
+
+func TestParseLinkedPullRequests(t *testing.T) {
+	data := []byte(`{"data": {"repository": {
+		"issue1": {
+			"number": 1,
+			"closedByPullRequestsReferences": {"nodes": [
+				{"number": 11, "state": "MERGED", "merged": true, "repository": {"nameWithOwner": "owner/repo"}},
+				{"number": 30, "state": "OPEN", "merged": false, "repository": {"nameWithOwner": "other/repo"}}
+			]},
+			"timelineItems": {"nodes": [
+				{"source": {"number": 10, "state": "OPEN", "merged": false, "repository": {"nameWithOwner": "Owner/Repo"}}},
+				{"source": {"number": 11, "state": "MERGED", "merged": true, "repository": {"nameWithOwner": "owner/repo"}}},
+				{"source": {}},
+				{}
+			]}
+		},
+		"issue2": {
+			"number": 2,
+			"closedByPullRequestsReferences": {"nodes": []},
+			"timelineItems": {"nodes": []}
+		},
+		"issue3": null
+	}}}`)
+
+	links, pullRequests, err := ParseLinkedPullRequests(data, "owner/repo")
+	if err != nil {
+		t.Fatalf("ParseLinkedPullRequests failed: %v", err)
+	}
+
+	if len(links) != 2 || !slices.Equal(links[1], []uint64{10, 11}) || links[2] == nil || len(links[2]) != 0 {
+		t.Errorf("unexpected links: %v", links)
+	}
+	expected := []PullRequest{
+		{Number: 10, State: IssueStateOpen},
+		{Number: 11, State: IssueStateClosed, Merged: true},
+	}
+	if !slices.Equal(pullRequests, expected) {
+		t.Errorf("expected %+v, got %+v", expected, pullRequests)
+	}
+}
+
+func TestParseLinkedPullRequestsErrors(t *testing.T) {
+	inputs := map[string]string{
+		"invalid json":       `not json`,
+		"graphql error":      `{"errors": [{"message": "Could not resolve to a Repository"}]}`,
+		"missing repository": `{"data": {"repository": null}}`,
+	}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := ParseLinkedPullRequests([]byte(input), "owner/repo"); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestFetchLinkedPullRequestsBatchesIssues(t *testing.T) {
+	var todoItems []todo.TodoItem
+	var githubIssues []GitHubIssue
+	for number := uint64(1); number <= 60; number++ {
+		todoItems = append(todoItems, todo.TodoItem{Text: fmt.Sprintf("Task %d", number), IssueNumber: uint64Ptr(number)})
+		githubIssues = append(githubIssues, GitHubIssue{Number: number, Title: fmt.Sprintf("Task %d", number), State: IssueStateOpen})
+	}
+	todoItems = append(todoItems,
+		todo.TodoItem{Text: "Done", IsChecked: true, IssueNumber: uint64Ptr(61)},
+		todo.TodoItem{Text: "Deleted", IssueNumber: uint64Ptr(62)},
+		todo.TodoItem{Text: "Duplicate", IssueNumber: uint64Ptr(1)},
+	)
+	githubIssues = append(githubIssues, GitHubIssue{Number: 61, Title: "Done", State: IssueStateClosed})
+
+	var batches [][]uint64
+	fetcher := func(issueNumbers []uint64) ([]byte, error) {
+		batches = append(batches, issueNumbers)
+		return []byte(fmt.Sprintf(`{"data": {"repository": {"issue%d": {
+			"number": %d,
+			"closedByPullRequestsReferences": {"nodes": [{"number": 100, "state": "OPEN", "merged": false, "repository": {"nameWithOwner": "owner/repo"}}]},
+			"timelineItems": {"nodes": []}
+		}}}}`, issueNumbers[0], issueNumbers[0])), nil
+	}
+
+	links, pullRequests, err := FetchLinkedPullRequests(todoItems, githubIssues, "owner/repo", fetcher)
+	if err != nil {
+		t.Fatalf("FetchLinkedPullRequests failed: %v", err)
+	}
+
+	if len(batches) != 2 || len(batches[0]) != 50 || len(batches[1]) != 10 || batches[1][0] != 51 {
+		t.Errorf("expected batches of 50 and 10 issues, got %v", batches)
+	}
+	if len(links) != 2 || !slices.Equal(links[1], []uint64{100}) || !slices.Equal(links[51], []uint64{100}) {
+		t.Errorf("unexpected links: %v", links)
+	}
+	if len(pullRequests) != 1 {
+		t.Errorf("expected pull requests without duplicates, got %+v", pullRequests)
+	}
+}
+
+func TestFetchLinkedPullRequestsPropagatesFetcherError(t *testing.T) {
+	todoItems := []todo.TodoItem{{Text: "Task", IssueNumber: uint64Ptr(1)}}
+	githubIssues := []GitHubIssue{{Number: 1, Title: "Task", State: IssueStateOpen}}
+	fetcher := func(issueNumbers []uint64) ([]byte, error) {
+		return nil, errors.New("network error")
+	}
+
+	if _, _, err := FetchLinkedPullRequests(todoItems, githubIssues, "owner/repo", fetcher); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestNeedsPullRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []todo.TodoItem
+		expected bool
+	}{
+		{"no items", nil, false},
+		{"without issue", []todo.TodoItem{{Text: "Task"}}, false},
+		{"checked with issue", []todo.TodoItem{{Text: "Done", IsChecked: true, IssueNumber: uint64Ptr(1)}}, false},
+		{"unchecked with issue", []todo.TodoItem{{Text: "Task", IssueNumber: uint64Ptr(1)}}, true},
+	}
+
+	for _, tt := range tests {
+		if actual := NeedsPullRequests(tt.items); actual != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, actual)
+		}
+	}
+}
+
+func TestSynchronizePullRequests(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Merged", IssueNumber: uint64Ptr(1)},
+		{Text: "Open", IssueNumber: uint64Ptr(2), PullRequests: []uint64{99}},
+		{Text: "Not looked up", IsChecked: true, IssueNumber: uint64Ptr(3), PullRequests: []uint64{12}},
+	}
+	pullRequests := []PullRequest{
+		{Number: 10, State: IssueStateClosed, Merged: true},
+		{Number: 11, State: IssueStateOpen},
+	}
+	links := map[uint64][]uint64{1: {10}, 2: {11}}
+
+	t.Run("links only", func(t *testing.T) {
+		updated := SynchronizePullRequests(todoItems, pullRequests, links, false)
+
+		if !slices.Equal(updated[0].PullRequests, []uint64{10}) || updated[0].IsChecked {
+			t.Errorf("unexpected first item: %+v", updated[0])
+		}
+		if !slices.Equal(updated[1].PullRequests, []uint64{11}) {
+			t.Errorf("expected stale link to be replaced, got %v", updated[1].PullRequests)
+		}
+		if !slices.Equal(updated[2].PullRequests, []uint64{12}) {
+			t.Errorf("expected links of items not looked up to be kept, got %v", updated[2].PullRequests)
+		}
+	})
+
+	t.Run("merged marks done", func(t *testing.T) {
+		updated := SynchronizePullRequests(todoItems, pullRequests, links, true)
+
+		if !updated[0].IsChecked {
+			t.Error("expected item with merged pull request to be checked")
+		}
+		if updated[1].IsChecked {
+			t.Error("expected item with open pull request to stay unchecked")
+		}
+	})
+}
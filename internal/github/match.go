@@ -0,0 +1,197 @@
+package github
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+// maxMatchDistance is the largest edit distance between normalized titles that are still
+// considered similar, however long the titles are
+const maxMatchDistance = 3
+
+// LinkCandidate is an open issue whose title is similar to the text of a todo item that
+// does not reference an issue yet
+type LinkCandidate struct {
+	// Index is the index of the todo item
+	Index int
+	Item  todo.TodoItem
+	Issue GitHubIssue
+	// Distance is the edit distance between the normalized text and title
+	Distance int
+}
+
+// NormalizeForMatch returns title normalized with normalizeTitle, in lower case, without
+// punctuation and with runs of whitespace collapsed to single spaces, so "Fix login bug."
+// and "fix  login bug" are equal
+func NormalizeForMatch(title string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range normalizeTitle(title) {
+		switch {
+		case unicode.IsSpace(r):
+			space = b.Len() > 0
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+		default:
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// EditDistance returns the Levenshtein distance between a and b, counted in runes
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// matchThreshold returns the largest edit distance allowed between normalized titles of
+// the given length in runes. Short titles must match exactly, so "Fix login bug" and
+// "Fix logout bug" are not similar.
+func matchThreshold(length int) int {
+	return min(length/8, maxMatchDistance)
+}
+
+// FindLinkCandidates pairs todo items that reference no issue with open issues referenced
+// by none of the items whose titles are similar to their text.
+//
+// Each item is paired with the issue at the smallest edit distance, the lowest number
+// first, and each issue is paired with one item at most. Candidates are returned in
+// the order of the todo items.
+func FindLinkCandidates(todoItems []todo.TodoItem, githubIssues []GitHubIssue) []LinkCandidate {
+	referenced := make(map[uint64]bool)
+	for _, todoItem := range todoItems {
+		if todoItem.IssueNumber != nil {
+			referenced[*todoItem.IssueNumber] = true
+		}
+	}
+
+	var candidates []LinkCandidate
+	for i, todoItem := range todoItems {
+		if todoItem.IsChecked || todoItem.IssueNumber != nil || todoItem.ExternalIssue != nil {
+			continue
+		}
+		text := NormalizeForMatch(todoItem.Text)
+		if text == "" {
+			continue
+		}
+
+		best := -1
+		bestDistance := 0
+		for j, ghIssue := range githubIssues {
+			if ghIssue.State != IssueStateOpen || referenced[ghIssue.Number] {
+				continue
+			}
+			title := NormalizeForMatch(ghIssue.Title)
+			distance := EditDistance(text, title)
+			if distance > matchThreshold(max(len([]rune(text)), len([]rune(title)))) {
+				continue
+			}
+			if best < 0 || distance < bestDistance ||
+				(distance == bestDistance && ghIssue.Number < githubIssues[best].Number) {
+				best = j
+				bestDistance = distance
+			}
+		}
+		if best < 0 {
+			continue
+		}
+
+		referenced[githubIssues[best].Number] = true
+		candidates = append(candidates, LinkCandidate{
+			Index:    i,
+			Item:     todoItem,
+			Issue:    githubIssues[best],
+			Distance: bestDistance,
+		})
+	}
+
+	return candidates
+}
+
+// LinkCandidates links the todo items of the given candidates to their issues.
+// Each linked item takes the title of its issue, so the issue is not renamed.
+func LinkCandidates(todoItems []todo.TodoItem, candidates []LinkCandidate) []todo.TodoItem {
+	updatedItems := make([]todo.TodoItem, len(todoItems))
+	copy(updatedItems, todoItems)
+
+	for _, candidate := range candidates {
+		issueNumber := candidate.Issue.Number
+		updatedItems[candidate.Index].IssueNumber = &issueNumber
+		updatedItems[candidate.Index].Text = trimString(candidate.Issue.Title)
+	}
+
+	return updatedItems
+}
+
+// HoldLinkCandidates removes the CreateIssueOp of the todo items of the given candidates,
+// so no duplicate issue is created while a link is unconfirmed
+func HoldLinkCandidates(operations []TodoOperation, candidates []LinkCandidate) []TodoOperation {
+	var kept []TodoOperation
+	for _, todoOp := range operations {
+		if _, ok := todoOp.Operation.(CreateIssueOp); ok && isHeld(todoOp.Todo, candidates) {
+			continue
+		}
+		kept = append(kept, todoOp)
+	}
+	return kept
+}
+
+// WithoutLinkCandidates returns the GitHub issues except those of the given candidates,
+// so an issue whose link is unconfirmed is not added as a new todo item
+func WithoutLinkCandidates(githubIssues []GitHubIssue, candidates []LinkCandidate) []GitHubIssue {
+	var issues []GitHubIssue
+	for _, issue := range githubIssues {
+		held := false
+		for _, candidate := range candidates {
+			if candidate.Issue.Number == issue.Number {
+				held = true
+				break
+			}
+		}
+		if !held {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// isHeld checks if todoItem is the item of one of the candidates
+func isHeld(todoItem todo.TodoItem, candidates []LinkCandidate) bool {
+	if todoItem.IssueNumber != nil {
+		return false
+	}
+	return slices.ContainsFunc(candidates, func(candidate LinkCandidate) bool {
+		return sameTask(todoItem, candidate.Item)
+	})
+}
+
+// sameTask checks if a and b are the same task: by ID if both have one, or by text otherwise
+func sameTask(a, b todo.TodoItem) bool {
+	if a.ID != "" && b.ID != "" {
+		return a.ID == b.ID
+	}
+	return normalizeTitle(a.Text) == normalizeTitle(b.Text)
+}
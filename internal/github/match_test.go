@@ -0,0 +1,154 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+func TestNormalizeForMatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Fix login bug", "fix login bug"},
+		{"Fix login bug.", "fix login bug"},
+		{"  Fix   login\tbug ", "fix login bug"},
+		{"Fix: login-bug!", "fix loginbug"},
+		{"...", ""},
+	}
+
+	for _, tt := range tests {
+		if actual := NormalizeForMatch(tt.input); actual != tt.expected {
+			t.Errorf("NormalizeForMatch(%q) = %q, want %q", tt.input, actual, tt.expected)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"fix login bug", "fix logout bug", 3},
+		{"日本語", "日本", 1},
+	}
+
+	for _, tt := range tests {
+		if actual := EditDistance(tt.a, tt.b); actual != tt.expected {
+			t.Errorf("EditDistance(%q, %q) = %d, want %d", tt.a, tt.b, actual, tt.expected)
+		}
+	}
+}
+
+func TestFindLinkCandidates(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Fix login bug."},
+		{Text: "Fix logout bug"},
+		{Text: "Update the installation documentation"},
+		{Text: "Linked task", IssueNumber: uint64Ptr(1)},
+		{Text: "Done task", IsChecked: true},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "Linked task", State: IssueStateOpen},
+		{Number: 2, Title: "Fix login bug", State: IssueStateOpen},
+		{Number: 3, Title: "Update the instalation documentation", State: IssueStateOpen},
+		{Number: 4, Title: "Done task", State: IssueStateOpen},
+		{Number: 5, Title: "Fix login bug", State: IssueStateClosed},
+	}
+
+	candidates := FindLinkCandidates(todoItems, githubIssues)
+
+	expected := []LinkCandidate{
+		{Index: 0, Item: todoItems[0], Issue: githubIssues[1], Distance: 0},
+		{Index: 2, Item: todoItems[2], Issue: githubIssues[2], Distance: 1},
+	}
+	if !reflect.DeepEqual(candidates, expected) {
+		t.Errorf("expected %+v, got %+v", expected, candidates)
+	}
+}
+
+func TestFindLinkCandidatesPairsEachIssueOnce(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Fix login bug"},
+		{Text: "fix login bug!"},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 7, Title: "Fix login bug.", State: IssueStateOpen},
+		{Number: 3, Title: "Fix login bug", State: IssueStateOpen},
+	}
+
+	candidates := FindLinkCandidates(todoItems, githubIssues)
+
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %d", len(candidates))
+	}
+	if candidates[0].Issue.Number != 3 || candidates[1].Issue.Number != 7 {
+		t.Errorf("expected issues #3 and #7, got #%d and #%d", candidates[0].Issue.Number, candidates[1].Issue.Number)
+	}
+}
+
+func TestLinkCandidates(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Other"},
+		{Text: "Fix login bug."},
+	}
+	candidates := []LinkCandidate{
+		{Index: 1, Item: todoItems[1], Issue: GitHubIssue{Number: 2, Title: "Fix login bug", State: IssueStateOpen}},
+	}
+
+	items := LinkCandidates(todoItems, candidates)
+
+	expected := []todo.TodoItem{
+		{Text: "Other"},
+		{Text: "Fix login bug", IssueNumber: uint64Ptr(2)},
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("expected %+v, got %+v", expected, items)
+	}
+	if todoItems[1].IssueNumber != nil {
+		t.Error("expected input items unchanged")
+	}
+}
+
+func TestHoldLinkCandidates(t *testing.T) {
+	candidates := []LinkCandidate{
+		{Index: 0, Item: todo.TodoItem{Text: "Fix login bug."}, Issue: GitHubIssue{Number: 2, Title: "Fix login bug"}},
+	}
+	operations := []TodoOperation{
+		{Todo: todo.TodoItem{Text: "Fix login bug."}, Operation: CreateIssueOp{Title: "Fix login bug."}},
+		{Todo: todo.TodoItem{Text: "New task"}, Operation: CreateIssueOp{Title: "New task"}},
+		{Todo: todo.TodoItem{Text: "Done", IsChecked: true, IssueNumber: uint64Ptr(1)}, Operation: CloseIssueOp{Number: 1, Reason: IssueStateReasonCompleted}},
+	}
+
+	kept := HoldLinkCandidates(operations, candidates)
+
+	if !reflect.DeepEqual(kept, operations[1:]) {
+		t.Errorf("expected %+v, got %+v", operations[1:], kept)
+	}
+
+	issues := WithoutLinkCandidates([]GitHubIssue{{Number: 1}, {Number: 2}}, candidates)
+	if len(issues) != 1 || issues[0].Number != 1 {
+		t.Errorf("expected only issue #1, got %+v", issues)
+	}
+}
+
+func TestHoldLinkCandidatesByID(t *testing.T) {
+	candidates := []LinkCandidate{
+		{Index: 1, Item: todo.TodoItem{Text: "Fix login bug", ID: "b"}, Issue: GitHubIssue{Number: 2, Title: "Fix login bug."}},
+	}
+	operations := []TodoOperation{
+		{Todo: todo.TodoItem{Text: "Fix login bug", ID: "a"}, Operation: CreateIssueOp{Title: "Fix login bug"}},
+		{Todo: todo.TodoItem{Text: "Fix login bug", ID: "b"}, Operation: CreateIssueOp{Title: "Fix login bug"}},
+	}
+
+	kept := HoldLinkCandidates(operations, candidates)
+
+	if !reflect.DeepEqual(kept, operations[:1]) {
+		t.Errorf("expected %+v, got %+v", operations[:1], kept)
+	}
+}
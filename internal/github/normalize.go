@@ -0,0 +1,21 @@
+package github
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalizeTitle returns the form of a title or section heading used to compare it with
+// another one. The title is converted to Unicode NFC, leading and trailing whitespace is
+// removed, and runs of whitespace such as no-break and ideographic spaces become a single
+// space, so titles that only differ in how they were typed or encoded are equal.
+func normalizeTitle(s string) string {
+	return strings.Join(strings.FieldsFunc(norm.NFC.String(s), unicode.IsSpace), " ")
+}
+
+// trimString removes leading and trailing whitespace, including Unicode spaces
+func trimString(s string) string {
+	return strings.TrimFunc(s, unicode.IsSpace)
+}
@@ -0,0 +1,60 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "ASCII whitespace", input: " \tFix login bug\r\n", expected: "Fix login bug"},
+		{name: "no-break space", input: "\u00a0Fix\u00a0login bug", expected: "Fix login bug"},
+		{name: "ideographic space", input: "\u3000ログイン\u3000不具合を修正\u3000", expected: "ログイン 不具合を修正"},
+		{name: "runs of whitespace", input: "Fix  login \u2003bug", expected: "Fix login bug"},
+		{name: "decomposed characters", input: "Cafe\u0301 \u30cf\u309a\u30b9", expected: "Caf\u00e9 \u30d1\u30b9"},
+		{name: "empty", input: " \u3000 ", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := normalizeTitle(tt.input); actual != tt.expected {
+				t.Errorf("normalizeTitle(%q) = %q, want %q", tt.input, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTrimString(t *testing.T) {
+	if actual := trimString("\u3000Fix\u3000login bug\u00a0"); actual != "Fix\u3000login bug" {
+		t.Errorf("expected inner spaces to be kept, got %q", actual)
+	}
+}
+
+func TestTitleComparisonsUseNormalizedTitles(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "ログイン\u3000不具合を修正", IssueNumber: uint64Ptr(1)},
+		{Text: "Cafe\u0301 menu\u00a0", IssueNumber: uint64Ptr(2)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "ログイン 不具合を修正", State: IssueStateOpen},
+		{Number: 2, Title: "Caf\u00e9 menu", State: IssueStateOpen},
+	}
+
+	if mismatches := FindTitleMismatches(todoItems, githubIssues); len(mismatches) != 0 {
+		t.Errorf("expected no mismatches, got %v", mismatches)
+	}
+
+	titleSync := SynchronizeTitles(todoItems, githubIssues, nil)
+	if len(titleSync.LocallyEditedIssues) != 0 {
+		t.Errorf("expected no locally edited issues, got %v", titleSync.LocallyEditedIssues)
+	}
+
+	if updates := CalculateTitleUpdates(todoItems, githubIssues, nil); len(updates.Operations) != 0 || len(updates.StaleIssues) != 0 {
+		t.Errorf("expected no title updates, got %+v", updates)
+	}
+}
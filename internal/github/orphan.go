@@ -0,0 +1,225 @@
+package github
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+// OrphanKind describes why an issue referenced by a todo item is missing from the fetched issues
+type OrphanKind string
+
+const (
+	// OrphanDeleted means the issue no longer exists
+	OrphanDeleted OrphanKind = "deleted"
+	// OrphanTransferred means the issue was moved to another repository
+	OrphanTransferred OrphanKind = "transferred"
+	// OrphanPullRequest means the number belongs to a pull request
+	OrphanPullRequest OrphanKind = "pull_request"
+)
+
+// OrphanedReference is a todo item reference to an issue missing from the fetched issues.
+// MovedTo is set for transferred issues.
+type OrphanedReference struct {
+	Number  uint64
+	Kind    OrphanKind
+	MovedTo *todo.IssueRef
+}
+
+// IssueLookup is a function type that fetches a single issue by number, following redirects.
+// Returns nil JSON if the issue does not exist.
+type IssueLookup func(issueNumber uint64) (json.RawMessage, error)
+
+// FindOrphanedIssueNumbers returns the issue numbers referenced by todo items
+// that are not among the GitHub issues, in order of first reference.
+func FindOrphanedIssueNumbers(todoItems []todo.TodoItem, githubIssues []GitHubIssue) []uint64 {
+	githubIssuesMap := make(map[uint64]GitHubIssue)
+	for _, issue := range githubIssues {
+		githubIssuesMap[issue.Number] = issue
+	}
+
+	var orphans []uint64
+	for _, todoItem := range todoItems {
+		if todoItem.IssueNumber == nil {
+			continue
+		}
+		if _, exists := githubIssuesMap[*todoItem.IssueNumber]; exists {
+			continue
+		}
+		if !slices.Contains(orphans, *todoItem.IssueNumber) {
+			orphans = append(orphans, *todoItem.IssueNumber)
+		}
+	}
+
+	return orphans
+}
+
+// ClassifyOrphanedReference determines what happened to a missing issue from the
+// result of fetching it directly. issueJSON is nil if the issue no longer exists.
+// Returns false if the issue is a regular issue of repo after all.
+func ClassifyOrphanedReference(number uint64, repo string, issueJSON json.RawMessage) (OrphanedReference, bool) {
+	if issueJSON == nil {
+		return OrphanedReference{Number: number, Kind: OrphanDeleted}, true
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(issueJSON, &raw); err != nil {
+		return OrphanedReference{Number: number, Kind: OrphanDeleted}, true
+	}
+
+	if pullRequest, exists := raw["pull_request"]; exists && pullRequest != nil {
+		return OrphanedReference{Number: number, Kind: OrphanPullRequest}, true
+	}
+
+	newNumber, numberOk := raw["number"].(float64)
+	repositoryURL, urlOk := raw["repository_url"].(string)
+	if !numberOk || newNumber != float64(uint64(newNumber)) || !urlOk {
+		return OrphanedReference{Number: number, Kind: OrphanDeleted}, true
+	}
+
+	newRepo := repositoryFromURL(repositoryURL)
+	if newRepo == "" {
+		return OrphanedReference{Number: number, Kind: OrphanDeleted}, true
+	}
+	if strings.EqualFold(newRepo, repo) && uint64(newNumber) == number {
+		return OrphanedReference{}, false
+	}
+
+	return OrphanedReference{
+		Number:  number,
+		Kind:    OrphanTransferred,
+		MovedTo: &todo.IssueRef{Repo: newRepo, Number: uint64(newNumber)},
+	}, true
+}
+
+// CollectOrphanedReferences looks up every issue referenced by todo items that is
+// missing from the GitHub issues and classifies it.
+func CollectOrphanedReferences(todoItems []todo.TodoItem, githubIssues []GitHubIssue, repo string, lookup IssueLookup) ([]OrphanedReference, error) {
+	var orphans []OrphanedReference
+	for _, issueNumber := range FindOrphanedIssueNumbers(todoItems, githubIssues) {
+		issueJSON, err := lookup(issueNumber)
+		if err != nil {
+			return nil, err
+		}
+		if orphan, ok := ClassifyOrphanedReference(issueNumber, repo, issueJSON); ok {
+			orphans = append(orphans, orphan)
+		}
+	}
+	return orphans, nil
+}
+
+// RelinkTransferredIssues updates references to transferred issues to point at
+// the repository the issue was moved to.
+func RelinkTransferredIssues(todoItems []todo.TodoItem, orphans []OrphanedReference) []todo.TodoItem {
+	movedTo := make(map[uint64]todo.IssueRef)
+	for _, orphan := range orphans {
+		if orphan.Kind == OrphanTransferred && orphan.MovedTo != nil {
+			movedTo[orphan.Number] = *orphan.MovedTo
+		}
+	}
+
+	updatedItems := make([]todo.TodoItem, 0, len(todoItems))
+	for _, todoItem := range todoItems {
+		updated := todoItem
+		if todoItem.IssueNumber != nil {
+			if ref, ok := movedTo[*todoItem.IssueNumber]; ok {
+				updated.IssueNumber = nil
+				updated.ExternalIssue = &ref
+			}
+		}
+		updatedItems = append(updatedItems, updated)
+	}
+
+	return updatedItems
+}
+
+// UnlinkOrphanedReferences removes references to deleted issues and pull requests,
+// keeping the items themselves.
+func UnlinkOrphanedReferences(todoItems []todo.TodoItem, orphans []OrphanedReference) []todo.TodoItem {
+	gone := goneIssueNumbers(orphans)
+
+	updatedItems := make([]todo.TodoItem, 0, len(todoItems))
+	for _, todoItem := range todoItems {
+		updated := todoItem
+		if todoItem.IssueNumber != nil && gone[*todoItem.IssueNumber] {
+			updated.IssueNumber = nil
+		}
+		updatedItems = append(updatedItems, updated)
+	}
+
+	return updatedItems
+}
+
+// UnlinkedItems returns the items whose references UnlinkOrphanedReferences removes, as
+// they are once unlinked.
+func UnlinkedItems(todoItems []todo.TodoItem, orphans []OrphanedReference) []todo.TodoItem {
+	gone := goneIssueNumbers(orphans)
+
+	var unlinked []todo.TodoItem
+	for _, todoItem := range todoItems {
+		if todoItem.IssueNumber != nil && gone[*todoItem.IssueNumber] {
+			updated := todoItem
+			updated.IssueNumber = nil
+			unlinked = append(unlinked, updated)
+		}
+	}
+
+	return unlinked
+}
+
+// HoldUnlinkedItems removes the CreateIssueOp of the given unlinked items, so the run
+// that unlinks a task doesn't create an issue in place of the one that is gone
+func HoldUnlinkedItems(operations []TodoOperation, unlinked []todo.TodoItem) []TodoOperation {
+	var kept []TodoOperation
+	for _, todoOp := range operations {
+		if _, ok := todoOp.Operation.(CreateIssueOp); ok && slices.ContainsFunc(unlinked, func(item todo.TodoItem) bool {
+			return sameTask(todoOp.Todo, item)
+		}) {
+			continue
+		}
+		kept = append(kept, todoOp)
+	}
+	return kept
+}
+
+// RemoveOrphanedReferences removes items referencing deleted issues and pull requests.
+func RemoveOrphanedReferences(todoItems []todo.TodoItem, orphans []OrphanedReference) []todo.TodoItem {
+	gone := goneIssueNumbers(orphans)
+
+	updatedItems := make([]todo.TodoItem, 0, len(todoItems))
+	for _, todoItem := range todoItems {
+		if todoItem.IssueNumber != nil && gone[*todoItem.IssueNumber] {
+			continue
+		}
+		updatedItems = append(updatedItems, todoItem)
+	}
+
+	return updatedItems
+}
+
+// goneIssueNumbers returns the numbers of orphans that can't be followed
+func goneIssueNumbers(orphans []OrphanedReference) map[uint64]bool {
+	gone := make(map[uint64]bool)
+	for _, orphan := range orphans {
+		if orphan.Kind != OrphanTransferred {
+			gone[orphan.Number] = true
+		}
+	}
+	return gone
+}
+
+// repositoryFromURL extracts "owner/repo" from an API URL like
+// "https://api.github.com/repos/owner/repo"
+func repositoryFromURL(url string) string {
+	_, path, found := strings.Cut(url, "/repos/")
+	if !found {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
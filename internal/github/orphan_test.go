@@ -0,0 +1,213 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+func TestFindOrphanedIssueNumbers(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Known", IssueNumber: uint64Ptr(1)},
+		{Text: "Missing", IssueNumber: uint64Ptr(2)},
+		{Text: "No issue"},
+		{Text: "Missing again", IssueNumber: uint64Ptr(2)},
+		{Text: "External", ExternalIssue: &todo.IssueRef{Repo: "other/repo", Number: 3}},
+		{Text: "Also missing", IssueNumber: uint64Ptr(4)},
+	}
+	githubIssues := []GitHubIssue{{Number: 1, Title: "Known", State: IssueStateOpen}}
+
+	orphans := FindOrphanedIssueNumbers(todoItems, githubIssues)
+
+	if len(orphans) != 2 || orphans[0] != 2 || orphans[1] != 4 {
+		t.Errorf("expected [2 4], got %v", orphans)
+	}
+}
+
+func TestClassifyOrphanedReference(t *testing.T) {
+	tests := []struct {
+		name      string
+		issueJSON json.RawMessage
+		expected  OrphanedReference
+		ok        bool
+	}{
+		{
+			name:     "deleted",
+			expected: OrphanedReference{Number: 5, Kind: OrphanDeleted},
+			ok:       true,
+		},
+		{
+			name:      "pull request",
+			issueJSON: json.RawMessage(`{"number": 5, "repository_url": "https://api.github.com/repos/owner/repo", "pull_request": {"url": "https://api.github.com/repos/owner/repo/pulls/5"}}`),
+			expected:  OrphanedReference{Number: 5, Kind: OrphanPullRequest},
+			ok:        true,
+		},
+		{
+			name:      "transferred",
+			issueJSON: json.RawMessage(`{"number": 12, "repository_url": "https://api.github.com/repos/other/project", "pull_request": null}`),
+			expected:  OrphanedReference{Number: 5, Kind: OrphanTransferred, MovedTo: &todo.IssueRef{Repo: "other/project", Number: 12}},
+			ok:        true,
+		},
+		{
+			name:      "same repository",
+			issueJSON: json.RawMessage(`{"number": 5, "repository_url": "https://api.github.com/repos/Owner/Repo"}`),
+			ok:        false,
+		},
+		{
+			name:      "unexpected response",
+			issueJSON: json.RawMessage(`{"message": "Not Found"}`),
+			expected:  OrphanedReference{Number: 5, Kind: OrphanDeleted},
+			ok:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orphan, ok := ClassifyOrphanedReference(5, "owner/repo", tt.issueJSON)
+			if ok != tt.ok {
+				t.Fatalf("expected ok %v, got %v", tt.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if orphan.Number != tt.expected.Number || orphan.Kind != tt.expected.Kind {
+				t.Errorf("expected %+v, got %+v", tt.expected, orphan)
+			}
+			if (orphan.MovedTo == nil) != (tt.expected.MovedTo == nil) {
+				t.Fatalf("expected MovedTo %v, got %v", tt.expected.MovedTo, orphan.MovedTo)
+			}
+			if orphan.MovedTo != nil && *orphan.MovedTo != *tt.expected.MovedTo {
+				t.Errorf("expected MovedTo %+v, got %+v", *tt.expected.MovedTo, *orphan.MovedTo)
+			}
+		})
+	}
+}
+
+func TestCollectOrphanedReferences(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Known", IssueNumber: uint64Ptr(1)},
+		{Text: "Deleted", IssueNumber: uint64Ptr(2)},
+		{Text: "Moved", IssueNumber: uint64Ptr(3)},
+	}
+	githubIssues := []GitHubIssue{{Number: 1, Title: "Known", State: IssueStateOpen}}
+
+	var lookedUp []uint64
+	lookup := func(issueNumber uint64) (json.RawMessage, error) {
+		lookedUp = append(lookedUp, issueNumber)
+		if issueNumber == 3 {
+			return json.RawMessage(`{"number": 7, "repository_url": "https://api.github.com/repos/other/repo"}`), nil
+		}
+		return nil, nil
+	}
+
+	orphans, err := CollectOrphanedReferences(todoItems, githubIssues, "owner/repo", lookup)
+	if err != nil {
+		t.Fatalf("CollectOrphanedReferences failed: %v", err)
+	}
+
+	if len(lookedUp) != 2 {
+		t.Errorf("expected 2 lookups, got %v", lookedUp)
+	}
+	if len(orphans) != 2 || orphans[0].Kind != OrphanDeleted || orphans[1].Kind != OrphanTransferred {
+		t.Errorf("unexpected orphans: %+v", orphans)
+	}
+}
+
+func TestCollectOrphanedReferencesPropagatesLookupError(t *testing.T) {
+	todoItems := []todo.TodoItem{{Text: "Missing", IssueNumber: uint64Ptr(2)}}
+	lookup := func(issueNumber uint64) (json.RawMessage, error) {
+		return nil, errors.New("network error")
+	}
+
+	if _, err := CollectOrphanedReferences(todoItems, nil, "owner/repo", lookup); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func testOrphans() []OrphanedReference {
+	return []OrphanedReference{
+		{Number: 2, Kind: OrphanDeleted},
+		{Number: 3, Kind: OrphanTransferred, MovedTo: &todo.IssueRef{Repo: "other/repo", Number: 7}},
+		{Number: 4, Kind: OrphanPullRequest},
+	}
+}
+
+func testOrphanItems() []todo.TodoItem {
+	return []todo.TodoItem{
+		{Text: "Known", IssueNumber: uint64Ptr(1)},
+		{Text: "Deleted", IssueNumber: uint64Ptr(2)},
+		{Text: "Moved", IsChecked: true, IssueNumber: uint64Ptr(3)},
+		{Text: "Pull request", IssueNumber: uint64Ptr(4)},
+	}
+}
+
+func TestRelinkTransferredIssues(t *testing.T) {
+	updated := RelinkTransferredIssues(testOrphanItems(), testOrphans())
+
+	if len(updated) != 4 {
+		t.Fatalf("expected 4 items, got %d", len(updated))
+	}
+	moved := updated[2]
+	if moved.IssueNumber != nil {
+		t.Errorf("expected IssueNumber nil, got %d", *moved.IssueNumber)
+	}
+	if moved.ExternalIssue == nil || *moved.ExternalIssue != (todo.IssueRef{Repo: "other/repo", Number: 7}) {
+		t.Errorf("expected external issue other/repo#7, got %+v", moved.ExternalIssue)
+	}
+	if !moved.IsChecked || moved.Text != "Moved" {
+		t.Errorf("expected other fields to be kept, got %+v", moved)
+	}
+	if updated[1].IssueNumber == nil || *updated[1].IssueNumber != 2 {
+		t.Errorf("expected deleted reference to be kept, got %+v", updated[1])
+	}
+}
+
+func TestUnlinkOrphanedReferences(t *testing.T) {
+	updated := UnlinkOrphanedReferences(testOrphanItems(), testOrphans())
+
+	if len(updated) != 4 {
+		t.Fatalf("expected 4 items, got %d", len(updated))
+	}
+	if updated[0].IssueNumber == nil || *updated[0].IssueNumber != 1 {
+		t.Errorf("expected known reference to be kept, got %+v", updated[0])
+	}
+	if updated[1].IssueNumber != nil || updated[3].IssueNumber != nil {
+		t.Errorf("expected deleted and pull request references to be unlinked, got %+v", updated)
+	}
+	if updated[2].IssueNumber == nil {
+		t.Error("expected transferred reference to be left for relinking")
+	}
+}
+
+func TestHoldUnlinkedItemsCreatesNoIssueForUnlinkedTasks(t *testing.T) {
+	todoItems := append(testOrphanItems(), todo.TodoItem{Text: "New task"})
+	githubIssues := []GitHubIssue{{Number: 1, Title: "Known", State: IssueStateOpen}}
+
+	unlinked := UnlinkedItems(todoItems, testOrphans())
+	if len(unlinked) != 2 || unlinked[0].Text != "Deleted" || unlinked[1].Text != "Pull request" || unlinked[0].IssueNumber != nil {
+		t.Fatalf("expected Deleted and Pull request to be unlinked, got %+v", unlinked)
+	}
+
+	updated := UnlinkOrphanedReferences(todoItems, testOrphans())
+	operations := HoldUnlinkedItems(CalculateGitHubOperations(updated, githubIssues), unlinked)
+
+	var ops []GitHubOperation
+	for _, todoOp := range operations {
+		ops = append(ops, todoOp.Operation)
+	}
+	expected := []GitHubOperation{CreateIssueOp{Title: "New task"}}
+	if !reflect.DeepEqual(ops, expected) {
+		t.Errorf("expected %+v, got %+v", expected, ops)
+	}
+}
+
+func TestRemoveOrphanedReferences(t *testing.T) {
+	updated := RemoveOrphanedReferences(testOrphanItems(), testOrphans())
+
+	if len(updated) != 2 || updated[0].Text != "Known" || updated[1].Text != "Moved" {
+		t.Errorf("expected Known and Moved to remain, got %+v", updated)
+	}
+}
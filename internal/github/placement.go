@@ -0,0 +1,199 @@
+package github
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+// InboxSection is the title of the section that todo items of pulled issues are added to
+// with PlaceInInbox
+const InboxSection = "Inbox"
+
+// defaultSectionLevel is the heading level of a section added to a file without headings
+const defaultSectionLevel = 2
+
+// PlacementSection is how the section of a todo item of a pulled issue is chosen
+type PlacementSection string
+
+const (
+	// PlaceAtEnd adds items at the end of the list
+	PlaceAtEnd PlacementSection = ""
+	// PlaceInInbox adds items to the Inbox section, which is added after the items
+	// without a section if the file has none
+	PlaceInInbox PlacementSection = "inbox"
+	// PlaceByLabel adds items to the first section titled as one of their issue's labels
+	PlaceByLabel PlacementSection = "label"
+	// PlaceByMilestone adds items to the section titled as their issue's milestone
+	PlaceByMilestone PlacementSection = "milestone"
+)
+
+// PlacementOrder is the order of the todo items of pulled issues
+type PlacementOrder string
+
+const (
+	// OrderAsListed keeps the order the issues are listed in
+	OrderAsListed PlacementOrder = ""
+	// OrderByNumber orders items by issue number
+	OrderByNumber PlacementOrder = "number"
+	// OrderByCreated orders items by the date their issues were created
+	OrderByCreated PlacementOrder = "created"
+	// OrderByPriority orders items by the first of their issue's labels in PriorityLabels
+	OrderByPriority PlacementOrder = "priority"
+)
+
+// Placement sets where the todo items of pulled issues are added
+type Placement struct {
+	Section PlacementSection
+	Order   PlacementOrder
+	// PriorityLabels are the labels giving the priority of issues, the highest first
+	PriorityLabels []string
+}
+
+// PlaceNewItems moves the todo items of pulled issues, the items referencing an issue
+// none of existing references, to their place in items as set by placement.
+//
+// sections are the sections of the file, including those without items. An item whose
+// issue matches no section with PlaceByLabel or PlaceByMilestone is added at the end.
+// Items keep their relative order within a section unless placement sets an order, and
+// are added after the last item of their section.
+func PlaceNewItems(existing, items []todo.TodoItem, sections []todo.Section, githubIssues []GitHubIssue, placement Placement) []todo.TodoItem {
+	if placement.Section == PlaceAtEnd && placement.Order == OrderAsListed {
+		return items
+	}
+
+	referenced := make(map[uint64]bool)
+	for _, item := range existing {
+		if item.IssueNumber != nil {
+			referenced[*item.IssueNumber] = true
+		}
+	}
+	githubIssuesMap := make(map[uint64]GitHubIssue)
+	for _, issue := range githubIssues {
+		githubIssuesMap[issue.Number] = issue
+	}
+
+	var placed, added []todo.TodoItem
+	for _, item := range items {
+		if item.IssueNumber != nil && !referenced[*item.IssueNumber] {
+			added = append(added, item)
+		} else {
+			placed = append(placed, item)
+		}
+	}
+	if len(added) == 0 {
+		return items
+	}
+
+	sortNewItems(added, githubIssuesMap, placement)
+
+	// Sections of the file, with those of the items in order after them
+	sections = slices.Clone(sections)
+	for _, item := range placed {
+		if item.Section.Title != "" && !slices.Contains(sections, item.Section) {
+			sections = append(sections, item.Section)
+		}
+	}
+
+	for _, item := range added {
+		issue := githubIssuesMap[*item.IssueNumber]
+		switch placement.Section {
+		case PlaceInInbox:
+			section, ok := findSection(sections, InboxSection)
+			if !ok {
+				section = todo.Section{Title: InboxSection, Level: defaultSectionLevel}
+				if len(sections) > 0 {
+					section.Level = sections[0].Level
+				}
+				sections = append(sections, section)
+				// A new Inbox goes before the first section, after the items without one
+				index := slices.IndexFunc(placed, func(item todo.TodoItem) bool { return item.Section.Title != "" })
+				if index >= 0 {
+					item.Section = section
+					placed = slices.Insert(placed, index, item)
+					continue
+				}
+			}
+			item.Section = section
+		case PlaceByLabel:
+			for _, label := range issue.Labels {
+				if section, ok := findSection(sections, label); ok {
+					item.Section = section
+					break
+				}
+			}
+		case PlaceByMilestone:
+			if issue.Milestone != nil {
+				if section, ok := findSection(sections, issue.Milestone.Title); ok {
+					item.Section = section
+				}
+			}
+		}
+
+		if item.Section.Title == "" {
+			placed = append(placed, item)
+		} else {
+			placed = todo.InsertInSection(placed, item)
+		}
+	}
+
+	return placed
+}
+
+// sortNewItems sorts the todo items of pulled issues in the order set by placement,
+// then by issue number
+func sortNewItems(items []todo.TodoItem, githubIssuesMap map[uint64]GitHubIssue, placement Placement) {
+	if placement.Order == OrderAsListed {
+		return
+	}
+
+	priority := func(issue GitHubIssue) int {
+		for i, label := range placement.PriorityLabels {
+			if slices.ContainsFunc(issue.Labels, func(l string) bool { return sameTitle(l, label) }) {
+				return i
+			}
+		}
+		return len(placement.PriorityLabels)
+	}
+
+	slices.SortStableFunc(items, func(a, b todo.TodoItem) int {
+		issueA, issueB := githubIssuesMap[*a.IssueNumber], githubIssuesMap[*b.IssueNumber]
+		var c int
+		switch placement.Order {
+		case OrderByCreated:
+			// Issues without a creation date go last
+			switch {
+			case issueA.CreatedAt == nil && issueB.CreatedAt == nil:
+			case issueA.CreatedAt == nil:
+				c = 1
+			case issueB.CreatedAt == nil:
+				c = -1
+			default:
+				c = issueA.CreatedAt.Compare(*issueB.CreatedAt)
+			}
+		case OrderByPriority:
+			c = cmp.Compare(priority(issueA), priority(issueB))
+		}
+		if c != 0 {
+			return c
+		}
+		return cmp.Compare(*a.IssueNumber, *b.IssueNumber)
+	})
+}
+
+// findSection returns the first of sections titled as title, ignoring case and whitespace
+func findSection(sections []todo.Section, title string) (todo.Section, bool) {
+	for _, section := range sections {
+		if sameTitle(section.Title, title) {
+			return section, true
+		}
+	}
+	return todo.Section{}, false
+}
+
+// sameTitle checks if two titles are equal, ignoring case and whitespace
+func sameTitle(a, b string) bool {
+	return strings.EqualFold(normalizeTitle(a), normalizeTitle(b))
+}
@@ -0,0 +1,142 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+var (
+	todoSection = todo.Section{Title: "Todo", Level: 2}
+	bugSection  = todo.Section{Title: "Bugs", Level: 2}
+)
+
+func placementItems() (existing, items []todo.TodoItem) {
+	existing = []todo.TodoItem{
+		{Text: "Top task"},
+		{Text: "Todo task", IssueNumber: uint64Ptr(1), Section: todoSection},
+		{Text: "Bug task", IssueNumber: uint64Ptr(2), Section: bugSection},
+	}
+	items = append(existing,
+		todo.TodoItem{Text: "Pulled bug", IssueNumber: uint64Ptr(4)},
+		todo.TodoItem{Text: "Pulled task", IssueNumber: uint64Ptr(3)},
+	)
+	return existing, items
+}
+
+func placementIssues() []GitHubIssue {
+	return []GitHubIssue{
+		{Number: 4, Title: "Pulled bug", State: IssueStateOpen, Labels: []string{"P1", "bugs"}, CreatedAt: date(2026, 10, 1)},
+		{Number: 3, Title: "Pulled task", State: IssueStateOpen, Labels: []string{"P0"}, Milestone: &Milestone{Number: 1, Title: "Todo"}, CreatedAt: date(2026, 10, 2)},
+	}
+}
+
+func date(year int, month time.Month, day int) *time.Time {
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &d
+}
+
+func texts(items []todo.TodoItem) []string {
+	var texts []string
+	for _, item := range items {
+		texts = append(texts, item.Text)
+	}
+	return texts
+}
+
+func TestPlaceNewItemsAtEndKeepsItems(t *testing.T) {
+	existing, items := placementItems()
+
+	placed := PlaceNewItems(existing, items, nil, placementIssues(), Placement{})
+
+	if !reflect.DeepEqual(placed, items) {
+		t.Errorf("expected %+v, got %+v", items, placed)
+	}
+}
+
+func TestPlaceNewItemsOrdered(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    PlacementOrder
+		expected []string
+	}{
+		{"number", OrderByNumber, []string{"Pulled task", "Pulled bug"}},
+		{"created", OrderByCreated, []string{"Pulled bug", "Pulled task"}},
+		{"priority", OrderByPriority, []string{"Pulled task", "Pulled bug"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing, items := placementItems()
+
+			placed := PlaceNewItems(existing, items, nil, placementIssues(), Placement{Order: tt.order, PriorityLabels: []string{"p0", "P1"}})
+
+			if actual := texts(placed[3:]); !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPlaceNewItemsInInbox(t *testing.T) {
+	existing, items := placementItems()
+
+	placed := PlaceNewItems(existing, items, []todo.Section{todoSection, bugSection}, placementIssues(), Placement{Section: PlaceInInbox, Order: OrderByNumber})
+
+	expected := []string{"Top task", "Pulled task", "Pulled bug", "Todo task", "Bug task"}
+	if actual := texts(placed); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	inbox := todo.Section{Title: InboxSection, Level: 2}
+	if placed[1].Section != inbox || placed[2].Section != inbox {
+		t.Errorf("expected pulled items in %+v, got %+v and %+v", inbox, placed[1].Section, placed[2].Section)
+	}
+}
+
+func TestPlaceNewItemsInExistingInbox(t *testing.T) {
+	existing, items := placementItems()
+	inbox := todo.Section{Title: "inbox", Level: 3}
+
+	placed := PlaceNewItems(existing, items, []todo.Section{inbox, todoSection, bugSection}, placementIssues(), Placement{Section: PlaceInInbox})
+
+	expected := []string{"Top task", "Todo task", "Bug task", "Pulled bug", "Pulled task"}
+	if actual := texts(placed); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if placed[3].Section != inbox || placed[4].Section != inbox {
+		t.Errorf("expected pulled items in %+v, got %+v and %+v", inbox, placed[3].Section, placed[4].Section)
+	}
+}
+
+func TestPlaceNewItemsByLabel(t *testing.T) {
+	existing, items := placementItems()
+
+	placed := PlaceNewItems(existing, items, nil, placementIssues(), Placement{Section: PlaceByLabel})
+
+	expected := []string{"Top task", "Todo task", "Bug task", "Pulled bug", "Pulled task"}
+	if actual := texts(placed); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if placed[3].Section != bugSection {
+		t.Errorf("expected the labelled item in %+v, got %+v", bugSection, placed[3].Section)
+	}
+	if placed[4].Section != (todo.Section{}) {
+		t.Errorf("expected the item without a matching label at the end, got %+v", placed[4].Section)
+	}
+}
+
+func TestPlaceNewItemsByMilestone(t *testing.T) {
+	existing, items := placementItems()
+
+	placed := PlaceNewItems(existing, items, nil, placementIssues(), Placement{Section: PlaceByMilestone})
+
+	expected := []string{"Top task", "Todo task", "Pulled task", "Bug task", "Pulled bug"}
+	if actual := texts(placed); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if placed[2].Section != todoSection {
+		t.Errorf("expected the item in %+v, got %+v", todoSection, placed[2].Section)
+	}
+}
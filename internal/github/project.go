@@ -0,0 +1,410 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+// ProjectStatusField is the name of the single-select field that TODO.md sections map to
+const ProjectStatusField = "Status"
+
+// ProjectDueDateField is the name of the date field that due dates of tasks are synced to
+const ProjectDueDateField = "Due"
+
+// ProjectBoard represents a GitHub Projects v2 board, its Status field and its Due field
+type ProjectBoard struct {
+	ID            string
+	StatusFieldID string
+	StatusOptions []ProjectStatusOption
+	// DueDateFieldID is empty if the board has no date field named Due
+	DueDateFieldID string
+	Items          []ProjectItem
+}
+
+// ProjectStatusOption represents an option of the Status field
+type ProjectStatusOption struct {
+	ID   string
+	Name string
+}
+
+// ProjectItem represents an issue placed on a project board, in board order
+type ProjectItem struct {
+	ID          string
+	Repository  string
+	IssueNumber uint64
+	Status      string
+	DueDate     *time.Time
+}
+
+// ProjectFetcher is a function type that fetches a page of a project board from GitHub GraphQL API
+// Parameters: cursor (empty for the first page)
+// Returns: GraphQL response body and error
+type ProjectFetcher func(cursor string) ([]byte, error)
+
+// projectResponse is the shape of the GraphQL response for a project board page
+type projectResponse struct {
+	Data struct {
+		RepositoryOwner *struct {
+			ProjectV2 *struct {
+				ID    string `json:"id"`
+				Field *struct {
+					ID      string `json:"id"`
+					Options []struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"options"`
+				} `json:"field"`
+				DueDateField *struct {
+					ID       string `json:"id"`
+					DataType string `json:"dataType"`
+				} `json:"dueDateField"`
+				Items struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []struct {
+						ID               string `json:"id"`
+						FieldValueByName *struct {
+							Name string `json:"name"`
+						} `json:"fieldValueByName"`
+						DueDate *struct {
+							Date string `json:"date"`
+						} `json:"dueDate"`
+						Content *struct {
+							Number     uint64 `json:"number"`
+							Repository *struct {
+								NameWithOwner string `json:"nameWithOwner"`
+							} `json:"repository"`
+						} `json:"content"`
+					} `json:"nodes"`
+				} `json:"items"`
+			} `json:"projectV2"`
+		} `json:"repositoryOwner"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// ParseProjectBoard parses a GraphQL response page of a project board.
+// Returns the board, the cursor of the next page (empty if this is the last page) and an error.
+// Items that are not issues (draft issues and pull requests) are skipped.
+func ParseProjectBoard(data []byte) (ProjectBoard, string, error) {
+	var response projectResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return ProjectBoard{}, "", fmt.Errorf("failed to parse project response: %w", err)
+	}
+	if len(response.Errors) > 0 {
+		return ProjectBoard{}, "", fmt.Errorf("failed to fetch project: %s", response.Errors[0].Message)
+	}
+	if response.Data.RepositoryOwner == nil || response.Data.RepositoryOwner.ProjectV2 == nil {
+		return ProjectBoard{}, "", fmt.Errorf("project not found")
+	}
+
+	project := response.Data.RepositoryOwner.ProjectV2
+	if project.Field == nil {
+		return ProjectBoard{}, "", fmt.Errorf("project has no single-select %s field", ProjectStatusField)
+	}
+
+	board := ProjectBoard{
+		ID:            project.ID,
+		StatusFieldID: project.Field.ID,
+	}
+	for _, option := range project.Field.Options {
+		board.StatusOptions = append(board.StatusOptions, ProjectStatusOption{ID: option.ID, Name: option.Name})
+	}
+	if project.DueDateField != nil && project.DueDateField.DataType == "DATE" {
+		board.DueDateFieldID = project.DueDateField.ID
+	}
+
+	for _, node := range project.Items.Nodes {
+		if node.Content == nil || node.Content.Repository == nil || node.Content.Number == 0 {
+			continue
+		}
+		item := ProjectItem{
+			ID:          node.ID,
+			Repository:  node.Content.Repository.NameWithOwner,
+			IssueNumber: node.Content.Number,
+		}
+		if node.FieldValueByName != nil {
+			item.Status = node.FieldValueByName.Name
+		}
+		if node.DueDate != nil {
+			if dueDate, err := time.Parse(todo.DueDateLayout, node.DueDate.Date); err == nil {
+				item.DueDate = &dueDate
+			}
+		}
+		board.Items = append(board.Items, item)
+	}
+
+	nextCursor := ""
+	if project.Items.PageInfo.HasNextPage {
+		nextCursor = project.Items.PageInfo.EndCursor
+	}
+
+	return board, nextCursor, nil
+}
+
+// FetchProjectBoard fetches a project board with all its items using pagination
+func FetchProjectBoard(fetcher ProjectFetcher) (ProjectBoard, error) {
+	const maxPages = 1000
+	var board ProjectBoard
+	cursor := ""
+
+	for page := 1; ; page++ {
+		if page > maxPages {
+			return ProjectBoard{}, fmt.Errorf("exceeded maximum page limit")
+		}
+
+		data, err := fetcher(cursor)
+		if err != nil {
+			return ProjectBoard{}, fmt.Errorf("failed to fetch project: %w", err)
+		}
+
+		pageBoard, nextCursor, err := ParseProjectBoard(data)
+		if err != nil {
+			return ProjectBoard{}, err
+		}
+
+		if page == 1 {
+			board = pageBoard
+		} else {
+			board.Items = append(board.Items, pageBoard.Items...)
+		}
+
+		if nextCursor == "" {
+			return board, nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// FindStatusOption returns the Status option whose name matches name, ignoring case
+// and surrounding whitespace.
+func FindStatusOption(board ProjectBoard, name string) (ProjectStatusOption, bool) {
+	for _, option := range board.StatusOptions {
+		if strings.EqualFold(normalizeTitle(option.Name), normalizeTitle(name)) {
+			return option, true
+		}
+	}
+	return ProjectStatusOption{}, false
+}
+
+// FindProjectItem returns the board item for an issue of the given repository.
+func FindProjectItem(board ProjectBoard, repo string, issueNumber uint64) (ProjectItem, bool) {
+	for _, item := range board.Items {
+		if item.IssueNumber == issueNumber && strings.EqualFold(item.Repository, repo) {
+			return item, true
+		}
+	}
+	return ProjectItem{}, false
+}
+
+// CalculateProjectOperations determines board operations for todo items with issue numbers.
+// Items of createdIssues, the issues created in this run, are added to the board unless
+// they are checked without a matching Status option. Other issues that are not on the board
+// are left off it. Items whose section matches a Status option get that status if the board
+// shows a different one.
+func CalculateProjectOperations(todoItems []todo.TodoItem, board ProjectBoard, repo string, createdIssues []GitHubIssue) []TodoOperation {
+	var operations []TodoOperation
+
+	for _, todoItem := range todoItems {
+		if todoItem.IssueNumber == nil {
+			continue
+		}
+		issueNumber := *todoItem.IssueNumber
+
+		boardItem, onBoard := FindProjectItem(board, repo, issueNumber)
+		option, hasStatus := FindStatusOption(board, todoItem.Section.Title)
+
+		if !onBoard {
+			created := slices.ContainsFunc(createdIssues, func(issue GitHubIssue) bool { return issue.Number == issueNumber })
+			if !created || (todoItem.IsChecked && !hasStatus) {
+				continue
+			}
+			operations = append(operations, TodoOperation{
+				Todo:      todoItem,
+				Operation: AddProjectItemOp{Number: issueNumber},
+			})
+		}
+
+		if hasStatus && (!onBoard || boardItem.Status != option.Name) {
+			operations = append(operations, TodoOperation{
+				Todo: todoItem,
+				Operation: SetProjectStatusOp{
+					Number:   issueNumber,
+					OptionID: option.ID,
+					Status:   option.Name,
+				},
+			})
+		}
+	}
+
+	return operations
+}
+
+// CalculateProjectDueDateOperations determines updates of the Due field for todo items
+// with a due date whose issue is not on the board or has a different Due date there.
+// Issues that are still not on the board when the operations are made are skipped.
+func CalculateProjectDueDateOperations(todoItems []todo.TodoItem, board ProjectBoard, repo string) []TodoOperation {
+	var operations []TodoOperation
+
+	for _, todoItem := range todoItems {
+		if todoItem.IssueNumber == nil || todoItem.DueDate == nil {
+			continue
+		}
+		boardItem, onBoard := FindProjectItem(board, repo, *todoItem.IssueNumber)
+		if onBoard && boardItem.DueDate != nil && sameDate(*boardItem.DueDate, *todoItem.DueDate) {
+			continue
+		}
+
+		operations = append(operations, TodoOperation{
+			Todo: todoItem,
+			Operation: SetProjectDueDateOp{
+				Number:  *todoItem.IssueNumber,
+				DueDate: dateOf(*todoItem.DueDate),
+			},
+		})
+	}
+
+	return operations
+}
+
+// SynchronizeProjectDueDates updates the due date of todo items from the Due field of
+// their issue on the board. Items whose issue has no Due date on the board are kept as-is.
+func SynchronizeProjectDueDates(todoItems []todo.TodoItem, board ProjectBoard, repo string) []todo.TodoItem {
+	updatedItems := make([]todo.TodoItem, 0, len(todoItems))
+	for _, todoItem := range todoItems {
+		updated := todoItem
+
+		if todoItem.IssueNumber != nil {
+			if boardItem, onBoard := FindProjectItem(board, repo, *todoItem.IssueNumber); onBoard && boardItem.DueDate != nil {
+				if todoItem.DueDate == nil || !sameDate(*todoItem.DueDate, *boardItem.DueDate) {
+					dueDate := dateOf(*boardItem.DueDate)
+					updated.DueDate = &dueDate
+				}
+			}
+		}
+
+		updatedItems = append(updatedItems, updated)
+	}
+
+	return updatedItems
+}
+
+// ArrangeByBoard moves todo items into the sections matching their status on the board
+// and orders them like the board.
+//
+// Sections keep the order in which they first appear, except that sections matching
+// Status options follow the option order. Within a Status section, items on the board
+// come first in board order, followed by the other items in their original order.
+// Items whose issue is not on the board keep their section.
+func ArrangeByBoard(todoItems []todo.TodoItem, board ProjectBoard, repo string) []todo.TodoItem {
+	positions := make(map[uint64]int)
+	statuses := make(map[uint64]string)
+	for position, boardItem := range board.Items {
+		if !strings.EqualFold(boardItem.Repository, repo) {
+			continue
+		}
+		option, ok := FindStatusOption(board, boardItem.Status)
+		if !ok {
+			continue
+		}
+		positions[boardItem.IssueNumber] = position
+		statuses[boardItem.IssueNumber] = option.Name
+	}
+
+	// Re-section items according to their board status
+	sectioned := make([]todo.TodoItem, len(todoItems))
+	for i, todoItem := range todoItems {
+		sectioned[i] = todoItem
+		if todoItem.IssueNumber == nil {
+			continue
+		}
+		status, ok := statuses[*todoItem.IssueNumber]
+		if !ok || strings.EqualFold(normalizeTitle(todoItem.Section.Title), normalizeTitle(status)) {
+			continue
+		}
+		sectioned[i].Section = sectionForStatus(todoItems, status)
+	}
+
+	// Collect sections in order of first appearance
+	var titles []string
+	for _, todoItem := range sectioned {
+		if !slices.Contains(titles, todoItem.Section.Title) {
+			titles = append(titles, todoItem.Section.Title)
+		}
+	}
+
+	// Reorder the Status sections among themselves by option order
+	optionIndex := func(title string) int {
+		return slices.IndexFunc(board.StatusOptions, func(option ProjectStatusOption) bool {
+			return strings.EqualFold(normalizeTitle(option.Name), normalizeTitle(title))
+		})
+	}
+	var statusTitles []string
+	for _, title := range titles {
+		if title != "" && optionIndex(title) >= 0 {
+			statusTitles = append(statusTitles, title)
+		}
+	}
+	slices.SortStableFunc(statusTitles, func(a, b string) int {
+		return optionIndex(a) - optionIndex(b)
+	})
+	next := 0
+	for i, title := range titles {
+		if title != "" && optionIndex(title) >= 0 {
+			titles[i] = statusTitles[next]
+			next++
+		}
+	}
+
+	arranged := make([]todo.TodoItem, 0, len(sectioned))
+	for _, title := range titles {
+		var onBoard, others []todo.TodoItem
+		for _, todoItem := range sectioned {
+			if todoItem.Section.Title != title {
+				continue
+			}
+			if todoItem.IssueNumber != nil && optionIndex(title) >= 0 {
+				if _, ok := positions[*todoItem.IssueNumber]; ok {
+					onBoard = append(onBoard, todoItem)
+					continue
+				}
+			}
+			others = append(others, todoItem)
+		}
+		slices.SortStableFunc(onBoard, func(a, b todo.TodoItem) int {
+			return positions[*a.IssueNumber] - positions[*b.IssueNumber]
+		})
+		arranged = append(arranged, onBoard...)
+		arranged = append(arranged, others...)
+	}
+
+	return arranged
+}
+
+// sectionForStatus returns the section used for a board status. An existing section
+// with the same title is reused; otherwise a new section is created at the level of
+// the first existing section.
+func sectionForStatus(todoItems []todo.TodoItem, status string) todo.Section {
+	level := 0
+	for _, todoItem := range todoItems {
+		if todoItem.Section.Title == "" {
+			continue
+		}
+		if strings.EqualFold(normalizeTitle(todoItem.Section.Title), normalizeTitle(status)) {
+			return todoItem.Section
+		}
+		if level == 0 {
+			level = todoItem.Section.Level
+		}
+	}
+	return todo.Section{Title: status, Level: level}
+}
@@ -0,0 +1,314 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+func testBoard() ProjectBoard {
+	return ProjectBoard{
+		ID:            "PVT_1",
+		StatusFieldID: "PVTSSF_1",
+		StatusOptions: []ProjectStatusOption{
+			{ID: "opt_todo", Name: "Todo"},
+			{ID: "opt_progress", Name: "In Progress"},
+			{ID: "opt_done", Name: "Done"},
+		},
+	}
+}
+
+func TestParseProjectBoard(t *testing.T) {
+	data := []byte(`{
+		"data": {
+			"repositoryOwner": {
+				"projectV2": {
+					"id": "PVT_1",
+					"field": {
+						"id": "PVTSSF_1",
+						"options": [{"id": "opt_todo", "name": "Todo"}, {"id": "opt_done", "name": "Done"}]
+					},
+					"dueDateField": {"id": "PVTF_1", "dataType": "DATE"},
+					"items": {
+						"pageInfo": {"hasNextPage": true, "endCursor": "cursor1"},
+						"nodes": [
+							{
+								"id": "PVTI_1",
+								"fieldValueByName": {"name": "Done"},
+								"dueDate": {"date": "2026-11-01"},
+								"content": {"number": 12, "repository": {"nameWithOwner": "owner/repo"}}
+							},
+							{
+								"id": "PVTI_2",
+								"fieldValueByName": null,
+								"content": {"number": 13, "repository": {"nameWithOwner": "owner/repo"}}
+							},
+							{
+								"id": "PVTI_3",
+								"fieldValueByName": {"name": "Todo"},
+								"content": {}
+							}
+						]
+					}
+				}
+			}
+		}
+	}`)
+
+	board, nextCursor, err := ParseProjectBoard(data)
+	if err != nil {
+		t.Fatalf("ParseProjectBoard failed: %v", err)
+	}
+
+	if board.ID != "PVT_1" || board.StatusFieldID != "PVTSSF_1" || board.DueDateFieldID != "PVTF_1" {
+		t.Errorf("unexpected board ids: %+v", board)
+	}
+	if len(board.StatusOptions) != 2 || board.StatusOptions[1].Name != "Done" {
+		t.Errorf("unexpected status options: %+v", board.StatusOptions)
+	}
+	if nextCursor != "cursor1" {
+		t.Errorf("expected next cursor 'cursor1', got '%s'", nextCursor)
+	}
+	if len(board.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(board.Items))
+	}
+	if board.Items[0].IssueNumber != 12 || board.Items[0].Status != "Done" || board.Items[0].Repository != "owner/repo" {
+		t.Errorf("unexpected first item: %+v", board.Items[0])
+	}
+	if board.Items[0].DueDate == nil || !board.Items[0].DueDate.Equal(*datePtr(2026, 11, 1)) {
+		t.Errorf("expected due date 2026-11-01, got %v", board.Items[0].DueDate)
+	}
+	if board.Items[1].Status != "" || board.Items[1].DueDate != nil {
+		t.Errorf("expected empty status and due date, got %+v", board.Items[1])
+	}
+}
+
+func TestParseProjectBoardIgnoresDueFieldOfOtherType(t *testing.T) {
+	data := []byte(`{"data": {"repositoryOwner": {"projectV2": {
+		"id": "PVT_1",
+		"field": {"id": "PVTSSF_1", "options": []},
+		"dueDateField": {"id": "PVTF_1", "dataType": "TEXT"},
+		"items": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+	}}}}`)
+
+	board, _, err := ParseProjectBoard(data)
+	if err != nil {
+		t.Fatalf("ParseProjectBoard failed: %v", err)
+	}
+	if board.DueDateFieldID != "" {
+		t.Errorf("expected no due date field, got %q", board.DueDateFieldID)
+	}
+}
+
+func TestParseProjectBoardErrors(t *testing.T) {
+	inputs := map[string]string{
+		"invalid json":    `not json`,
+		"graphql error":   `{"errors": [{"message": "Could not resolve to a ProjectV2"}]}`,
+		"missing project": `{"data": {"repositoryOwner": {"projectV2": null}}}`,
+		"missing field":   `{"data": {"repositoryOwner": {"projectV2": {"id": "PVT_1", "field": null}}}}`,
+	}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := ParseProjectBoard([]byte(input)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestFetchProjectBoardMultiplePages(t *testing.T) {
+	page := func(number uint64, hasNext bool, cursor string) []byte {
+		return []byte(fmt.Sprintf(`{"data": {"repositoryOwner": {"projectV2": {
+			"id": "PVT_1",
+			"field": {"id": "PVTSSF_1", "options": [{"id": "opt_todo", "name": "Todo"}]},
+			"items": {
+				"pageInfo": {"hasNextPage": %t, "endCursor": "%s"},
+				"nodes": [{"id": "PVTI_%d", "content": {"number": %d, "repository": {"nameWithOwner": "owner/repo"}}}]
+			}
+		}}}}`, hasNext, cursor, number, number))
+	}
+
+	var cursors []string
+	fetcher := func(cursor string) ([]byte, error) {
+		cursors = append(cursors, cursor)
+		if cursor == "" {
+			return page(1, true, "next"), nil
+		}
+		return page(2, false, ""), nil
+	}
+
+	board, err := FetchProjectBoard(fetcher)
+	if err != nil {
+		t.Fatalf("FetchProjectBoard failed: %v", err)
+	}
+
+	if len(cursors) != 2 || cursors[1] != "next" {
+		t.Errorf("unexpected cursors: %v", cursors)
+	}
+	if len(board.Items) != 2 || board.Items[1].IssueNumber != 2 {
+		t.Errorf("unexpected items: %+v", board.Items)
+	}
+}
+
+func TestFetchProjectBoardPropagatesFetcherError(t *testing.T) {
+	fetcher := func(cursor string) ([]byte, error) {
+		return nil, errors.New("network error")
+	}
+
+	if _, err := FetchProjectBoard(fetcher); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestCalculateProjectOperations(t *testing.T) {
+	board := testBoard()
+	board.Items = []ProjectItem{
+		{ID: "PVTI_1", Repository: "owner/repo", IssueNumber: 1, Status: "Todo"},
+		{ID: "PVTI_2", Repository: "owner/repo", IssueNumber: 2, Status: "Todo"},
+		{ID: "PVTI_9", Repository: "other/repo", IssueNumber: 3, Status: "Todo"},
+	}
+	todoItems := []todo.TodoItem{
+		{Text: "In sync", IssueNumber: uint64Ptr(1), Section: todo.Section{Title: "Todo"}},
+		{Text: "Moved", IssueNumber: uint64Ptr(2), Section: todo.Section{Title: "in progress"}},
+		{Text: "Not on board", IssueNumber: uint64Ptr(3), Section: todo.Section{Title: "Notes"}},
+		{Text: "Done not on board", IsChecked: true, IssueNumber: uint64Ptr(4)},
+		{Text: "New issue", IssueNumber: uint64Ptr(5), Section: todo.Section{Title: "Done"}},
+		{Text: "No issue", Section: todo.Section{Title: "Todo"}},
+	}
+
+	createdIssues := []GitHubIssue{{Number: 5, Title: "New issue", State: IssueStateOpen}}
+
+	operations := CalculateProjectOperations(todoItems, board, "owner/repo", createdIssues)
+
+	// Issues created before this run are not backfilled onto the board
+	expected := []GitHubOperation{
+		SetProjectStatusOp{Number: 2, OptionID: "opt_progress", Status: "In Progress"},
+		AddProjectItemOp{Number: 5},
+		SetProjectStatusOp{Number: 5, OptionID: "opt_done", Status: "Done"},
+	}
+	if len(operations) != len(expected) {
+		t.Fatalf("expected %d operations, got %d: %+v", len(expected), len(operations), operations)
+	}
+	for i, op := range expected {
+		if operations[i].Operation != op {
+			t.Errorf("operation[%d]: expected %+v, got %+v", i, op, operations[i].Operation)
+		}
+	}
+}
+
+func TestArrangeByBoardResectionsAndOrdersItems(t *testing.T) {
+	board := testBoard()
+	board.Items = []ProjectItem{
+		{Repository: "owner/repo", IssueNumber: 3, Status: "In Progress"},
+		{Repository: "owner/repo", IssueNumber: 2, Status: "Todo"},
+		{Repository: "owner/repo", IssueNumber: 1, Status: "Todo"},
+		{Repository: "other/repo", IssueNumber: 4, Status: "Done"},
+	}
+	todoSection := todo.Section{Title: "Todo", Level: 2}
+	notesSection := todo.Section{Title: "Notes", Level: 2}
+	todoItems := []todo.TodoItem{
+		{Text: "Unsectioned"},
+		{Text: "First", IssueNumber: uint64Ptr(1), Section: todoSection},
+		{Text: "Second", IssueNumber: uint64Ptr(2), Section: todoSection},
+		{Text: "Unlinked todo", Section: todoSection},
+		{Text: "Third", IssueNumber: uint64Ptr(3), Section: todoSection},
+		{Text: "Other repo", IssueNumber: uint64Ptr(4), Section: notesSection},
+	}
+
+	arranged := ArrangeByBoard(todoItems, board, "owner/repo")
+
+	expected := []struct {
+		text    string
+		section todo.Section
+	}{
+		{"Unsectioned", todo.Section{}},
+		{"Second", todoSection},
+		{"First", todoSection},
+		{"Unlinked todo", todoSection},
+		{"Third", todo.Section{Title: "In Progress", Level: 2}},
+		{"Other repo", notesSection},
+	}
+	if len(arranged) != len(expected) {
+		t.Fatalf("expected %d items, got %d", len(expected), len(arranged))
+	}
+	for i, want := range expected {
+		if arranged[i].Text != want.text || arranged[i].Section != want.section {
+			t.Errorf("item[%d]: expected %s in %+v, got %s in %+v", i, want.text, want.section, arranged[i].Text, arranged[i].Section)
+		}
+	}
+}
+
+func TestArrangeByBoardOrdersStatusSectionsByOption(t *testing.T) {
+	board := testBoard()
+	board.Items = []ProjectItem{
+		{Repository: "owner/repo", IssueNumber: 1, Status: "Todo"},
+		{Repository: "owner/repo", IssueNumber: 2, Status: "Done"},
+	}
+	todoItems := []todo.TodoItem{
+		{Text: "Done task", IsChecked: true, IssueNumber: uint64Ptr(2), Section: todo.Section{Title: "Done", Level: 2}},
+		{Text: "Todo task", IssueNumber: uint64Ptr(1), Section: todo.Section{Title: "Todo", Level: 2}},
+	}
+
+	arranged := ArrangeByBoard(todoItems, board, "owner/repo")
+
+	if len(arranged) != 2 || arranged[0].Text != "Todo task" || arranged[1].Text != "Done task" {
+		t.Errorf("expected Todo section before Done section, got %+v", arranged)
+	}
+}
+
+func TestCalculateProjectDueDateOperations(t *testing.T) {
+	board := testBoard()
+	board.Items = []ProjectItem{
+		{ID: "PVTI_1", Repository: "owner/repo", IssueNumber: 1, DueDate: datePtr(2026, 11, 1)},
+		{ID: "PVTI_2", Repository: "owner/repo", IssueNumber: 2, DueDate: datePtr(2026, 11, 1)},
+		{ID: "PVTI_3", Repository: "owner/repo", IssueNumber: 3},
+	}
+	todoItems := []todo.TodoItem{
+		{Text: "Same date", IssueNumber: uint64Ptr(1), DueDate: datePtr(2026, 11, 1)},
+		{Text: "Changed date", IssueNumber: uint64Ptr(2), DueDate: datePtr(2026, 12, 1)},
+		{Text: "No date on board", IssueNumber: uint64Ptr(3), DueDate: datePtr(2026, 12, 1)},
+		{Text: "No date", IssueNumber: uint64Ptr(4)},
+		{Text: "Not on board", IssueNumber: uint64Ptr(5), DueDate: datePtr(2026, 12, 1)},
+		{Text: "No issue", DueDate: datePtr(2026, 12, 1)},
+	}
+
+	operations := CalculateProjectDueDateOperations(todoItems, board, "owner/repo")
+
+	expected := []uint64{2, 3, 5}
+	if len(operations) != len(expected) {
+		t.Fatalf("expected %d operations, got %d: %+v", len(expected), len(operations), operations)
+	}
+	for i, number := range expected {
+		op, ok := operations[i].Operation.(SetProjectDueDateOp)
+		if !ok || op.Number != number || !op.DueDate.Equal(*datePtr(2026, 12, 1)) {
+			t.Errorf("operation[%d]: expected due date 2026-12-01 for #%d, got %+v", i, number, operations[i].Operation)
+		}
+	}
+}
+
+func TestSynchronizeProjectDueDates(t *testing.T) {
+	board := testBoard()
+	board.Items = []ProjectItem{
+		{ID: "PVTI_1", Repository: "owner/repo", IssueNumber: 1, DueDate: datePtr(2026, 12, 1)},
+		{ID: "PVTI_2", Repository: "owner/repo", IssueNumber: 2},
+		{ID: "PVTI_3", Repository: "other/repo", IssueNumber: 3, DueDate: datePtr(2026, 12, 1)},
+	}
+	todoItems := []todo.TodoItem{
+		{Text: "Updated date", IssueNumber: uint64Ptr(1), DueDate: datePtr(2026, 11, 1)},
+		{Text: "Keeps local date", IssueNumber: uint64Ptr(2), DueDate: datePtr(2026, 11, 1)},
+		{Text: "Other repository", IssueNumber: uint64Ptr(3), DueDate: datePtr(2026, 11, 1)},
+	}
+
+	updated := SynchronizeProjectDueDates(todoItems, board, "owner/repo")
+
+	expected := []*time.Time{datePtr(2026, 12, 1), datePtr(2026, 11, 1), datePtr(2026, 11, 1)}
+	for i, want := range expected {
+		if updated[i].DueDate == nil || !updated[i].DueDate.Equal(*want) {
+			t.Errorf("item[%d]: expected due date %v, got %v", i, *want, updated[i].DueDate)
+		}
+	}
+}
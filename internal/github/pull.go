@@ -3,6 +3,7 @@ package github
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/toms74209200/gh-atat/internal/todo"
 )
@@ -52,16 +53,103 @@ func ParseGitHubIssues(issuesJSON []json.RawMessage) []GitHubIssue {
 			continue
 		}
 
+		// Parse state reason, ignoring reasons this tool doesn't know
+		var stateReason IssueStateReason
+		if stateReasonStr, ok := raw["state_reason"].(string); ok {
+			switch IssueStateReason(stateReasonStr) {
+			case IssueStateReasonCompleted, IssueStateReasonNotPlanned, IssueStateReasonReopened:
+				stateReason = IssueStateReason(stateReasonStr)
+			}
+		}
+
+		nodeID, _ := raw["node_id"].(string)
+
+		var createdAt *time.Time
+		if createdAtStr, ok := raw["created_at"].(string); ok {
+			if t, err := time.Parse(time.RFC3339, createdAtStr); err == nil {
+				createdAt = &t
+			}
+		}
+
 		issues = append(issues, GitHubIssue{
-			Number: uint64(number),
-			Title:  title,
-			State:  state,
+			Number:      uint64(number),
+			NodeID:      nodeID,
+			Title:       title,
+			State:       state,
+			StateReason: stateReason,
+			Milestone:   parseMilestone(raw["milestone"]),
+			Labels:      parseLabels(raw["labels"]),
+			CreatedAt:   createdAt,
 		})
 	}
 
 	return issues
 }
 
+// ParseMilestones parses JSON data and returns a list of Milestone
+func ParseMilestones(milestonesJSON []json.RawMessage) []Milestone {
+	var milestones []Milestone
+
+	for _, milestoneJSON := range milestonesJSON {
+		var raw any
+		if err := json.Unmarshal(milestoneJSON, &raw); err != nil {
+			continue
+		}
+		if milestone := parseMilestone(raw); milestone != nil {
+			milestones = append(milestones, *milestone)
+		}
+	}
+
+	return milestones
+}
+
+// parseMilestone extracts a Milestone from a decoded milestone JSON object.
+// Returns nil if the value is not a valid milestone.
+func parseMilestone(value any) *Milestone {
+	raw, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	number, numberOk := raw["number"].(float64)
+	title, titleOk := raw["title"].(string)
+	if !numberOk || number != float64(uint64(number)) || !titleOk {
+		return nil
+	}
+
+	milestone := Milestone{
+		Number: uint64(number),
+		Title:  title,
+	}
+	if dueOnStr, ok := raw["due_on"].(string); ok {
+		if dueOn, err := time.Parse(time.RFC3339, dueOnStr); err == nil {
+			milestone.DueOn = &dueOn
+		}
+	}
+
+	return &milestone
+}
+
+// parseLabels extracts label names from a decoded labels JSON array
+func parseLabels(value any) []string {
+	rawLabels, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var labels []string
+	for _, rawLabel := range rawLabels {
+		label, ok := rawLabel.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, ok := label["name"].(string); ok {
+			labels = append(labels, name)
+		}
+	}
+	return labels
+}
+
 // FetchGitHubIssues fetches all issues from GitHub with pagination
 func FetchGitHubIssues(repo string, token string, fetcher IssueFetcher) ([]GitHubIssue, error) {
 	const maxPages = 1000
@@ -110,6 +198,10 @@ func SynchronizeWithGitHubIssues(todoItems []todo.TodoItem, githubIssues []GitHu
 				if githubIssue.State == IssueStateClosed && !todoItem.IsChecked {
 					updated.IsChecked = true
 				}
+				// Issues closed as not planned are cancelled rather than done
+				if githubIssue.State == IssueStateClosed && githubIssue.StateReason == IssueStateReasonNotPlanned {
+					updated.IsCancelled = true
+				}
 			}
 		}
 
@@ -131,8 +223,8 @@ func SynchronizeWithGitHubIssues(todoItems []todo.TodoItem, githubIssues []GitHu
 				exists = true
 				break
 			}
-			// Compare titles with trim to handle whitespace
-			if trimString(todoItem.Text) == trimString(githubIssue.Title) {
+			// Compare normalized titles to ignore whitespace and Unicode forms
+			if normalizeTitle(todoItem.Text) == normalizeTitle(githubIssue.Title) {
 				exists = true
 				break
 			}
@@ -178,7 +270,7 @@ func SynchronizeTitles(todoItems []todo.TodoItem, githubIssues []GitHubIssue, pa
 
 		if todoItem.IssueNumber != nil {
 			if ghIssue, exists := githubIssuesMap[*todoItem.IssueNumber]; exists {
-				if ghIssue.State == IssueStateOpen && trimString(todoItem.Text) != trimString(ghIssue.Title) {
+				if ghIssue.State == IssueStateOpen && normalizeTitle(todoItem.Text) != normalizeTitle(ghIssue.Title) {
 					renamedIssue = &ghIssue
 				}
 			}
@@ -190,11 +282,9 @@ func SynchronizeTitles(todoItems []todo.TodoItem, githubIssues []GitHubIssue, pa
 		}
 
 		if MatchesPastTitle(pastTitles, renamedIssue.Number, todoItem.Text) {
-			updatedItems = append(updatedItems, todo.TodoItem{
-				Text:        renamedIssue.Title,
-				IsChecked:   todoItem.IsChecked,
-				IssueNumber: todoItem.IssueNumber,
-			})
+			updated := todoItem
+			updated.Text = renamedIssue.Title
+			updatedItems = append(updatedItems, updated)
 		} else {
 			localEdits = append(localEdits, renamedIssue.Number)
 			updatedItems = append(updatedItems, todoItem)
@@ -217,26 +307,3 @@ func SynchronizeTitlesWithHistory(todoItems []todo.TodoItem, githubIssues []GitH
 
 	return SynchronizeTitles(todoItems, githubIssues, pastTitles), nil
 }
-
-// trimString removes leading and trailing whitespace
-func trimString(s string) string {
-	start := 0
-	end := len(s)
-
-	// Find first non-whitespace character
-	for start < len(s) && isWhitespace(s[start]) {
-		start++
-	}
-
-	// Find last non-whitespace character
-	for end > start && isWhitespace(s[end-1]) {
-		end--
-	}
-
-	return s[start:end]
-}
-
-// isWhitespace checks if a byte is a whitespace character
-func isWhitespace(b byte) bool {
-	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
-}
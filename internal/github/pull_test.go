@@ -3,7 +3,9 @@ package github
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/toms74209200/gh-atat/internal/todo"
 )
@@ -49,6 +51,45 @@ func TestParseGitHubIssuesWithValidIssues(t *testing.T) {
 	}
 }
 
+func TestParseGitHubIssuesWithLabels(t *testing.T) {
+	issuesJSON := []json.RawMessage{
+		json.RawMessage(`{
+			"number": 123,
+			"title": "Labelled issue",
+			"state": "open",
+			"labels": [{"name": "roadmap"}, {"name": "bug"}, "invalid"]
+		}`),
+	}
+
+	issues := ParseGitHubIssues(issuesJSON)
+
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d", len(issues))
+	}
+	if !slices.Equal(issues[0].Labels, []string{"roadmap", "bug"}) {
+		t.Errorf("Expected labels [roadmap bug], got %v", issues[0].Labels)
+	}
+}
+
+func TestParseGitHubIssuesParsesCreatedAt(t *testing.T) {
+	issuesJSON := []json.RawMessage{
+		json.RawMessage(`{"number": 1, "title": "Dated", "state": "open", "created_at": "2026-10-01T09:30:00Z"}`),
+		json.RawMessage(`{"number": 2, "title": "Undated", "state": "open", "created_at": "yesterday"}`),
+	}
+
+	issues := ParseGitHubIssues(issuesJSON)
+
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d", len(issues))
+	}
+	if issues[0].CreatedAt == nil || !issues[0].CreatedAt.Equal(time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected created at 2026-10-01T09:30:00Z, got %v", issues[0].CreatedAt)
+	}
+	if issues[1].CreatedAt != nil {
+		t.Errorf("Expected no created date for an invalid date, got %v", issues[1].CreatedAt)
+	}
+}
+
 func TestParseGitHubIssuesFiltersPullRequests(t *testing.T) {
 	issuesJSON := []json.RawMessage{
 		json.RawMessage(`{
@@ -379,6 +420,53 @@ func TestSynchronizeWithGitHubIssuesUpdatesClosedIssues(t *testing.T) {
 	}
 }
 
+func TestSynchronizeWithGitHubIssuesMarksNotPlannedIssuesCancelled(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Dropped upstream", IsChecked: false, IssueNumber: uint64Ptr(1)},
+		{Text: "Done then dropped", IsChecked: true, IssueNumber: uint64Ptr(2)},
+		{Text: "Completed upstream", IsChecked: false, IssueNumber: uint64Ptr(3)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "Dropped upstream", State: IssueStateClosed, StateReason: IssueStateReasonNotPlanned},
+		{Number: 2, Title: "Done then dropped", State: IssueStateClosed, StateReason: IssueStateReasonNotPlanned},
+		{Number: 3, Title: "Completed upstream", State: IssueStateClosed, StateReason: IssueStateReasonCompleted},
+	}
+
+	result := SynchronizeWithGitHubIssues(todoItems, githubIssues)
+
+	expected := []struct{ checked, cancelled bool }{{true, true}, {true, true}, {true, false}}
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d items, got %d", len(expected), len(result))
+	}
+	for i, want := range expected {
+		if result[i].IsChecked != want.checked || result[i].IsCancelled != want.cancelled {
+			t.Errorf("item[%d]: expected checked=%v cancelled=%v, got checked=%v cancelled=%v",
+				i, want.checked, want.cancelled, result[i].IsChecked, result[i].IsCancelled)
+		}
+	}
+}
+
+func TestParseGitHubIssuesParsesStateReason(t *testing.T) {
+	issuesJSON := []json.RawMessage{
+		json.RawMessage(`{"number": 1, "title": "Not planned", "state": "closed", "state_reason": "not_planned"}`),
+		json.RawMessage(`{"number": 2, "title": "Completed", "state": "closed", "state_reason": "completed"}`),
+		json.RawMessage(`{"number": 3, "title": "Open", "state": "open", "state_reason": null}`),
+		json.RawMessage(`{"number": 4, "title": "Unknown", "state": "closed", "state_reason": "duplicate"}`),
+	}
+
+	issues := ParseGitHubIssues(issuesJSON)
+
+	expected := []IssueStateReason{IssueStateReasonNotPlanned, IssueStateReasonCompleted, IssueStateReasonNone, IssueStateReasonNone}
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %d", len(expected), len(issues))
+	}
+	for i, want := range expected {
+		if issues[i].StateReason != want {
+			t.Errorf("issue[%d]: expected reason '%s', got '%s'", i, want, issues[i].StateReason)
+		}
+	}
+}
+
 func TestSynchronizeWithGitHubIssuesAddsNewOpenIssues(t *testing.T) {
 	issueNum123 := uint64(123)
 	todoItems := []todo.TodoItem{
@@ -1,6 +1,10 @@
 package github
 
 import (
+	"encoding/json"
+	"slices"
+	"time"
+
 	"github.com/toms74209200/gh-atat/internal/todo"
 )
 
@@ -16,9 +20,11 @@ type CreateIssueOp struct {
 
 func (CreateIssueOp) isGitHubOperation() {}
 
-// CloseIssueOp represents closing an existing GitHub issue
+// CloseIssueOp represents closing an existing GitHub issue.
+// Reason is sent as the state reason unless it is empty.
 type CloseIssueOp struct {
 	Number uint64
+	Reason IssueStateReason
 }
 
 func (CloseIssueOp) isGitHubOperation() {}
@@ -31,6 +37,38 @@ type RenameIssueOp struct {
 
 func (RenameIssueOp) isGitHubOperation() {}
 
+// SetMilestoneOp represents assigning an existing GitHub issue to the milestone for a due date
+type SetMilestoneOp struct {
+	Number  uint64
+	DueDate time.Time
+}
+
+func (SetMilestoneOp) isGitHubOperation() {}
+
+// AddProjectItemOp represents adding an existing GitHub issue to the project board
+type AddProjectItemOp struct {
+	Number uint64
+}
+
+func (AddProjectItemOp) isGitHubOperation() {}
+
+// SetProjectStatusOp represents setting the Status field of an issue on the project board
+type SetProjectStatusOp struct {
+	Number   uint64
+	OptionID string
+	Status   string
+}
+
+func (SetProjectStatusOp) isGitHubOperation() {}
+
+// SetProjectDueDateOp represents setting the Due field of an issue on the project board
+type SetProjectDueDateOp struct {
+	Number  uint64
+	DueDate time.Time
+}
+
+func (SetProjectDueDateOp) isGitHubOperation() {}
+
 // TodoOperation represents a todo item with its associated GitHub operation
 type TodoOperation struct {
 	Todo      todo.TodoItem
@@ -47,17 +85,28 @@ func CalculateGitHubOperations(todoItems []todo.TodoItem, githubIssues []GitHubI
 
 		switch {
 		// Unchecked todo without issue number -> create new issue
-		case !todoItem.IsChecked && todoItem.IssueNumber == nil:
+		case !todoItem.IsChecked && todoItem.IssueNumber == nil && todoItem.ExternalIssue == nil:
 			op = CreateIssueOp{Title: todoItem.Text}
 
-		// Checked todo with issue number -> close issue if it's open
+		// Checked todo with issue number -> close issue if it's open,
+		// or update the close reason if the item was cancelled or restored
 		case todoItem.IsChecked && todoItem.IssueNumber != nil:
 			issueNum := *todoItem.IssueNumber
+			reason := IssueStateReasonCompleted
+			if todoItem.IsCancelled {
+				reason = IssueStateReasonNotPlanned
+			}
 			for _, issue := range githubIssues {
-				if issue.Number == issueNum && issue.State == IssueStateOpen {
-					op = CloseIssueOp{Number: issueNum}
-					break
+				if issue.Number != issueNum {
+					continue
 				}
+				switch {
+				case issue.State == IssueStateOpen,
+					todoItem.IsCancelled && issue.StateReason != IssueStateReasonNotPlanned,
+					!todoItem.IsCancelled && issue.StateReason == IssueStateReasonNotPlanned:
+					op = CloseIssueOp{Number: issueNum, Reason: reason}
+				}
+				break
 			}
 
 		// All other cases -> no operation
@@ -76,6 +125,96 @@ func CalculateGitHubOperations(todoItems []todo.TodoItem, githubIssues []GitHubI
 	return operations
 }
 
+// ParsePastCloseReasons extracts the state reasons an issue was closed with from its
+// GitHub issue events. Closed events without a state reason were closed as completed.
+func ParsePastCloseReasons(eventsJSON []json.RawMessage) []IssueStateReason {
+	var reasons []IssueStateReason
+	for _, eventJSON := range eventsJSON {
+		var event struct {
+			Event       string  `json:"event"`
+			StateReason *string `json:"state_reason"`
+		}
+		if err := json.Unmarshal(eventJSON, &event); err != nil || event.Event != "closed" {
+			continue
+		}
+		reason := IssueStateReasonCompleted
+		if event.StateReason != nil && *event.StateReason != "" {
+			reason = IssueStateReason(*event.StateReason)
+		}
+		reasons = append(reasons, reason)
+	}
+	return reasons
+}
+
+// CollectPastCloseReasons fetches the past close reasons only for closed issues whose
+// close reason differs from the state of their checked todo item.
+func CollectPastCloseReasons(todoItems []todo.TodoItem, githubIssues []GitHubIssue, eventsFetcher EventsFetcher) (map[uint64][]IssueStateReason, error) {
+	pastReasons := make(map[uint64][]IssueStateReason)
+	for _, conflict := range findStateConflicts(todoItems, githubIssues, nil) {
+		events, err := eventsFetcher(conflict.Number)
+		if err != nil {
+			return nil, err
+		}
+		pastReasons[conflict.Number] = ParsePastCloseReasons(events)
+	}
+	return pastReasons, nil
+}
+
+// FindPushStateConflicts returns the checked items whose closed issue has a different close
+// reason, except for the items whose state the issue was never closed with, as found in
+// pastReasons. Those items were cancelled or restored locally, so their issue takes the new
+// reason. The issue of any other item was closed with the state of the item before, and its
+// reason was changed on GitHub since, so the difference is a conflict.
+func FindPushStateConflicts(todoItems []todo.TodoItem, githubIssues []GitHubIssue, pastReasons map[uint64][]IssueStateReason) []StateConflict {
+	changed := make(map[uint64]bool)
+	for _, todoItem := range todoItems {
+		if todoItem.IssueNumber == nil || !todoItem.IsChecked {
+			continue
+		}
+		localReason := IssueStateReasonCompleted
+		if todoItem.IsCancelled {
+			localReason = IssueStateReasonNotPlanned
+		}
+		if !slices.Contains(pastReasons[*todoItem.IssueNumber], localReason) {
+			changed[*todoItem.IssueNumber] = true
+		}
+	}
+	return findStateConflicts(todoItems, githubIssues, changed)
+}
+
+// ResolveStateConflicts settles the state conflicts in cancelled, by issue number.
+// Items settled with the state on GitHub take it, and their issues are left unchanged.
+// The issues of unresolved conflicts are left unchanged too, so only the close operations
+// of conflicts settled with the local state are kept.
+func ResolveStateConflicts(todoItems []todo.TodoItem, operations []TodoOperation, conflicts []StateConflict, cancelled map[uint64]bool) ([]todo.TodoItem, []TodoOperation) {
+	skipped := make(map[uint64]bool)
+	items := slices.Clone(todoItems)
+	for _, conflict := range conflicts {
+		state, resolved := cancelled[conflict.Number]
+		if resolved && state == conflict.LocalCancelled {
+			continue
+		}
+		skipped[conflict.Number] = true
+		if !resolved {
+			continue
+		}
+		for i, item := range items {
+			if item.IssueNumber != nil && *item.IssueNumber == conflict.Number && item.IsChecked {
+				items[i].IsCancelled = state
+			}
+		}
+	}
+
+	var kept []TodoOperation
+	for _, todoOp := range operations {
+		if closeOp, ok := todoOp.Operation.(CloseIssueOp); ok && skipped[closeOp.Number] {
+			continue
+		}
+		kept = append(kept, todoOp)
+	}
+	return items, kept
+}
+
 // TitleUpdates holds the result of title update calculation during push.
 // Operations contains rename operations to perform, and StaleIssues contains
 // issue numbers where the remote was renamed (local text is stale).
@@ -107,7 +246,7 @@ func CalculateTitleUpdates(todoItems []todo.TodoItem, githubIssues []GitHubIssue
 		if ghIssue.State != IssueStateOpen {
 			continue
 		}
-		if trimString(todoItem.Text) == trimString(ghIssue.Title) {
+		if normalizeTitle(todoItem.Text) == normalizeTitle(ghIssue.Title) {
 			continue
 		}
 
@@ -178,7 +317,7 @@ func CalculateTodoUpdates(
 			}
 			issueNumber = nil
 
-		case RenameIssueOp:
+		case RenameIssueOp, SetMilestoneOp, AddProjectItemOp, SetProjectStatusOp, SetProjectDueDateOp:
 			issueNumber = nil
 		}
 
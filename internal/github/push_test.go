@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/toms74209200/gh-atat/internal/todo"
@@ -40,6 +41,14 @@ func TestCalculateGitHubOperations(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "unchecked_with_external_issue_does_nothing",
+			todoItems: []todo.TodoItem{
+				{Text: "Moved task", ExternalIssue: &todo.IssueRef{Repo: "other/repo", Number: 7}},
+			},
+			githubIssues:    []GitHubIssue{},
+			expectedOpCount: 0,
+		},
 		{
 			name: "checked_with_open_issue_closes_issue",
 			todoItems: []todo.TodoItem{
@@ -60,6 +69,92 @@ func TestCalculateGitHubOperations(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "checked_with_open_issue_closes_as_completed",
+			todoItems: []todo.TodoItem{
+				{Text: "Completed task", IsChecked: true, IssueNumber: uint64Ptr(123)},
+			},
+			githubIssues: []GitHubIssue{
+				{Number: 123, Title: "Completed task", State: IssueStateOpen},
+			},
+			expectedOpCount: 1,
+			expectedOpType:  CloseIssueOp{},
+			validateOp: func(t *testing.T, op GitHubOperation) {
+				closeOp := op.(CloseIssueOp)
+				if closeOp.Reason != IssueStateReasonCompleted {
+					t.Errorf("expected reason completed, got '%s'", closeOp.Reason)
+				}
+			},
+		},
+		{
+			name: "cancelled_with_open_issue_closes_as_not_planned",
+			todoItems: []todo.TodoItem{
+				{Text: "Dropped task", IsChecked: true, IsCancelled: true, IssueNumber: uint64Ptr(123)},
+			},
+			githubIssues: []GitHubIssue{
+				{Number: 123, Title: "Dropped task", State: IssueStateOpen},
+			},
+			expectedOpCount: 1,
+			expectedOpType:  CloseIssueOp{},
+			validateOp: func(t *testing.T, op GitHubOperation) {
+				closeOp := op.(CloseIssueOp)
+				if closeOp.Number != 123 || closeOp.Reason != IssueStateReasonNotPlanned {
+					t.Errorf("expected #123 closed as not planned, got %+v", closeOp)
+				}
+			},
+		},
+		{
+			name: "cancelled_with_completed_issue_updates_reason",
+			todoItems: []todo.TodoItem{
+				{Text: "Dropped task", IsChecked: true, IsCancelled: true, IssueNumber: uint64Ptr(123)},
+			},
+			githubIssues: []GitHubIssue{
+				{Number: 123, Title: "Dropped task", State: IssueStateClosed, StateReason: IssueStateReasonCompleted},
+			},
+			expectedOpCount: 1,
+			expectedOpType:  CloseIssueOp{},
+			validateOp: func(t *testing.T, op GitHubOperation) {
+				closeOp := op.(CloseIssueOp)
+				if closeOp.Reason != IssueStateReasonNotPlanned {
+					t.Errorf("expected reason not_planned, got '%s'", closeOp.Reason)
+				}
+			},
+		},
+		{
+			name: "checked_with_not_planned_issue_updates_reason",
+			todoItems: []todo.TodoItem{
+				{Text: "Restored task", IsChecked: true, IssueNumber: uint64Ptr(123)},
+			},
+			githubIssues: []GitHubIssue{
+				{Number: 123, Title: "Restored task", State: IssueStateClosed, StateReason: IssueStateReasonNotPlanned},
+			},
+			expectedOpCount: 1,
+			expectedOpType:  CloseIssueOp{},
+			validateOp: func(t *testing.T, op GitHubOperation) {
+				closeOp := op.(CloseIssueOp)
+				if closeOp.Reason != IssueStateReasonCompleted {
+					t.Errorf("expected reason completed, got '%s'", closeOp.Reason)
+				}
+			},
+		},
+		{
+			name: "cancelled_with_not_planned_issue_no_operation",
+			todoItems: []todo.TodoItem{
+				{Text: "Dropped task", IsChecked: true, IsCancelled: true, IssueNumber: uint64Ptr(123)},
+			},
+			githubIssues: []GitHubIssue{
+				{Number: 123, Title: "Dropped task", State: IssueStateClosed, StateReason: IssueStateReasonNotPlanned},
+			},
+			expectedOpCount: 0,
+		},
+		{
+			name: "cancelled_without_issue_no_operation",
+			todoItems: []todo.TodoItem{
+				{Text: "Dropped task", IsChecked: true, IsCancelled: true, IssueNumber: nil},
+			},
+			githubIssues:    []GitHubIssue{},
+			expectedOpCount: 0,
+		},
 		{
 			name: "checked_with_closed_issue_no_operation",
 			todoItems: []todo.TodoItem{
@@ -118,6 +213,142 @@ func TestCalculateGitHubOperations(t *testing.T) {
 	}
 }
 
+func TestFindPushStateConflicts(t *testing.T) {
+	tests := []struct {
+		name        string
+		todoItem    todo.TodoItem
+		pastReasons map[uint64][]IssueStateReason
+		expected    []StateConflict
+	}{
+		{
+			name:     "reason changed on GitHub",
+			todoItem: todo.TodoItem{Text: "Task", IsChecked: true, IssueNumber: uint64Ptr(1)},
+			pastReasons: map[uint64][]IssueStateReason{
+				1: {IssueStateReasonCompleted, IssueStateReasonNotPlanned},
+			},
+			expected: []StateConflict{{Number: 1, LocalCancelled: false, RemoteCancelled: true}},
+		},
+		{
+			name:     "restored locally",
+			todoItem: todo.TodoItem{Text: "Task", IsChecked: true, IssueNumber: uint64Ptr(1)},
+			pastReasons: map[uint64][]IssueStateReason{
+				1: {IssueStateReasonNotPlanned},
+			},
+			expected: nil,
+		},
+		{
+			name:     "same reason",
+			todoItem: todo.TodoItem{Text: "Task", IsChecked: true, IsCancelled: true, IssueNumber: uint64Ptr(1)},
+			expected: nil,
+		},
+	}
+
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "Task", State: IssueStateClosed, StateReason: IssueStateReasonNotPlanned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts := FindPushStateConflicts([]todo.TodoItem{tt.todoItem}, githubIssues, tt.pastReasons)
+
+			if !reflect.DeepEqual(conflicts, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, conflicts)
+			}
+		})
+	}
+}
+
+func TestParsePastCloseReasons(t *testing.T) {
+	events := []json.RawMessage{
+		json.RawMessage(`{"event": "closed", "state_reason": null}`),
+		json.RawMessage(`{"event": "reopened", "state_reason": "reopened"}`),
+		json.RawMessage(`{"event": "renamed", "rename": {"from": "A", "to": "B"}}`),
+		json.RawMessage(`{"event": "closed", "state_reason": "not_planned"}`),
+		json.RawMessage(`not json`),
+	}
+
+	reasons := ParsePastCloseReasons(events)
+
+	expected := []IssueStateReason{IssueStateReasonCompleted, IssueStateReasonNotPlanned}
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("expected %+v, got %+v", expected, reasons)
+	}
+}
+
+func TestCollectPastCloseReasonsFetchesOnlyDifferentReasons(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Different", IsChecked: true, IssueNumber: uint64Ptr(1)},
+		{Text: "Same", IsChecked: true, IsCancelled: true, IssueNumber: uint64Ptr(2)},
+		{Text: "Open", IssueNumber: uint64Ptr(3)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "Different", State: IssueStateClosed, StateReason: IssueStateReasonNotPlanned},
+		{Number: 2, Title: "Same", State: IssueStateClosed, StateReason: IssueStateReasonNotPlanned},
+		{Number: 3, Title: "Open", State: IssueStateClosed, StateReason: IssueStateReasonCompleted},
+	}
+
+	var fetched []uint64
+	pastReasons, err := CollectPastCloseReasons(todoItems, githubIssues, func(issueNumber uint64) ([]json.RawMessage, error) {
+		fetched = append(fetched, issueNumber)
+		return []json.RawMessage{json.RawMessage(`{"event": "closed", "state_reason": "completed"}`)}, nil
+	})
+	if err != nil {
+		t.Fatalf("CollectPastCloseReasons failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(fetched, []uint64{1}) {
+		t.Errorf("expected events of #1 only, got %v", fetched)
+	}
+	if !reflect.DeepEqual(pastReasons[1], []IssueStateReason{IssueStateReasonCompleted}) {
+		t.Errorf("unexpected past reasons %+v", pastReasons)
+	}
+}
+
+func TestResolveStateConflicts(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Ours", IsChecked: true, IssueNumber: uint64Ptr(1)},
+		{Text: "Theirs", IsChecked: true, IssueNumber: uint64Ptr(2)},
+		{Text: "Unresolved", IsChecked: true, IssueNumber: uint64Ptr(3)},
+		{Text: "Open", IsChecked: true, IssueNumber: uint64Ptr(4)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "Ours", State: IssueStateClosed, StateReason: IssueStateReasonNotPlanned},
+		{Number: 2, Title: "Theirs", State: IssueStateClosed, StateReason: IssueStateReasonNotPlanned},
+		{Number: 3, Title: "Unresolved", State: IssueStateClosed, StateReason: IssueStateReasonNotPlanned},
+		{Number: 4, Title: "Open", State: IssueStateOpen},
+	}
+	conflicts := []StateConflict{
+		{Number: 1, LocalCancelled: false, RemoteCancelled: true},
+		{Number: 2, LocalCancelled: false, RemoteCancelled: true},
+		{Number: 3, LocalCancelled: false, RemoteCancelled: true},
+	}
+
+	items, operations := ResolveStateConflicts(todoItems, CalculateGitHubOperations(todoItems, githubIssues), conflicts, map[uint64]bool{1: false, 2: true})
+
+	expectedOps := []GitHubOperation{
+		CloseIssueOp{Number: 1, Reason: IssueStateReasonCompleted},
+		CloseIssueOp{Number: 4, Reason: IssueStateReasonCompleted},
+	}
+	if len(operations) != len(expectedOps) {
+		t.Fatalf("expected %+v, got %+v", expectedOps, operations)
+	}
+	for i, op := range operations {
+		if op.Operation != expectedOps[i] {
+			t.Errorf("expected %+v, got %+v", expectedOps[i], op.Operation)
+		}
+	}
+
+	expectedCancelled := []bool{false, true, false, false}
+	for i, item := range items {
+		if item.IsCancelled != expectedCancelled[i] {
+			t.Errorf("expected %s to be cancelled: %v, got %v", item.Text, expectedCancelled[i], item.IsCancelled)
+		}
+	}
+	if todoItems[1].IsCancelled {
+		t.Errorf("expected the input items to be unchanged")
+	}
+}
+
 func TestCreateIssueOperationCallsCreator(t *testing.T) {
 	todoItem := todo.TodoItem{
 		Text:        "New task",
@@ -0,0 +1,150 @@
+package github
+
+import (
+	"slices"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+// StateConflict is an issue closed on GitHub with a different close reason than its
+// checked todo item, such as an issue closed as completed whose item was cancelled.
+type StateConflict struct {
+	Number uint64
+	// LocalCancelled reports whether the todo item is cancelled
+	LocalCancelled bool
+	// RemoteCancelled reports whether the issue was closed as not planned
+	RemoteCancelled bool
+}
+
+// SyncResolutions settle conflicts found by PlanSync, by issue number
+type SyncResolutions struct {
+	// Titles are the titles to use for both the todo item and the issue
+	Titles map[uint64]string
+	// Cancelled tells whether the todo item and the issue are cancelled (closed as not planned)
+	// or done (closed as completed)
+	Cancelled map[uint64]bool
+}
+
+// SyncPlan is the result of synchronizing todo items with GitHub issues in both directions
+type SyncPlan struct {
+	// Items are the todo items updated from the GitHub issues
+	Items []todo.TodoItem
+	// Operations are the GitHub operations updating the issues from the todo items
+	Operations []TodoOperation
+	// TitleConflicts are unresolved issues whose title and todo item text were both changed.
+	// Both sides are left unchanged.
+	TitleConflicts []TitleConflict
+	// StateConflicts are unresolved issues closed with a different reason than their todo item.
+	// Both sides are left unchanged.
+	StateConflicts []StateConflict
+}
+
+// PlanSync merges todo items and GitHub issues in both directions, as pull followed by push
+// would, but without letting either side overwrite changes made on the other.
+//
+// Titles are reconciled with the rename history in pastTitles: items whose text matches a
+// past title take the current title, and issues never renamed take the item text. An issue
+// renamed on GitHub whose item text was also edited is a title conflict. Closed issues mark
+// their items as done, open issues missing from the items are added, and the remaining
+// differences become create, close and rename operations. A checked item and its closed issue
+// with different close reasons are a state conflict.
+//
+// Conflicts settled in resolutions are applied to both sides; other conflicts are returned
+// and left unchanged.
+func PlanSync(todoItems []todo.TodoItem, githubIssues []GitHubIssue, pastTitles map[uint64][]string, resolutions SyncResolutions) SyncPlan {
+	// Take the titles of issues renamed on GitHub for items that were not edited
+	titleSync := SynchronizeTitles(todoItems, githubIssues, pastTitles)
+
+	var titleConflicts []uint64
+	for _, issueNumber := range titleSync.LocallyEditedIssues {
+		if _, resolved := resolutions.Titles[issueNumber]; resolved {
+			continue
+		}
+		if len(pastTitles[issueNumber]) > 0 && !slices.Contains(titleConflicts, issueNumber) {
+			titleConflicts = append(titleConflicts, issueNumber)
+		}
+	}
+
+	// Rename issues whose items were edited, unless they were renamed on GitHub too
+	var operations []TodoOperation
+	for _, todoOp := range CalculateTitleUpdates(titleSync.Items, githubIssues, pastTitles).Operations {
+		issueNumber := todoOp.Operation.(RenameIssueOp).Number
+		if _, resolved := resolutions.Titles[issueNumber]; resolved || slices.Contains(titleConflicts, issueNumber) {
+			continue
+		}
+		operations = append(operations, todoOp)
+	}
+
+	items, renames := ResolveTitleConflicts(titleSync.Items, githubIssues, resolutions.Titles)
+	operations = append(operations, renames...)
+
+	// Merge issue states into the items, keeping the items of unresolved state conflicts
+	stateConflicts := findStateConflicts(items, githubIssues, resolutions.Cancelled)
+	merged := SynchronizeWithGitHubIssues(items, githubIssues)
+	for i, item := range items {
+		if item.IssueNumber == nil {
+			continue
+		}
+		if cancelled, resolved := resolutions.Cancelled[*item.IssueNumber]; resolved && merged[i].IsChecked {
+			merged[i].IsCancelled = cancelled
+			continue
+		}
+		if slices.ContainsFunc(stateConflicts, func(c StateConflict) bool { return c.Number == *item.IssueNumber }) {
+			merged[i].IsChecked = item.IsChecked
+			merged[i].IsCancelled = item.IsCancelled
+		}
+	}
+
+	// Create and close issues from the merged items
+	for _, todoOp := range CalculateGitHubOperations(merged, githubIssues) {
+		if closeOp, ok := todoOp.Operation.(CloseIssueOp); ok &&
+			slices.ContainsFunc(stateConflicts, func(c StateConflict) bool { return c.Number == closeOp.Number }) {
+			continue
+		}
+		operations = append(operations, todoOp)
+	}
+
+	return SyncPlan{
+		Items:          merged,
+		Operations:     operations,
+		TitleConflicts: FindTitleConflicts(items, githubIssues, titleConflicts),
+		StateConflicts: stateConflicts,
+	}
+}
+
+// findStateConflicts returns the checked items whose closed issue has a different close reason,
+// except for the issues in resolved
+func findStateConflicts(todoItems []todo.TodoItem, githubIssues []GitHubIssue, resolved map[uint64]bool) []StateConflict {
+	githubIssuesMap := make(map[uint64]GitHubIssue)
+	for _, issue := range githubIssues {
+		githubIssuesMap[issue.Number] = issue
+	}
+
+	var conflicts []StateConflict
+	for _, todoItem := range todoItems {
+		if todoItem.IssueNumber == nil || !todoItem.IsChecked {
+			continue
+		}
+		if _, ok := resolved[*todoItem.IssueNumber]; ok {
+			continue
+		}
+		ghIssue, exists := githubIssuesMap[*todoItem.IssueNumber]
+		if !exists || ghIssue.State != IssueStateClosed {
+			continue
+		}
+		remoteCancelled := ghIssue.StateReason == IssueStateReasonNotPlanned
+		if todoItem.IsCancelled == remoteCancelled {
+			continue
+		}
+		if slices.ContainsFunc(conflicts, func(c StateConflict) bool { return c.Number == ghIssue.Number }) {
+			continue
+		}
+		conflicts = append(conflicts, StateConflict{
+			Number:          ghIssue.Number,
+			LocalCancelled:  todoItem.IsCancelled,
+			RemoteCancelled: remoteCancelled,
+		})
+	}
+
+	return conflicts
+}
@@ -0,0 +1,189 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+func TestPlanSyncMergesBothDirections(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "New task"},
+		{Text: "Done locally", IsChecked: true, IssueNumber: uint64Ptr(1)},
+		{Text: "Done remotely", IssueNumber: uint64Ptr(2)},
+		{Text: "Old title", IssueNumber: uint64Ptr(3)},
+		{Text: "Edited locally", IssueNumber: uint64Ptr(4)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "Done locally", State: IssueStateOpen},
+		{Number: 2, Title: "Done remotely", State: IssueStateClosed, StateReason: IssueStateReasonCompleted},
+		{Number: 3, Title: "Renamed remotely", State: IssueStateOpen},
+		{Number: 4, Title: "Original title", State: IssueStateOpen},
+		{Number: 5, Title: "Created on GitHub", State: IssueStateOpen},
+	}
+	pastTitles := map[uint64][]string{
+		3: {"Old title"},
+		4: {},
+	}
+
+	plan := PlanSync(todoItems, githubIssues, pastTitles, SyncResolutions{})
+
+	expectedItems := []todo.TodoItem{
+		{Text: "New task"},
+		{Text: "Done locally", IsChecked: true, IssueNumber: uint64Ptr(1)},
+		{Text: "Done remotely", IsChecked: true, IssueNumber: uint64Ptr(2)},
+		{Text: "Renamed remotely", IssueNumber: uint64Ptr(3)},
+		{Text: "Edited locally", IssueNumber: uint64Ptr(4)},
+		{Text: "Created on GitHub", IssueNumber: uint64Ptr(5)},
+	}
+	if !reflect.DeepEqual(plan.Items, expectedItems) {
+		t.Errorf("expected items %+v, got %+v", expectedItems, plan.Items)
+	}
+
+	var ops []GitHubOperation
+	for _, todoOp := range plan.Operations {
+		ops = append(ops, todoOp.Operation)
+	}
+	expectedOps := []GitHubOperation{
+		RenameIssueOp{Number: 4, Title: "Edited locally"},
+		CreateIssueOp{Title: "New task"},
+		CloseIssueOp{Number: 1, Reason: IssueStateReasonCompleted},
+	}
+	if !reflect.DeepEqual(ops, expectedOps) {
+		t.Errorf("expected operations %+v, got %+v", expectedOps, ops)
+	}
+
+	if len(plan.TitleConflicts) != 0 || len(plan.StateConflicts) != 0 {
+		t.Errorf("expected no conflicts, got %+v and %+v", plan.TitleConflicts, plan.StateConflicts)
+	}
+}
+
+func TestPlanSyncReportsTitleChangedOnBothSides(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Fix login bug", IssueNumber: uint64Ptr(4)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 4, Title: "Fix sign-in bug", State: IssueStateOpen},
+	}
+	pastTitles := map[uint64][]string{
+		4: {"Fix bug"},
+	}
+
+	plan := PlanSync(todoItems, githubIssues, pastTitles, SyncResolutions{})
+
+	expected := []TitleConflict{{Number: 4, Local: "Fix login bug", Remote: "Fix sign-in bug"}}
+	if !reflect.DeepEqual(plan.TitleConflicts, expected) {
+		t.Errorf("expected conflicts %+v, got %+v", expected, plan.TitleConflicts)
+	}
+	if plan.Items[0].Text != "Fix login bug" {
+		t.Errorf("expected the item to be unchanged, got '%s'", plan.Items[0].Text)
+	}
+	if len(plan.Operations) != 0 {
+		t.Errorf("expected no operations, got %+v", plan.Operations)
+	}
+}
+
+func TestPlanSyncAppliesTitleResolution(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Fix login bug", IssueNumber: uint64Ptr(4)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 4, Title: "Fix sign-in bug", State: IssueStateOpen},
+	}
+	pastTitles := map[uint64][]string{
+		4: {"Fix bug"},
+	}
+
+	plan := PlanSync(todoItems, githubIssues, pastTitles, SyncResolutions{Titles: map[uint64]string{4: "Fix login bug"}})
+
+	if len(plan.TitleConflicts) != 0 {
+		t.Errorf("expected no conflicts, got %+v", plan.TitleConflicts)
+	}
+	if len(plan.Operations) != 1 {
+		t.Fatalf("expected 1 operation, got %d", len(plan.Operations))
+	}
+	if op := plan.Operations[0].Operation; op != (RenameIssueOp{Number: 4, Title: "Fix login bug"}) {
+		t.Errorf("expected rename of #4, got %+v", op)
+	}
+}
+
+func TestPlanSyncReportsDifferentCloseReasons(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Cancelled locally", IsChecked: true, IsCancelled: true, IssueNumber: uint64Ptr(1)},
+		{Text: "Done locally", IsChecked: true, IssueNumber: uint64Ptr(2)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "Cancelled locally", State: IssueStateClosed, StateReason: IssueStateReasonCompleted},
+		{Number: 2, Title: "Done locally", State: IssueStateClosed, StateReason: IssueStateReasonNotPlanned},
+	}
+
+	plan := PlanSync(todoItems, githubIssues, nil, SyncResolutions{})
+
+	expected := []StateConflict{
+		{Number: 1, LocalCancelled: true, RemoteCancelled: false},
+		{Number: 2, LocalCancelled: false, RemoteCancelled: true},
+	}
+	if !reflect.DeepEqual(plan.StateConflicts, expected) {
+		t.Errorf("expected conflicts %+v, got %+v", expected, plan.StateConflicts)
+	}
+	if !reflect.DeepEqual(plan.Items, todoItems) {
+		t.Errorf("expected items to be unchanged, got %+v", plan.Items)
+	}
+	if len(plan.Operations) != 0 {
+		t.Errorf("expected no operations, got %+v", plan.Operations)
+	}
+}
+
+func TestPlanSyncAppliesStateResolution(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Cancelled locally", IsChecked: true, IsCancelled: true, IssueNumber: uint64Ptr(1)},
+		{Text: "Done locally", IsChecked: true, IssueNumber: uint64Ptr(2)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "Cancelled locally", State: IssueStateClosed, StateReason: IssueStateReasonCompleted},
+		{Number: 2, Title: "Done locally", State: IssueStateClosed, StateReason: IssueStateReasonNotPlanned},
+	}
+	resolutions := SyncResolutions{Cancelled: map[uint64]bool{1: true, 2: true}}
+
+	plan := PlanSync(todoItems, githubIssues, nil, resolutions)
+
+	if len(plan.StateConflicts) != 0 {
+		t.Errorf("expected no conflicts, got %+v", plan.StateConflicts)
+	}
+	if !plan.Items[1].IsCancelled {
+		t.Error("expected the second item to be cancelled")
+	}
+	if len(plan.Operations) != 1 {
+		t.Fatalf("expected 1 operation, got %d", len(plan.Operations))
+	}
+	if op := plan.Operations[0].Operation; op != (CloseIssueOp{Number: 1, Reason: IssueStateReasonNotPlanned}) {
+		t.Errorf("expected #1 to be closed as not planned, got %+v", op)
+	}
+}
+
+func TestPlanSyncLeavesIssuesOfMergedPullRequestsToThem(t *testing.T) {
+	todoItems := []todo.TodoItem{
+		{Text: "Merged", IssueNumber: uint64Ptr(1)},
+	}
+	githubIssues := []GitHubIssue{
+		{Number: 1, Title: "Merged", State: IssueStateOpen},
+	}
+	pullRequests := []PullRequest{{Number: 10, State: IssueStateClosed, Merged: true}}
+	links := map[uint64][]uint64{1: {10}}
+
+	// Sync shows the links before planning, and marks tasks done only after it
+	linkedItems := SynchronizePullRequests(todoItems, pullRequests, links, false)
+	plan := PlanSync(linkedItems, githubIssues, nil, SyncResolutions{})
+	updated := SynchronizePullRequests(plan.Items, pullRequests, links, true)
+
+	if len(plan.Operations) != 0 {
+		t.Errorf("expected the issue to be left to the pull request, got %+v", plan.Operations)
+	}
+	expected := []todo.TodoItem{
+		{Text: "Merged", IsChecked: true, IssueNumber: uint64Ptr(1), PullRequests: []uint64{10}},
+	}
+	if !reflect.DeepEqual(updated, expected) {
+		t.Errorf("expected items %+v, got %+v", expected, updated)
+	}
+}
@@ -29,7 +29,7 @@ func FindTitleMismatches(todoItems []todo.TodoItem, githubIssues []GitHubIssue)
 		if githubIssue.State != IssueStateOpen {
 			continue
 		}
-		if trimString(todoItem.Text) != trimString(githubIssue.Title) {
+		if normalizeTitle(todoItem.Text) != normalizeTitle(githubIssue.Title) {
 			mismatches = append(mismatches, githubIssue.Number)
 		}
 	}
@@ -78,14 +78,14 @@ func CollectPastTitles(todoItems []todo.TodoItem, githubIssues []GitHubIssue, ev
 }
 
 // MatchesPastTitle checks if a text matches any past title for a given issue number.
-// Comparison is done after trimming whitespace.
+// Titles are compared after normalizing them with normalizeTitle.
 func MatchesPastTitle(pastTitles map[uint64][]string, issueNumber uint64, text string) bool {
 	titles, exists := pastTitles[issueNumber]
 	if !exists {
 		return false
 	}
 	for _, title := range titles {
-		if trimString(title) == trimString(text) {
+		if normalizeTitle(title) == normalizeTitle(text) {
 			return true
 		}
 	}
@@ -0,0 +1,229 @@
+package history
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Command is the gh atat command a history entry records
+type Command string
+
+const (
+	CommandPush  Command = "push"
+	CommandPull  Command = "pull"
+	CommandSync  Command = "sync"
+	CommandClean Command = "clean"
+)
+
+// idLayout is the layout of entry IDs, which sort in the order the runs started
+const idLayout = "20060102T150405.000000000Z"
+
+// Entry records a run of a command that changed TODO files or GitHub issues
+type Entry struct {
+	ID      string    `json:"id"`
+	Command Command   `json:"command"`
+	Time    time.Time `json:"time"`
+	// User is the GitHub login of the user who ran the command
+	User string `json:"user,omitempty"`
+	// Repositories are the repositories the run synced with
+	Repositories []string `json:"repositories,omitempty"`
+	// Files are the TODO files the run rewrote, with their content before the run
+	Files []FileBackup `json:"files,omitempty"`
+	// Operations are the changes the run made on GitHub, in the order they were made
+	Operations []Operation `json:"operations,omitempty"`
+	// UndoneAt is when the run was undone, or nil if it was not
+	UndoneAt *time.Time `json:"undone_at,omitempty"`
+}
+
+// FileBackup is the content of a TODO file before a run rewrote it
+type FileBackup struct {
+	// Path is relative to the project root
+	Path string `json:"path"`
+	// Existed is false if the run created the file
+	Existed bool   `json:"existed"`
+	Content string `json:"content"`
+	// WrittenHash is the hex SHA-256 of the content the run wrote, to detect later edits
+	WrittenHash string `json:"written_hash"`
+}
+
+// OperationKind is the kind of change made on GitHub
+type OperationKind string
+
+const (
+	OperationCreate           OperationKind = "create"
+	OperationClose            OperationKind = "close"
+	OperationReopen           OperationKind = "reopen"
+	OperationRename           OperationKind = "rename"
+	OperationSetMilestone     OperationKind = "set_milestone"
+	OperationAddToProject     OperationKind = "add_to_project"
+	OperationSetProjectStatus OperationKind = "set_project_status"
+	OperationSetProjectDue    OperationKind = "set_project_due"
+)
+
+// Operation is a change made to an issue on GitHub
+type Operation struct {
+	Kind   OperationKind `json:"kind"`
+	Repo   string        `json:"repo"`
+	Number uint64        `json:"number"`
+	// Title is the title of a created or renamed issue, or the milestone or status set
+	Title string `json:"title,omitempty"`
+	// PreviousTitle is the title of a renamed issue before the rename
+	PreviousTitle string `json:"previous_title,omitempty"`
+	// Reason is the state reason of a closed issue
+	Reason string `json:"reason,omitempty"`
+}
+
+// NewEntry creates an empty entry for a run of command started at t
+func NewEntry(command Command, t time.Time) *Entry {
+	t = t.UTC()
+	return &Entry{
+		ID:      t.Format(idLayout),
+		Command: command,
+		Time:    t,
+	}
+}
+
+// IsEmpty reports whether the run changed nothing
+func (e *Entry) IsEmpty() bool {
+	return len(e.Files) == 0 && len(e.Operations) == 0
+}
+
+// AddFile records the content of a TODO file before the run rewrote it.
+// Only the first backup of each file is kept, as it holds the content before the run.
+func (e *Entry) AddFile(backup FileBackup) {
+	index := slices.IndexFunc(e.Files, func(f FileBackup) bool { return f.Path == backup.Path })
+	if index >= 0 {
+		e.Files[index].WrittenHash = backup.WrittenHash
+		return
+	}
+	e.Files = append(e.Files, backup)
+}
+
+// AddRepository records a repository the run synced with
+func (e *Entry) AddRepository(repo string) {
+	if repo != "" && !slices.Contains(e.Repositories, repo) {
+		e.Repositories = append(e.Repositories, repo)
+	}
+}
+
+// Record records an operation made on GitHub
+func (e *Entry) Record(op Operation) {
+	e.Operations = append(e.Operations, op)
+}
+
+// String describes the operation, such as "renamed #4 from X to Y"
+func (op Operation) String() string {
+	ref := fmt.Sprintf("#%d", op.Number)
+	switch op.Kind {
+	case OperationCreate:
+		return fmt.Sprintf("created %s %s", ref, op.Title)
+	case OperationClose:
+		if op.Reason == "not_planned" {
+			return fmt.Sprintf("closed %s as not planned", ref)
+		}
+		return fmt.Sprintf("closed %s", ref)
+	case OperationReopen:
+		return fmt.Sprintf("reopened %s", ref)
+	case OperationRename:
+		return fmt.Sprintf("renamed %s from %s to %s", ref, op.PreviousTitle, op.Title)
+	case OperationSetMilestone:
+		return fmt.Sprintf("set milestone of %s to %s", ref, op.Title)
+	case OperationAddToProject:
+		return fmt.Sprintf("added %s to project", ref)
+	case OperationSetProjectStatus:
+		return fmt.Sprintf("moved %s to %s", ref, op.Title)
+	case OperationSetProjectDue:
+		return fmt.Sprintf("set due date of %s on project to %s", ref, op.Title)
+	default:
+		return fmt.Sprintf("%s %s", op.Kind, ref)
+	}
+}
+
+// Reverse returns the operation undoing op.
+// Returns false if op can't be undone.
+func Reverse(op Operation) (Operation, bool) {
+	reversed := Operation{Repo: op.Repo, Number: op.Number}
+	switch op.Kind {
+	case OperationCreate:
+		// Issues can't be deleted with the REST API, so created issues are closed instead
+		reversed.Kind = OperationClose
+		reversed.Reason = "not_planned"
+	case OperationClose:
+		reversed.Kind = OperationReopen
+	case OperationReopen:
+		reversed.Kind = OperationClose
+		reversed.Reason = "completed"
+	case OperationRename:
+		if op.PreviousTitle == "" {
+			return Operation{}, false
+		}
+		reversed.Kind = OperationRename
+		reversed.Title = op.PreviousTitle
+		reversed.PreviousTitle = op.Title
+	default:
+		return Operation{}, false
+	}
+	return reversed, true
+}
+
+// ReverseOperations returns the operations undoing the operations of an entry, in the
+// order they must be made, and the operations that can't be undone
+func ReverseOperations(entry Entry) ([]Operation, []Operation) {
+	var reversed, irreversible []Operation
+	for i := len(entry.Operations) - 1; i >= 0; i-- {
+		op := entry.Operations[i]
+		if r, ok := Reverse(op); ok {
+			reversed = append(reversed, r)
+		} else {
+			irreversible = append(irreversible, op)
+		}
+	}
+	return reversed, irreversible
+}
+
+// Since returns the entries of runs started at or after t
+func Since(entries []Entry, t time.Time) []Entry {
+	var filtered []Entry
+	for _, entry := range entries {
+		if !entry.Time.Before(t) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// ParseSince parses the value of the --since flag relative to now.
+//
+// The value is a date (2006-01-02) in the local time zone, an RFC 3339 time, or a
+// duration before now such as "36h" or "7d".
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid value for --since: %s. Use a date (YYYY-MM-DD) or a duration such as 7d or 12h", value)
+}
+
+// LatestUndoable returns the most recent entry that was not undone.
+// entries must be in the order the runs started.
+func LatestUndoable(entries []Entry) (Entry, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].UndoneAt == nil {
+			return entries[i], true
+		}
+	}
+	return Entry{}, false
+}
@@ -0,0 +1,150 @@
+package history
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewEntry(t *testing.T) {
+	start := time.Date(2026, 10, 18, 21, 4, 5, 123, time.FixedZone("JST", 9*60*60))
+
+	entry := NewEntry(CommandPush, start)
+
+	if entry.ID != "20261018T120405.000000123Z" {
+		t.Errorf("unexpected ID %s", entry.ID)
+	}
+	if !entry.IsEmpty() {
+		t.Error("expected new entry to be empty")
+	}
+}
+
+func TestEntryAddFileKeepsFirstContent(t *testing.T) {
+	entry := NewEntry(CommandPull, time.Now())
+
+	entry.AddFile(FileBackup{Path: "TODO.md", Existed: true, Content: "before", WrittenHash: "first"})
+	entry.AddFile(FileBackup{Path: "TODO.md", Existed: true, Content: "between", WrittenHash: "second"})
+
+	expected := []FileBackup{{Path: "TODO.md", Existed: true, Content: "before", WrittenHash: "second"}}
+	if !slices.Equal(entry.Files, expected) {
+		t.Errorf("expected %+v, got %+v", expected, entry.Files)
+	}
+}
+
+func TestOperationString(t *testing.T) {
+	tests := []struct {
+		op       Operation
+		expected string
+	}{
+		{Operation{Kind: OperationCreate, Number: 12, Title: "Task"}, "created #12 Task"},
+		{Operation{Kind: OperationClose, Number: 9, Reason: "completed"}, "closed #9"},
+		{Operation{Kind: OperationClose, Number: 9, Reason: "not_planned"}, "closed #9 as not planned"},
+		{Operation{Kind: OperationRename, Number: 4, Title: "Y", PreviousTitle: "X"}, "renamed #4 from X to Y"},
+		{Operation{Kind: OperationSetProjectStatus, Number: 4, Title: "Done"}, "moved #4 to Done"},
+		{Operation{Kind: OperationSetProjectDue, Number: 4, Title: "2026-11-01"}, "set due date of #4 on project to 2026-11-01"},
+	}
+
+	for _, tt := range tests {
+		if actual := tt.op.String(); actual != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, actual)
+		}
+	}
+}
+
+func TestReverseOperations(t *testing.T) {
+	entry := Entry{Operations: []Operation{
+		{Kind: OperationCreate, Repo: "owner/repo", Number: 12, Title: "Task"},
+		{Kind: OperationClose, Repo: "owner/repo", Number: 9, Reason: "completed"},
+		{Kind: OperationRename, Repo: "owner/repo", Number: 4, Title: "Y", PreviousTitle: "X"},
+		{Kind: OperationRename, Repo: "owner/repo", Number: 5, Title: "Z"},
+		{Kind: OperationSetMilestone, Repo: "owner/repo", Number: 4, Title: "2026-11-01"},
+	}}
+
+	reversed, irreversible := ReverseOperations(entry)
+
+	expectedReversed := []Operation{
+		{Kind: OperationRename, Repo: "owner/repo", Number: 4, Title: "X", PreviousTitle: "Y"},
+		{Kind: OperationReopen, Repo: "owner/repo", Number: 9},
+		{Kind: OperationClose, Repo: "owner/repo", Number: 12, Reason: "not_planned"},
+	}
+	if !slices.Equal(reversed, expectedReversed) {
+		t.Errorf("expected %+v, got %+v", expectedReversed, reversed)
+	}
+	expectedIrreversible := []Operation{entry.Operations[4], entry.Operations[3]}
+	if !slices.Equal(irreversible, expectedIrreversible) {
+		t.Errorf("expected %+v, got %+v", expectedIrreversible, irreversible)
+	}
+}
+
+func TestLatestUndoable(t *testing.T) {
+	undoneAt := time.Now()
+	entries := []Entry{
+		{ID: "1"},
+		{ID: "2"},
+		{ID: "3", UndoneAt: &undoneAt},
+	}
+
+	entry, ok := LatestUndoable(entries)
+	if !ok || entry.ID != "2" {
+		t.Errorf("expected entry 2, got %+v, %v", entry, ok)
+	}
+
+	if _, ok := LatestUndoable(entries[2:]); ok {
+		t.Error("expected nothing to undo")
+	}
+}
+
+func TestEntryAddRepository(t *testing.T) {
+	entry := NewEntry(CommandPull, time.Now())
+
+	entry.AddRepository("owner/repo")
+	entry.AddRepository("")
+	entry.AddRepository("owner/other")
+	entry.AddRepository("owner/repo")
+
+	if !slices.Equal(entry.Repositories, []string{"owner/repo", "owner/other"}) {
+		t.Errorf("unexpected repositories %v", entry.Repositories)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{value: "2026-10-01", expected: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2026-10-17T09:00:00Z", expected: time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)},
+		{value: "7d", expected: time.Date(2026, 10, 11, 12, 0, 0, 0, time.UTC)},
+		{value: "90m", expected: time.Date(2026, 10, 18, 10, 30, 0, 0, time.UTC)},
+		{value: "-1d", wantErr: true},
+		{value: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		actual, err := ParseSince(tt.value, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !actual.Equal(tt.expected) {
+			t.Errorf("ParseSince(%q): expected %v, got %v", tt.value, tt.expected, actual)
+		}
+	}
+}
+
+func TestSince(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{ID: "1", Time: start.Add(-time.Hour)},
+		{ID: "2", Time: start},
+		{ID: "3", Time: start.Add(time.Hour)},
+	}
+
+	filtered := Since(entries, start)
+
+	if len(filtered) != 2 || filtered[0].ID != "2" || filtered[1].ID != "3" {
+		t.Errorf("unexpected entries %+v", filtered)
+	}
+}
@@ -0,0 +1,43 @@
+package history
+
+import "time"
+
+// LogRecord is a history entry as listed by `gh atat log --json`, without the
+// backed up file contents
+type LogRecord struct {
+	ID           string         `json:"id"`
+	Command      Command        `json:"command"`
+	Time         time.Time      `json:"time"`
+	User         string         `json:"user,omitempty"`
+	Repositories []string       `json:"repositories"`
+	Files        []string       `json:"files"`
+	Operations   []LogOperation `json:"operations"`
+	UndoneAt     *time.Time     `json:"undone_at,omitempty"`
+}
+
+// LogOperation is an operation with its description, such as "closed #9"
+type LogOperation struct {
+	Operation
+	Description string `json:"description"`
+}
+
+// NewLogRecord creates the log record of an entry
+func NewLogRecord(entry Entry) LogRecord {
+	record := LogRecord{
+		ID:           entry.ID,
+		Command:      entry.Command,
+		Time:         entry.Time,
+		User:         entry.User,
+		Repositories: append([]string{}, entry.Repositories...),
+		Files:        []string{},
+		Operations:   []LogOperation{},
+		UndoneAt:     entry.UndoneAt,
+	}
+	for _, file := range entry.Files {
+		record.Files = append(record.Files, file.Path)
+	}
+	for _, op := range entry.Operations {
+		record.Operations = append(record.Operations, LogOperation{Operation: op, Description: op.String()})
+	}
+	return record
+}
@@ -0,0 +1,46 @@
+package history
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewLogRecord(t *testing.T) {
+	entry := Entry{
+		ID:           "20261018T120000.000000000Z",
+		Command:      CommandPush,
+		Time:         time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		User:         "octocat",
+		Repositories: []string{"owner/repo"},
+		Files:        []FileBackup{{Path: "TODO.md", Existed: true, Content: "secret content"}},
+		Operations:   []Operation{{Kind: OperationClose, Repo: "owner/repo", Number: 9, Reason: "completed"}},
+	}
+
+	record := NewLogRecord(entry)
+
+	output, err := json.Marshal(record)
+	if err != nil {
+		t.Fatalf("failed to marshal record: %v", err)
+	}
+	expected := `{"id":"20261018T120000.000000000Z","command":"push","time":"2026-10-18T12:00:00Z","user":"octocat",` +
+		`"repositories":["owner/repo"],"files":["TODO.md"],` +
+		`"operations":[{"kind":"close","repo":"owner/repo","number":9,"reason":"completed","description":"closed #9"}]}`
+	if string(output) != expected {
+		t.Errorf("expected %s, got %s", expected, output)
+	}
+	if strings.Contains(string(output), "secret content") {
+		t.Error("expected file contents to be left out")
+	}
+}
+
+func TestNewLogRecordEmptyListsAreArrays(t *testing.T) {
+	output, err := json.Marshal(NewLogRecord(Entry{ID: "1", Command: CommandPull}))
+	if err != nil {
+		t.Fatalf("failed to marshal record: %v", err)
+	}
+	if !strings.Contains(string(output), `"repositories":[],"files":[],"operations":[]`) {
+		t.Errorf("expected empty arrays, got %s", output)
+	}
+}
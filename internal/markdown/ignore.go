@@ -0,0 +1,120 @@
+package markdown
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ignoreStartRegexp is a precompiled regexp to find the marker starting a region that is not synced
+var ignoreStartRegexp = regexp.MustCompile(`<!--\s*atat:ignore-start\s*-->`)
+
+// ignoreEndRegexp is a precompiled regexp to find the marker ending a region that is not synced
+var ignoreEndRegexp = regexp.MustCompile(`<!--\s*atat:ignore-end\s*-->`)
+
+// ignoreLineRegexp is a precompiled regexp to find the marker of a line that is not synced
+var ignoreLineRegexp = regexp.MustCompile(`<!--\s*atat:ignore\s*-->`)
+
+// atxHeadingRegexp is a precompiled regexp to match ATX headings like "## Notes"
+var atxHeadingRegexp = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+
+// fenceRegexp is a precompiled regexp to match the fence opening or closing a code block
+var fenceRegexp = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+
+// ignoredRange is a range of lines of a TODO file that is not synced
+type ignoredRange struct {
+	// start and end are the first line and the line after the last one
+	start, end int
+	// section reports whether the range is a skipped section, starting with its heading
+	section bool
+}
+
+// findIgnoredRanges returns the ranges of lines that are not synced, in order: regions
+// between ignore-start and ignore-end markers, lines with an ignore marker, and headings
+// titled as one of ignoreSections with their content and subheadings. A region without
+// an end marker lasts until the end of the file. Markers and headings in fenced code
+// blocks are not recognized.
+func findIgnoredRanges(lines []string, ignoreSections []string) []ignoredRange {
+	var ranges []ignoredRange
+	add := func(start, end int, section bool) {
+		if n := len(ranges); n > 0 && ranges[n-1].end >= start {
+			ranges[n-1].end = max(ranges[n-1].end, end)
+			return
+		}
+		ranges = append(ranges, ignoredRange{start: start, end: end, section: section})
+	}
+
+	regionStart := -1
+	sectionLevel := 0
+	fence := ""
+	for i, line := range lines {
+		if fence != "" {
+			if f := fenceRegexp.FindStringSubmatch(line); f != nil && f[1][0] == fence[0] &&
+				len(f[1]) >= len(fence) && strings.TrimSpace(line[len(f[0]):]) == "" {
+				fence = ""
+			}
+			if regionStart >= 0 || sectionLevel > 0 {
+				add(i, i+1, false)
+			}
+			continue
+		}
+		if f := fenceRegexp.FindStringSubmatch(line); f != nil {
+			fence = f[1]
+		}
+
+		switch {
+		case regionStart >= 0:
+			if ignoreEndRegexp.MatchString(line) {
+				add(regionStart, i+1, false)
+				regionStart = -1
+			}
+			continue
+		case ignoreStartRegexp.MatchString(line):
+			regionStart = i
+			continue
+		}
+
+		if heading := atxHeadingRegexp.FindStringSubmatch(line); heading != nil {
+			level := len(heading[1])
+			switch {
+			case sectionLevel > 0 && level > sectionLevel:
+			case isIgnoredSection(heading[2], ignoreSections):
+				sectionLevel = level
+				add(i, i+1, true)
+				continue
+			default:
+				sectionLevel = 0
+			}
+		}
+
+		if sectionLevel > 0 || ignoreLineRegexp.MatchString(line) {
+			add(i, i+1, false)
+		}
+	}
+	if regionStart >= 0 {
+		add(regionStart, len(lines), false)
+	}
+
+	return ranges
+}
+
+// isIgnoredSection checks if a heading title is one of the sections that are not synced,
+// ignoring case and inline formatting
+func isIgnoredSection(title string, ignoreSections []string) bool {
+	title = inlineText(title, false)
+	return slices.ContainsFunc(ignoreSections, func(section string) bool {
+		return strings.EqualFold(strings.TrimSpace(section), title)
+	})
+}
+
+// maskIgnoredRanges replaces the ignored lines of content with spaces, so they are
+// parsed as blank lines and the offsets of the other lines are kept
+func maskIgnoredRanges(lines []string, ranges []ignoredRange) string {
+	masked := slices.Clone(lines)
+	for _, r := range ranges {
+		for i := r.start; i < r.end; i++ {
+			masked[i] = strings.Repeat(" ", len(lines[i]))
+		}
+	}
+	return strings.Join(masked, "\n")
+}
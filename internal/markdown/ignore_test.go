@@ -0,0 +1,141 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+const ignoredContent = `- [ ] Top task (#1)
+<!-- atat:ignore-start -->
+- [ ] Example task
+- [ ] Example with bad due date due:2026-13-01
+<!-- atat:ignore-end -->
+- [ ] Draft <!-- atat:ignore -->
+
+## Todo
+
+- [ ] Todo task (#2)
+
+## Notes
+
+Some notes
+- [ ] Not a task
+
+### Ideas
+
+- [ ] Not a task either
+
+## Done
+
+- [x] Done task (#3)
+`
+
+func TestFindIgnoredRanges(t *testing.T) {
+	lines := strings.Split(ignoredContent, "\n")
+
+	ranges := findIgnoredRanges(lines, []string{"notes"})
+
+	expected := []ignoredRange{
+		{start: 1, end: 6},
+		{start: 11, end: 20, section: true},
+	}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("expected %+v, got %+v", expected, ranges)
+	}
+}
+
+func TestFindIgnoredRangesSkipsCodeBlocks(t *testing.T) {
+	lines := strings.Split("```\n<!-- atat:ignore-start -->\n## Notes\n```\n- [ ] Task\n", "\n")
+
+	if ranges := findIgnoredRanges(lines, []string{"Notes"}); len(ranges) != 0 {
+		t.Errorf("expected no ranges, got %+v", ranges)
+	}
+}
+
+func TestFindIgnoredRangesUnterminatedRegion(t *testing.T) {
+	lines := strings.Split("- [ ] Task\n<!-- atat:ignore-start -->\n- [ ] Example\n", "\n")
+
+	expected := []ignoredRange{{start: 1, end: 4}}
+	if ranges := findIgnoredRanges(lines, nil); !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("expected %+v, got %+v", expected, ranges)
+	}
+}
+
+func TestParseTodoMarkdownWithOptionsSkipsIgnoredContent(t *testing.T) {
+	items, err := ParseTodoMarkdownWithOptions(ignoredContent, ParseOptions{IgnoreSections: []string{"Notes"}})
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdownWithOptions failed: %v", err)
+	}
+
+	var texts []string
+	for _, item := range items {
+		texts = append(texts, item.Text)
+	}
+	expected := []string{"Top task", "Todo task", "Done task"}
+	if !reflect.DeepEqual(texts, expected) {
+		t.Errorf("expected %v, got %v", expected, texts)
+	}
+	if items[2].Section != (todo.Section{Title: "Done", Level: 2}) {
+		t.Errorf("expected the last item under Done, got %+v", items[2].Section)
+	}
+}
+
+func TestSerializeTodoMarkdownWithOptionsKeepsIgnoredContent(t *testing.T) {
+	options := ParseOptions{IgnoreSections: []string{"Notes"}}
+	items, err := ParseTodoMarkdownWithOptions(ignoredContent, options)
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdownWithOptions failed: %v", err)
+	}
+
+	actual, err := SerializeTodoMarkdownWithOptions(items, ignoredContent, options)
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+	if actual != ignoredContent {
+		t.Errorf("roundtrip failed:\noriginal:\n%s\nserialized:\n%s", ignoredContent, actual)
+	}
+}
+
+func TestSerializeTodoMarkdownWithOptionsPlacesIgnoredContent(t *testing.T) {
+	previous := "- [ ] First\n- [ ] Second\n<!-- atat:ignore -->\n\n## Notes\n\nSome notes\n\n## Todo\n\n- [ ] Third\n"
+	options := ParseOptions{IgnoreSections: []string{"Notes"}}
+	items := []todo.TodoItem{
+		{Text: "First"},
+		{Text: "Added"},
+		{Text: "Third", Section: todo.Section{Title: "Todo", Level: 2}},
+	}
+
+	actual, err := SerializeTodoMarkdownWithOptions(items, previous, options)
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+
+	expected := "- [ ] First\n<!-- atat:ignore -->\n- [ ] Added\n\n## Notes\n\nSome notes\n\n## Todo\n\n- [ ] Third\n"
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestParseTodoMarkdownSkipsQuotedTasks(t *testing.T) {
+	content := "- [ ] Task\n\n> - [ ] Quoted task\n> - [-] Quoted cancelled task\n>\n> > - [x] Nested quoted task\n"
+
+	items, err := ParseTodoMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdown failed: %v", err)
+	}
+
+	if len(items) != 1 || items[0].Text != "Task" {
+		t.Errorf("expected only the unquoted task, got %+v", items)
+	}
+
+	actual, err := SerializeTodoMarkdownWithOptions(items, content, ParseOptions{})
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+	if actual != content {
+		t.Errorf("expected quoted tasks to be kept:\n%s\ngot:\n%s", content, actual)
+	}
+}
@@ -1,10 +1,13 @@
 package markdown
 
 import (
+	"bytes"
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/toms74209200/gh-atat/internal/todo"
 	"github.com/yuin/goldmark"
@@ -14,72 +17,324 @@ import (
 	"github.com/yuin/goldmark/text"
 )
 
+// defaultSectionLevel is the heading level used for sections without a level
+const defaultSectionLevel = 2
+
+// cancelledMarker is the checkbox written for cancelled items
+const cancelledMarker = "[-]"
+
 // issueNumberRegexp is a precompiled regexp to extract issue numbers from text like "Task (#123)"
-var issueNumberRegexp = regexp.MustCompile(`\s+\(#(\d+)\)\s*$`)
+// or "Task (owner/repo#123)"
+var issueNumberRegexp = regexp.MustCompile(`\s+\((?:([A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+))?#(\d+)\)\s*$`)
+
+// pullRequestsRegexp is a precompiled regexp to extract linked pull requests from text like
+// "Task (#123) (PR #130, #131)"
+var pullRequestsRegexp = regexp.MustCompile(`\s+\(PR (#\d+(?:,\s*#\d+)*)\)\s*$`)
+
+// dueDateRegexp is a precompiled regexp to find due: tokens like "due:2026-11-01"
+var dueDateRegexp = regexp.MustCompile(`(^|\s)due:(\S+)`)
+
+// itemIDRegexp is a precompiled regexp to extract the hidden ID of a task like "<!-- atat:id=k3x9p2qa -->"
+var itemIDRegexp = regexp.MustCompile(`\s*<!--\s*atat:id=([0-9A-Za-z_-]+)\s*-->`)
+
+// checkboxRegexp is a precompiled regexp to find the checkbox at the start of a task's source
+var checkboxRegexp = regexp.MustCompile(`^\[[ xX-]\]\s*`)
+
+// listPrefixRegexp is a precompiled regexp to check the source before the checkbox of a task
+// is its indent and list marker, like "  * " or "1. "
+var listPrefixRegexp = regexp.MustCompile(`^[ \t]*([-*+]|[0-9]{1,9}[.)])[ \t]+$`)
+
+// defaultItemPrefix is the list marker written before the checkbox of new items
+const defaultItemPrefix = "- "
+
+// ParseOptions controls how the text of todo items is read
+type ParseOptions struct {
+	// KeepInlineCode keeps code spans with their backticks in the plain text of items,
+	// so they appear in issue titles
+	KeepInlineCode bool
+	// IgnoreSections are the titles of headings whose items are not synced
+	IgnoreSections []string
+}
+
+// document is a parsed TODO file
+type document struct {
+	lines []string
+	items []todo.TodoItem
+	// itemLines are the lines the items start on
+	itemLines []int
+	// itemEnds are the lines after the last line of each item
+	itemEnds []int
+	// itemPrefixes are the indent and list marker before the checkbox of each item
+	itemPrefixes []string
+	// headings are the headings of synced sections
+	headings []headingLine
+	// ignored are the ranges of lines that are not synced
+	ignored []ignoredRange
+}
+
+// headingLine is a heading and the lines it is on
+type headingLine struct {
+	// line and end are the first line of the heading and the line after its last one
+	line, end int
+	section   todo.Section
+}
 
 // ParseTodoMarkdown parses markdown content and extracts todo items.
 func ParseTodoMarkdown(content string) ([]todo.TodoItem, error) {
-	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.GFM,
-		),
-	)
+	return ParseTodoMarkdownWithOptions(content, ParseOptions{})
+}
 
-	source := []byte(content)
+// ParseTodoMarkdownWithOptions parses markdown content and extracts todo items,
+// reading their text as set in options.
+//
+// Items between <!-- atat:ignore-start --> and <!-- atat:ignore-end --> markers, on lines
+// with an <!-- atat:ignore --> marker, and under the headings in options.IgnoreSections
+// are skipped.
+func ParseTodoMarkdownWithOptions(content string, options ParseOptions) ([]todo.TodoItem, error) {
+	doc, err := parseDocument(content, options)
+	if err != nil {
+		return nil, err
+	}
+	return doc.items, nil
+}
+
+// ParseSections parses markdown content and returns the sections of its headings in
+// order, including sections without items. Headings that are not synced as set in
+// options are skipped.
+func ParseSections(content string, options ParseOptions) ([]todo.Section, error) {
+	doc, err := parseDocument(content, options)
+	if err != nil {
+		return nil, err
+	}
+	sections := make([]todo.Section, len(doc.headings))
+	for i, h := range doc.headings {
+		sections[i] = h.section
+	}
+	return sections, nil
+}
+
+// parseDocument parses markdown content into its items, their positions and the
+// ranges of lines that are not synced
+func parseDocument(content string, options ParseOptions) (document, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	ignored := findIgnoredRanges(lines, options.IgnoreSections)
+	source := []byte(maskIgnoredRanges(lines, ignored))
 	reader := text.NewReader(source)
-	doc := md.Parser().Parse(reader)
+	root := newMarkdown().Parser().Parse(reader)
+
+	doc := document{lines: lines, ignored: ignored}
+	lineOf := func(node ast.Node) int {
+		if node.Lines().Len() == 0 {
+			return 0
+		}
+		return bytes.Count(source[:node.Lines().At(0).Start], []byte("\n"))
+	}
+	endLineOf := func(node ast.Node) int {
+		if node.Lines().Len() == 0 {
+			return 0
+		}
+		return bytes.Count(source[:node.Lines().At(node.Lines().Len()-1).Start], []byte("\n")) + 1
+	}
+
+	prefixOf := func(node ast.Node) string {
+		if node.Lines().Len() == 0 {
+			return defaultItemPrefix
+		}
+		start := node.Lines().At(0).Start
+		prefix := string(source[bytes.LastIndexByte(source[:start], '\n')+1 : start])
+		if !listPrefixRegexp.MatchString(prefix) {
+			return defaultItemPrefix
+		}
+		return prefix
+	}
 
-	var items []todo.TodoItem
+	var section todo.Section
 
-	err := ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+	err := ast.Walk(root, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
 			return ast.WalkContinue, nil
 		}
 
-		taskCheckBox, ok := node.(*extast.TaskCheckBox)
-		if !ok {
-			return ast.WalkContinue, nil
+		// Tasks quoted in blockquotes are not synced
+		if _, ok := node.(*ast.Blockquote); ok {
+			return ast.WalkSkipChildren, nil
+		}
+
+		// Track the heading that following items are placed under
+		if heading, ok := node.(*ast.Heading); ok {
+			headingText, err := extractText(heading, source, false)
+			if err != nil {
+				return ast.WalkStop, err
+			}
+			section = todo.Section{
+				Title: strings.TrimSpace(headingText),
+				Level: heading.Level,
+			}
+			line := lineOf(heading)
+			end := line + 1
+			if !atxHeadingRegexp.MatchString(lines[line]) {
+				// Setext headings are followed by their underline
+				end = endLineOf(heading) + 1
+			}
+			doc.headings = append(doc.headings, headingLine{line: line, end: end, section: section})
+			return ast.WalkSkipChildren, nil
 		}
 
-		isChecked := taskCheckBox.IsChecked
+		var isChecked, isCancelled bool
+		var block ast.Node
 
-		// Get the parent ListItem
-		listItem := taskCheckBox.Parent()
-		if listItem == nil {
+		switch v := node.(type) {
+		case *extast.TaskCheckBox:
+			isChecked = v.IsChecked
+			block = v.Parent()
+		case *ast.TextBlock, *ast.Paragraph:
+			// "[-]" is not a GFM task checkbox, so cancelled items are found by their text
+			if !isCancelledListItem(node, source) {
+				return ast.WalkContinue, nil
+			}
+			isChecked = true
+			isCancelled = true
+			block = node
+		default:
+			return ast.WalkContinue, nil
+		}
+
+		if block == nil {
 			return ast.WalkContinue, nil
 		}
 
 		// Extract text from the list item
-		extractedText, err := extractText(listItem, source)
+		extractedText, err := extractText(block, source, options.KeepInlineCode)
 		if err != nil {
 			return ast.WalkStop, err
 		}
-		extractedText = strings.TrimSpace(extractedText)
+		extractedText = strings.TrimSpace(strings.TrimPrefix(extractedText, cancelledMarker))
 
 		if extractedText == "" {
 			return ast.WalkContinue, nil
 		}
 
+		// Extract linked pull requests, which follow the issue number
+		cleanText, pullRequests := extractPullRequests(extractedText)
+
 		// Extract issue number if present
-		cleanText, issueNumber := extractIssueNumber(extractedText)
+		cleanText, issueNumber, externalIssue := extractIssueNumber(cleanText)
+
+		// Extract due date if present
+		cleanText, dueDate := extractDueDate(cleanText)
+
+		// Tasks with nothing but an issue number, pull requests or a due date are not synced
+		if cleanText == "" {
+			return ast.WalkContinue, nil
+		}
+
+		// Keep the inline markdown of the text, without the parts extracted above
+		markdownText := strings.TrimSpace(checkboxRegexp.ReplaceAllString(extractSource(block, source), ""))
+		markdownText, id := extractID(markdownText)
+		markdownText, _ = extractPullRequests(markdownText)
+		markdownText, _, _ = extractIssueNumber(markdownText)
+		// A due date within the text is kept in place, and one at the end is written again
+		if loc := dueDateIndex(markdownText); loc == nil || loc[1] == len(markdownText) {
+			markdownText, _ = extractDueDate(markdownText)
+		}
+		if markdownText == cleanText {
+			markdownText = ""
+		}
+
+		// Items struck through as a whole are cancelled
+		if !isCancelled {
+			struck, err := isStruckThrough(block, source, cleanText, options.KeepInlineCode)
+			if err != nil {
+				return ast.WalkStop, err
+			}
+			if struck {
+				isChecked = true
+				isCancelled = true
+				// The strikethrough is written as the cancelled checkbox instead
+				markdownText = ""
+			}
+		}
 
-		items = append(items, todo.TodoItem{
-			Text:        cleanText,
-			IsChecked:   isChecked,
-			IssueNumber: issueNumber,
+		doc.itemLines = append(doc.itemLines, lineOf(block))
+		doc.itemEnds = append(doc.itemEnds, endLineOf(block))
+		doc.itemPrefixes = append(doc.itemPrefixes, prefixOf(block))
+		doc.items = append(doc.items, todo.TodoItem{
+			Text:          cleanText,
+			Markdown:      markdownText,
+			IsChecked:     isChecked,
+			IsCancelled:   isCancelled,
+			IssueNumber:   issueNumber,
+			ExternalIssue: externalIssue,
+			DueDate:       dueDate,
+			Section:       section,
+			PullRequests:  pullRequests,
+			ID:            id,
 		})
 
 		return ast.WalkContinue, nil
 	})
 	if err != nil {
-		return nil, err
+		return document{}, err
+	}
+
+	return doc, nil
+}
+
+// isCancelledListItem checks if node is the first block of a list item starting with "[-]"
+func isCancelledListItem(node ast.Node, source []byte) bool {
+	parent := node.Parent()
+	if _, ok := parent.(*ast.ListItem); !ok || parent.FirstChild() != node {
+		return false
+	}
+	if _, ok := node.FirstChild().(*extast.TaskCheckBox); ok {
+		return false
+	}
+
+	text, err := extractText(node, source, false)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(text, cancelledMarker+" ")
+}
+
+// isStruckThrough checks if the struck through text in node equals the item text
+func isStruckThrough(node ast.Node, source []byte, itemText string, keepCode bool) (bool, error) {
+	var struck strings.Builder
+
+	err := ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if strikethrough, ok := n.(*extast.Strikethrough); ok {
+			text, err := extractText(strikethrough, source, keepCode)
+			if err != nil {
+				return ast.WalkStop, err
+			}
+			struck.WriteString(text)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return false, err
 	}
 
-	return items, nil
+	struckText := strings.TrimSpace(struck.String())
+	return struckText != "" && struckText == itemText, nil
+}
+
+// newMarkdown returns the markdown parser for TODO files
+func newMarkdown() goldmark.Markdown {
+	return goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+		),
+	)
 }
 
 // extractText extracts plain text from an AST node, handling formatting.
-func extractText(node ast.Node, source []byte) (string, error) {
+// Code spans keep their backticks if keepCode is true.
+func extractText(node ast.Node, source []byte, keepCode bool) (string, error) {
 	var text strings.Builder
 
 	err := ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -92,12 +347,17 @@ func extractText(node ast.Node, source []byte) (string, error) {
 			text.Write(v.Segment.Value(source))
 		case *ast.CodeSpan:
 			// Extract text from code spans
-			child := v.FirstChild()
-			if child != nil {
+			var code strings.Builder
+			for child := v.FirstChild(); child != nil; child = child.NextSibling() {
 				if txtNode, ok := child.(*ast.Text); ok {
-					text.Write(txtNode.Segment.Value(source))
+					code.Write(txtNode.Segment.Value(source))
 				}
 			}
+			if keepCode {
+				text.WriteString(codeSpan(code.String()))
+			} else {
+				text.WriteString(code.String())
+			}
 			// Skip children as we already processed them
 			return ast.WalkSkipChildren, nil
 		case *extast.TaskCheckBox:
@@ -114,39 +374,390 @@ func extractText(node ast.Node, source []byte) (string, error) {
 	return text.String(), nil
 }
 
-// extractIssueNumber extracts issue number from text like "Task (#123)"
-func extractIssueNumber(text string) (string, *uint64) {
+// codeSpan writes code as a code span, with more backticks than code contains in a row
+func codeSpan(code string) string {
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+	return fence + code + fence
+}
+
+// extractSource returns the markdown source of a block, with its lines joined by spaces
+func extractSource(node ast.Node, source []byte) string {
+	lines := node.Lines()
+	parts := make([]string, 0, lines.Len())
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		parts = append(parts, strings.TrimSpace(string(line.Value(source))))
+	}
+	return strings.Join(parts, " ")
+}
+
+// inlineText returns the plain text of inline markdown, as read from a task.
+// Code spans keep their backticks if keepCode is true.
+func inlineText(markdown string, keepCode bool) string {
+	source := []byte(markdown)
+	doc := newMarkdown().Parser().Parse(text.NewReader(source))
+	plain, err := extractText(doc, source, keepCode)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(plain)
+}
+
+// itemSource returns the text written for an item: its inline markdown if Text is still
+// the plain form of it without its due date, or Text otherwise. inPlace reports whether
+// the text holds the due date token of the item, kept in place within the text.
+func itemSource(item todo.TodoItem) (text string, inPlace bool) {
+	if item.Markdown == "" {
+		return item.Text, false
+	}
+	if markdownText, dueDate := extractDueDate(item.Markdown); dueDate != nil && isPlainForm(markdownText, item.Text) {
+		return item.Markdown, true
+	}
+	if isPlainForm(item.Markdown, item.Text) {
+		return item.Markdown, false
+	}
+	return item.Text, false
+}
+
+// isPlainForm reports whether text is the plain form of the inline markdown
+func isPlainForm(markdown, text string) bool {
+	return inlineText(markdown, false) == text || inlineText(markdown, true) == text
+}
+
+// extractIssueNumber extracts issue number from text like "Task (#123)".
+// References to another repository like "Task (owner/repo#123)" are returned as an IssueRef.
+func extractIssueNumber(text string) (string, *uint64, *todo.IssueRef) {
 	// Match " (#digits)" at the end of the string using the precompiled regexp
 	matches := issueNumberRegexp.FindStringSubmatch(text)
 
-	if len(matches) > 1 {
-		if num, err := strconv.ParseUint(matches[1], 10, 64); err == nil {
+	if len(matches) > 2 {
+		if num, err := strconv.ParseUint(matches[2], 10, 64); err == nil {
 			cleanText := issueNumberRegexp.ReplaceAllString(text, "")
 			cleanText = strings.TrimSpace(cleanText)
-			return cleanText, &num
+			if matches[1] != "" {
+				return cleanText, nil, &todo.IssueRef{Repo: matches[1], Number: num}
+			}
+			return cleanText, &num, nil
 		}
 	}
 
-	return text, nil
+	return text, nil, nil
+}
+
+// extractID extracts the hidden ID of a task like "Task (#123) <!-- atat:id=k3x9p2qa -->"
+func extractID(text string) (string, string) {
+	matches := itemIDRegexp.FindStringSubmatch(text)
+	if matches == nil {
+		return text, ""
+	}
+	return strings.TrimSpace(itemIDRegexp.ReplaceAllString(text, " ")), matches[1]
+}
+
+// extractPullRequests extracts linked pull request numbers from text like "Task (#123) (PR #130)"
+func extractPullRequests(text string) (string, []uint64) {
+	matches := pullRequestsRegexp.FindStringSubmatch(text)
+	if len(matches) < 2 {
+		return text, nil
+	}
+
+	var numbers []uint64
+	for _, ref := range strings.Split(matches[1], ",") {
+		num, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(ref), "#"), 10, 64)
+		if err != nil {
+			return text, nil
+		}
+		numbers = append(numbers, num)
+	}
+
+	return strings.TrimSpace(pullRequestsRegexp.ReplaceAllString(text, "")), numbers
+}
+
+// extractDueDate extracts a due date from text like "Task due:2026-11-01".
+// Only the token found by dueDateIndex is extracted, and other due: tokens are kept as text.
+func extractDueDate(text string) (string, *time.Time) {
+	loc := dueDateIndex(text)
+	if loc == nil {
+		return text, nil
+	}
+
+	dueDate, _ := time.Parse(todo.DueDateLayout, text[loc[4]:loc[5]])
+	cleanText := strings.Join(strings.Fields(text[:loc[0]]+" "+text[loc[1]:]), " ")
+	return cleanText, &dueDate
+}
+
+// dueDateIndex returns the submatch indices of the due date token of text: the last
+// due: token with a valid date. Returns nil if text has none.
+// Tokens with an invalid date, like "due:tomorrow", are plain text.
+func dueDateIndex(text string) []int {
+	matches := dueDateRegexp.FindAllStringSubmatchIndex(text, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		if _, err := time.Parse(todo.DueDateLayout, text[matches[i][4]:matches[i][5]]); err == nil {
+			return matches[i]
+		}
+	}
+	return nil
 }
 
 // SerializeTodoMarkdown converts todo items to markdown format.
+// A heading is written before an item whenever its section differs from the
+// section of the preceding items. Items without a section that follow a heading
+// stay under that heading.
 func SerializeTodoMarkdown(items []todo.TodoItem) string {
+	return serializeItems(items, nil, nil, nil)
+}
+
+// SerializeTodoMarkdownWithOptions converts todo items to markdown format like
+// SerializeTodoMarkdown, and keeps the content of previous, the content the file had,
+// other than its items: content that is not synced as set by its markers and options,
+// prose, other lists and blocks, and headings of sections without items.
+//
+// Sections without items, including skipped ones, are written before the heading that
+// followed them, or at the end, and items added to them are written under them. Other
+// content is written after the nearest item that preceded it in its section and is still
+// there, or at the start of its section. Items stay under the heading they were under, and
+// items added to a section are written under the heading of the item before them in the
+// section, or the first heading of it.
+//
+// Items keep the indent and list marker they had in previous, and the file keeps its
+// CRLF line endings.
+// Returns an error if previous can't be parsed.
+func SerializeTodoMarkdownWithOptions(items []todo.TodoItem, previous string, options ParseOptions) (string, error) {
+	doc, err := parseDocument(previous, options)
+	if err != nil {
+		return "", err
+	}
+	result := serializeItems(items, keptBlocks(doc), keptHeadings(doc), keptItems(doc))
+	if strings.Contains(previous, "\r\n") {
+		result = strings.ReplaceAll(result, "\n", "\r\n")
+	}
+	return result, nil
+}
+
+// serializeItems converts todo items to markdown format, placing the kept blocks
+// among them. Items are written under the heading of kept they were under, the heading
+// of the item before them in their section, or the first of headings of their section,
+// and with the prefix they had in kept.
+func serializeItems(items []todo.TodoItem, blocks []keptBlock, headings []keptHeading, kept []keptItem) string {
 	var builder strings.Builder
+	current := -1
+
+	// Find the heading of the section each item is in. Sections without a heading get
+	// an index after the headings, so their heading is written like the others.
+	sections := make([]int, len(items))
+	prefixes := make([]string, len(items))
+	used := make([]bool, len(kept))
+	newSections := make(map[string]int)
+	for i, item := range items {
+		sections[i] = -1
+		prefixes[i] = defaultItemPrefix
+		if k := slices.IndexFunc(kept, func(k keptItem) bool { return sameItem(k.item, item) }); k >= 0 {
+			prefixes[i] = kept[k].prefix
+		}
+		for k := range kept {
+			if !used[k] && kept[k].item.Section == item.Section && sameItem(kept[k].item, item) {
+				used[k] = true
+				sections[i] = kept[k].heading
+				prefixes[i] = kept[k].prefix
+				break
+			}
+		}
+		if sections[i] >= 0 || item.Section.Title == "" {
+			continue
+		}
+		if i > 0 && items[i-1].Section == item.Section && sections[i-1] >= 0 {
+			sections[i] = sections[i-1]
+			continue
+		}
+		if k := slices.IndexFunc(headings, func(h keptHeading) bool { return h.section.Title == item.Section.Title }); k >= 0 {
+			sections[i] = k
+			continue
+		}
+		if _, ok := newSections[item.Section.Title]; !ok {
+			newSections[item.Section.Title] = len(headings) + len(newSections)
+		}
+		sections[i] = newSections[item.Section.Title]
+	}
+
+	// Find the item each block follows
+	anchors := make([]int, len(blocks))
+	for j, block := range blocks {
+		anchors[j] = -1
+		if block.isSection {
+			continue
+		}
+		for _, preceding := range block.preceding {
+			for i, item := range items {
+				if sections[i] == block.section && sameItem(item, preceding) {
+					anchors[j] = i
+					break
+				}
+			}
+			if anchors[j] >= 0 {
+				break
+			}
+		}
+	}
+
+	// Items of sections that had no items are written under their heading
+	deferred := make([][]int, len(blocks))
+	isDeferred := make([]bool, len(items))
+	for j, block := range blocks {
+		if block.heading < 0 {
+			continue
+		}
+		for i := range items {
+			if sections[i] == block.heading {
+				deferred[j] = append(deferred[j], i)
+				isDeferred[i] = true
+			}
+		}
+	}
+
+	// separate ends the content written so far with a blank line
+	separate := func() {
+		if builder.Len() > 0 && !strings.HasSuffix(builder.String(), "\n\n") {
+			builder.WriteString("\n")
+		}
+	}
+
+	placed := make([]bool, len(blocks))
+	var writeBlock func(j int)
+	var writeItem func(i int)
+
+	// writeSectionsBefore writes the section blocks that were followed by section
+	writeSectionsBefore := func(section int) {
+		for j, block := range blocks {
+			if block.isSection && block.next >= 0 && block.next == section {
+				writeBlock(j)
+			}
+		}
+	}
+
+	writeBlock = func(j int) {
+		if placed[j] {
+			return
+		}
+		placed[j] = true
+		block := blocks[j]
+		if block.heading >= 0 {
+			writeSectionsBefore(block.heading)
+		}
+		if block.isSection {
+			separate()
+		}
+		source := block.source
+		if builder.Len() == 0 || strings.HasSuffix(builder.String(), "\n\n") {
+			// The blank lines kept before a removed item are not repeated
+			source = trimLeadingBlankLines(source)
+		}
+		builder.WriteString(source + "\n")
+		if len(deferred[j]) > 0 {
+			separate()
+			current = block.heading
+			for _, i := range deferred[j] {
+				writeItem(i)
+			}
+		}
+	}
 
-	for _, item := range items {
+	writeItem = func(i int) {
+		item := items[i]
 		checkbox := "[ ]"
-		if item.IsChecked {
+		if item.IsCancelled {
+			checkbox = cancelledMarker
+		} else if item.IsChecked {
 			checkbox = "[x]"
 		}
 
-		text := item.Text
+		text, inPlace := itemSource(item)
+		switch {
+		case inPlace && item.DueDate != nil:
+			loc := dueDateIndex(text)
+			text = text[:loc[3]] + "due:" + item.DueDate.Format(todo.DueDateLayout) + text[loc[1]:]
+		case inPlace:
+			loc := dueDateIndex(text)
+			text = strings.TrimSpace(text[:loc[0]] + text[loc[1]:])
+		case item.DueDate != nil:
+			text = fmt.Sprintf("%s due:%s", text, item.DueDate.Format(todo.DueDateLayout))
+		}
 		if item.IssueNumber != nil {
-			text = fmt.Sprintf("%s (#%d)", item.Text, *item.IssueNumber)
+			text = fmt.Sprintf("%s (#%d)", text, *item.IssueNumber)
+		} else if item.ExternalIssue != nil {
+			text = fmt.Sprintf("%s (%s#%d)", text, item.ExternalIssue.Repo, item.ExternalIssue.Number)
+		}
+		if len(item.PullRequests) > 0 {
+			refs := make([]string, len(item.PullRequests))
+			for i, number := range item.PullRequests {
+				refs[i] = fmt.Sprintf("#%d", number)
+			}
+			text = fmt.Sprintf("%s (PR %s)", text, strings.Join(refs, ", "))
 		}
+		if item.ID != "" {
+			text = fmt.Sprintf("%s <!-- atat:id=%s -->", text, item.ID)
+		}
+
+		fmt.Fprintf(&builder, "%s%s %s\n", prefixes[i], checkbox, text)
 
-		fmt.Fprintf(&builder, "- %s %s\n", checkbox, text)
+		// Blocks following this item
+		for j := range blocks {
+			if anchors[j] == i {
+				writeBlock(j)
+			}
+		}
+	}
+
+	// Blocks at the start of the file
+	for j, block := range blocks {
+		if anchors[j] < 0 && !block.isSection && block.section < 0 {
+			writeBlock(j)
+		}
 	}
 
-	return builder.String()
+	for i, item := range items {
+		if isDeferred[i] {
+			continue
+		}
+		if section := sections[i]; section >= 0 && section != current {
+			writeSectionsBefore(section)
+
+			separate()
+			if section < len(headings) && headings[section].section == item.Section {
+				builder.WriteString(headings[section].source + "\n\n")
+			} else {
+				level := item.Section.Level
+				if level == 0 {
+					level = defaultSectionLevel
+				}
+				fmt.Fprintf(&builder, "%s %s\n\n", strings.Repeat("#", level), item.Section.Title)
+			}
+			current = section
+
+			// Blocks at the start of this section
+			for j, block := range blocks {
+				if anchors[j] < 0 && !block.isSection && block.section == section {
+					writeBlock(j)
+				}
+			}
+		}
+
+		writeItem(i)
+	}
+
+	// Blocks whose place is gone
+	for j := range blocks {
+		writeBlock(j)
+	}
+
+	// Blank lines kept before removed items are not left at the end
+	result := builder.String()
+	if strings.HasSuffix(result, "\n\n") {
+		result = strings.TrimRight(result, "\n") + "\n"
+	}
+	return result
 }
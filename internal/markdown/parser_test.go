@@ -1,7 +1,9 @@
 package markdown
 
 import (
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/toms74209200/gh-atat/internal/todo"
 )
@@ -9,6 +11,7 @@ import (
 func TestParseTodoMarkdown(t *testing.T) {
 	num123 := uint64(123)
 	num456 := uint64(456)
+	due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name     string
@@ -34,11 +37,11 @@ func TestParseTodoMarkdown(t *testing.T) {
 - [x] [link](url) text
 - [ ] ~~strikethrough~~ text`,
 			expected: []todo.TodoItem{
-				{Text: "bold text", IsChecked: false, IssueNumber: nil},
-				{Text: "italic text", IsChecked: true, IssueNumber: nil},
-				{Text: "code text", IsChecked: false, IssueNumber: nil},
-				{Text: "link text", IsChecked: true, IssueNumber: nil},
-				{Text: "strikethrough text", IsChecked: false, IssueNumber: nil},
+				{Text: "bold text", Markdown: "**bold** text", IsChecked: false, IssueNumber: nil},
+				{Text: "italic text", Markdown: "*italic* text", IsChecked: true, IssueNumber: nil},
+				{Text: "code text", Markdown: "`code` text", IsChecked: false, IssueNumber: nil},
+				{Text: "link text", Markdown: "[link](url) text", IsChecked: true, IssueNumber: nil},
+				{Text: "strikethrough text", Markdown: "~~strikethrough~~ text", IsChecked: false, IssueNumber: nil},
 			},
 		},
 		{
@@ -52,6 +55,50 @@ func TestParseTodoMarkdown(t *testing.T) {
 				{Text: "Task without issue", IsChecked: false, IssueNumber: nil},
 			},
 		},
+		{
+			name: "due dates extracted from text",
+			input: `- [ ] Task with due date due:2026-11-01 (#123)
+- [ ] Ship due:2026-11-01 the release
+- [ ] Payment due: Friday`,
+			expected: []todo.TodoItem{
+				{Text: "Task with due date", IsChecked: false, IssueNumber: &num123, DueDate: &due},
+				{Text: "Ship the release", Markdown: "Ship due:2026-11-01 the release", IsChecked: false, IssueNumber: nil, DueDate: &due},
+				{Text: "Payment due: Friday", IsChecked: false, IssueNumber: nil},
+			},
+		},
+		{
+			name: "linked pull requests after issue number",
+			input: `- [ ] Task with pull request (#123) (PR #130)
+- [ ] Task with pull requests (#456) (PR #131, #132)
+- [ ] Mentions (PR #1) in text`,
+			expected: []todo.TodoItem{
+				{Text: "Task with pull request", IsChecked: false, IssueNumber: &num123, PullRequests: []uint64{130}},
+				{Text: "Task with pull requests", IsChecked: false, IssueNumber: &num456, PullRequests: []uint64{131, 132}},
+				{Text: "Mentions (PR #1) in text", IsChecked: false, IssueNumber: nil},
+			},
+		},
+		{
+			name: "issue in another repository",
+			input: `- [ ] Transferred task (owner/other-repo#123)
+- [ ] Not a reference (owner#123)`,
+			expected: []todo.TodoItem{
+				{Text: "Transferred task", IsChecked: false, IssueNumber: nil, ExternalIssue: &todo.IssueRef{Repo: "owner/other-repo", Number: 123}},
+				{Text: "Not a reference (owner#123)", IsChecked: false, IssueNumber: nil},
+			},
+		},
+		{
+			name: "cancelled items",
+			input: `- [-] Dropped task (#123)
+- [x] ~~Struck task~~ (#456)
+- [ ] ~~Struck~~ partially
+- [-]
+- Plain bullet [-] text`,
+			expected: []todo.TodoItem{
+				{Text: "Dropped task", IsChecked: true, IsCancelled: true, IssueNumber: &num123},
+				{Text: "Struck task", IsChecked: true, IsCancelled: true, IssueNumber: &num456},
+				{Text: "Struck partially", Markdown: "~~Struck~~ partially", IsChecked: false, IssueNumber: nil},
+			},
+		},
 		{
 			name: "nested checklist flat structure",
 			input: `- [ ] Main task
@@ -79,10 +126,10 @@ func TestParseTodoMarkdown(t *testing.T) {
 - [x] Another completed
 - [ ] Another pending`,
 			expected: []todo.TodoItem{
-				{Text: "Completed task", IsChecked: true, IssueNumber: nil},
-				{Text: "Pending task", IsChecked: false, IssueNumber: nil},
-				{Text: "Another completed", IsChecked: true, IssueNumber: nil},
-				{Text: "Another pending", IsChecked: false, IssueNumber: nil},
+				{Text: "Completed task", IsChecked: true, IssueNumber: nil, Section: todo.Section{Title: "Section 1", Level: 1}},
+				{Text: "Pending task", IsChecked: false, IssueNumber: nil, Section: todo.Section{Title: "Section 1", Level: 1}},
+				{Text: "Another completed", IsChecked: true, IssueNumber: nil, Section: todo.Section{Title: "Subsection", Level: 2}},
+				{Text: "Another pending", IsChecked: false, IssueNumber: nil, Section: todo.Section{Title: "Subsection", Level: 2}},
 			},
 		},
 		{
@@ -101,9 +148,9 @@ Regular text.
 
 - [ ] Checklist item 3`,
 			expected: []todo.TodoItem{
-				{Text: "Checklist item 1", IsChecked: false, IssueNumber: nil},
-				{Text: "Checklist item 2", IsChecked: true, IssueNumber: nil},
-				{Text: "Checklist item 3", IsChecked: false, IssueNumber: nil},
+				{Text: "Checklist item 1", IsChecked: false, IssueNumber: nil, Section: todo.Section{Title: "Title", Level: 1}},
+				{Text: "Checklist item 2", IsChecked: true, IssueNumber: nil, Section: todo.Section{Title: "Title", Level: 1}},
+				{Text: "Checklist item 3", IsChecked: false, IssueNumber: nil, Section: todo.Section{Title: "Title", Level: 1}},
 			},
 		},
 		{
@@ -165,6 +212,10 @@ Regular text.
 					t.Errorf("item[%d].Text: expected %q, got %q", i, expected.Text, actual.Text)
 				}
 
+				if actual.Markdown != expected.Markdown {
+					t.Errorf("item[%d].Markdown: expected %q, got %q", i, expected.Markdown, actual.Markdown)
+				}
+
 				if actual.IsChecked != expected.IsChecked {
 					t.Errorf("item[%d].IsChecked: expected %v, got %v", i, expected.IsChecked, actual.IsChecked)
 				}
@@ -174,14 +225,202 @@ Regular text.
 				} else if actual.IssueNumber != nil && *actual.IssueNumber != *expected.IssueNumber {
 					t.Errorf("item[%d].IssueNumber: expected %d, got %d", i, *expected.IssueNumber, *actual.IssueNumber)
 				}
+
+				if (actual.ExternalIssue == nil) != (expected.ExternalIssue == nil) {
+					t.Errorf("item[%d].ExternalIssue: expected %v, got %v", i, expected.ExternalIssue, actual.ExternalIssue)
+				} else if actual.ExternalIssue != nil && *actual.ExternalIssue != *expected.ExternalIssue {
+					t.Errorf("item[%d].ExternalIssue: expected %+v, got %+v", i, *expected.ExternalIssue, *actual.ExternalIssue)
+				}
+
+				if actual.IsCancelled != expected.IsCancelled {
+					t.Errorf("item[%d].IsCancelled: expected %v, got %v", i, expected.IsCancelled, actual.IsCancelled)
+				}
+
+				if actual.Section != expected.Section {
+					t.Errorf("item[%d].Section: expected %+v, got %+v", i, expected.Section, actual.Section)
+				}
+
+				if !slices.Equal(actual.PullRequests, expected.PullRequests) {
+					t.Errorf("item[%d].PullRequests: expected %v, got %v", i, expected.PullRequests, actual.PullRequests)
+				}
+
+				if (actual.DueDate == nil) != (expected.DueDate == nil) {
+					t.Errorf("item[%d].DueDate: expected %v, got %v", i, expected.DueDate, actual.DueDate)
+				} else if actual.DueDate != nil && !actual.DueDate.Equal(*expected.DueDate) {
+					t.Errorf("item[%d].DueDate: expected %v, got %v", i, *expected.DueDate, *actual.DueDate)
+				}
 			}
 		})
 	}
 }
 
+func TestParseTodoMarkdownKeepsInlineMarkdown(t *testing.T) {
+	input := "- [ ] Fix [login](https://example.com/login) **bug** due:2026-11-01 (#123) (PR #130)\n" +
+		"- [-] *Dropped* task\n" +
+		"- [x] ~~Struck task~~\n"
+
+	items, err := ParseTodoMarkdown(input)
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdown failed: %v", err)
+	}
+
+	expected := []struct{ text, markdown string }{
+		{"Fix login bug", "Fix [login](https://example.com/login) **bug**"},
+		{"Dropped task", "*Dropped* task"},
+		{"Struck task", ""},
+	}
+	for i, e := range expected {
+		if items[i].Text != e.text || items[i].Markdown != e.markdown {
+			t.Errorf("item[%d]: expected (%q, %q), got (%q, %q)", i, e.text, e.markdown, items[i].Text, items[i].Markdown)
+		}
+	}
+}
+
+func TestParseTodoMarkdownWithOptionsKeepInlineCode(t *testing.T) {
+	input := "- [ ] Fix `parseConfig` crash\n- [ ] Escape ``a`b`` in [docs](url)\n"
+
+	items, err := ParseTodoMarkdownWithOptions(input, ParseOptions{KeepInlineCode: true})
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdownWithOptions failed: %v", err)
+	}
+
+	if items[0].Text != "Fix `parseConfig` crash" || items[0].Markdown != "" {
+		t.Errorf("item[0]: expected code kept without markdown, got (%q, %q)", items[0].Text, items[0].Markdown)
+	}
+	if items[1].Text != "Escape ``a`b`` in docs" {
+		t.Errorf("item[1]: expected %q, got %q", "Escape ``a`b`` in docs", items[1].Text)
+	}
+}
+
+func TestParseTodoMarkdownExtractsID(t *testing.T) {
+	input := "- [ ] Fix **login** bug (#123) <!-- atat:id=k3x9p2qa -->\n- [ ] Task <!--atat:id=ab12-->\n- [ ] No ID\n"
+
+	items, err := ParseTodoMarkdown(input)
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdown failed: %v", err)
+	}
+
+	expected := []struct{ text, markdown, id string }{
+		{"Fix login bug", "Fix **login** bug", "k3x9p2qa"},
+		{"Task", "", "ab12"},
+		{"No ID", "", ""},
+	}
+	for i, e := range expected {
+		if items[i].Text != e.text || items[i].Markdown != e.markdown || items[i].ID != e.id {
+			t.Errorf("item[%d]: expected (%q, %q, %q), got (%q, %q, %q)", i, e.text, e.markdown, e.id, items[i].Text, items[i].Markdown, items[i].ID)
+		}
+	}
+	if items[0].IssueNumber == nil || *items[0].IssueNumber != 123 {
+		t.Errorf("item[0]: expected issue #123, got %v", items[0].IssueNumber)
+	}
+}
+
+func TestParseTodoMarkdownInvalidDueDate(t *testing.T) {
+	nextDay := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input    string
+		expected string
+		dueDate  *time.Time
+	}{
+		{"- [ ] Task due:2026-13-01\n", "Task due:2026-13-01", nil},
+		{"- [ ] Task due:tomorrow\n", "Task due:tomorrow", nil},
+		{"- [ ] Task due:2026-11-01 due:2026-11-02\n", "Task due:2026-11-01", &nextDay},
+		{"- [ ] Task due:2026-11-02 due:tomorrow\n", "Task due:tomorrow", &nextDay},
+	}
+
+	for _, tt := range tests {
+		items, err := ParseTodoMarkdown(tt.input)
+		if err != nil {
+			t.Fatalf("%q: ParseTodoMarkdown failed: %v", tt.input, err)
+		}
+		if len(items) != 1 || items[0].Text != tt.expected {
+			t.Fatalf("%q: expected text %q, got %+v", tt.input, tt.expected, items)
+		}
+		if (tt.dueDate == nil) != (items[0].DueDate == nil) || (tt.dueDate != nil && !tt.dueDate.Equal(*items[0].DueDate)) {
+			t.Errorf("%q: expected due date %v, got %v", tt.input, tt.dueDate, items[0].DueDate)
+		}
+		actual, err := SerializeTodoMarkdownWithOptions(items, tt.input, ParseOptions{})
+		if err != nil {
+			t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+		}
+		if actual != tt.input {
+			t.Errorf("roundtrip failed:\noriginal:\n%s\nserialized:\n%s", tt.input, actual)
+		}
+	}
+
+	// A due date set on a task keeps the invalid token as text
+	items, err := ParseTodoMarkdown("- [ ] Task due:tomorrow\n")
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdown failed: %v", err)
+	}
+	items[0].DueDate = &nextDay
+	expected := "- [ ] Task due:tomorrow due:2026-11-02\n"
+	if actual := SerializeTodoMarkdown(items); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestParseTodoMarkdownSkipsTasksWithoutText(t *testing.T) {
+	content := "- [ ] due:2026-01-01\n- [ ] due:2026-01-01 (#12)\n- [ ] Task\n"
+
+	items, err := ParseTodoMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdown failed: %v", err)
+	}
+
+	if len(items) != 1 || items[0].Text != "Task" {
+		t.Fatalf("expected only the task with text, got %+v", items)
+	}
+	actual, err := SerializeTodoMarkdownWithOptions(items, content, ParseOptions{})
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+	if actual != content {
+		t.Errorf("expected tasks without text to be kept:\n%s\ngot:\n%s", content, actual)
+	}
+}
+
+func TestSerializeTodoMarkdownKeepsDueDatePosition(t *testing.T) {
+	content := "- [ ] Ship due:2026-11-01 the release (#1)\n"
+	items, err := ParseTodoMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdown failed: %v", err)
+	}
+
+	actual, err := SerializeTodoMarkdownWithOptions(items, content, ParseOptions{})
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+	if actual != content {
+		t.Errorf("roundtrip failed:\noriginal:\n%s\nserialized:\n%s", content, actual)
+	}
+
+	changed := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	items[0].DueDate = &changed
+	expected := "- [ ] Ship due:2026-12-01 the release (#1)\n"
+	actual, err = SerializeTodoMarkdownWithOptions(items, content, ParseOptions{})
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	items[0].DueDate = nil
+	expected = "- [ ] Ship the release (#1)\n"
+	actual, err = SerializeTodoMarkdownWithOptions(items, content, ParseOptions{})
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
 func TestSerializeTodoMarkdown(t *testing.T) {
 	num123 := uint64(123)
 	num456 := uint64(456)
+	due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name     string
@@ -198,6 +437,60 @@ func TestSerializeTodoMarkdown(t *testing.T) {
 			},
 			expected: "- [ ] Unchecked task\n- [x] Checked task\n- [ ] Task with issue (#123)\n- [x] Checked task with issue (#456)\n",
 		},
+		{
+			name: "serialize due date before issue number",
+			input: []todo.TodoItem{
+				{Text: "Task with due date", IsChecked: false, IssueNumber: &num123, DueDate: &due},
+			},
+			expected: "- [ ] Task with due date due:2026-11-01 (#123)\n",
+		},
+		{
+			name: "serialize linked pull requests after issue number",
+			input: []todo.TodoItem{
+				{Text: "Task with pull requests", IsChecked: false, IssueNumber: &num123, PullRequests: []uint64{130, 131}},
+			},
+			expected: "- [ ] Task with pull requests (#123) (PR #130, #131)\n",
+		},
+		{
+			name: "serialize issue in another repository",
+			input: []todo.TodoItem{
+				{Text: "Transferred task", IsChecked: false, ExternalIssue: &todo.IssueRef{Repo: "owner/other", Number: 5}},
+			},
+			expected: "- [ ] Transferred task (owner/other#5)\n",
+		},
+		{
+			name: "serialize cancelled item",
+			input: []todo.TodoItem{
+				{Text: "Dropped task", IsChecked: true, IsCancelled: true, IssueNumber: &num123},
+			},
+			expected: "- [-] Dropped task (#123)\n",
+		},
+		{
+			name: "serialize sections",
+			input: []todo.TodoItem{
+				{Text: "Unsectioned task", IsChecked: false},
+				{Text: "Todo task", IsChecked: false, Section: todo.Section{Title: "Todo", Level: 2}},
+				{Text: "Another todo task", IsChecked: false, Section: todo.Section{Title: "Todo", Level: 2}},
+				{Text: "Done task", IsChecked: true, Section: todo.Section{Title: "Done"}},
+				{Text: "Appended task", IsChecked: false},
+			},
+			expected: "- [ ] Unsectioned task\n\n## Todo\n\n- [ ] Todo task\n- [ ] Another todo task\n\n## Done\n\n- [x] Done task\n- [ ] Appended task\n",
+		},
+		{
+			name: "serialize inline markdown",
+			input: []todo.TodoItem{
+				{Text: "Fix login bug", Markdown: "Fix [login](url) **bug**", IssueNumber: &num123},
+				{Text: "Fix `parseConfig` crash", Markdown: "Fix `parseConfig` *crash*"},
+			},
+			expected: "- [ ] Fix [login](url) **bug** (#123)\n- [ ] Fix `parseConfig` *crash*\n",
+		},
+		{
+			name: "serialize text changed from its markdown",
+			input: []todo.TodoItem{
+				{Text: "Fix sign-in bug", Markdown: "Fix [login](url) **bug**", IssueNumber: &num123},
+			},
+			expected: "- [ ] Fix sign-in bug (#123)\n",
+		},
 		{
 			name:     "serialize empty list",
 			input:    []todo.TodoItem{},
@@ -216,7 +509,7 @@ func TestSerializeTodoMarkdown(t *testing.T) {
 }
 
 func TestSerializeRoundtrip(t *testing.T) {
-	originalContent := "- [ ] Task 1\n- [x] Task 2 (#123)\n- [ ] Task 3\n"
+	originalContent := "- [ ] Task 1\n- [x] Task 2 (#123) (PR #125)\n- [-] Task 4 (#124)\n\n# Section\n\n- [ ] Task 3 due:2026-11-01\n- [ ] See [docs](https://example.com) for `--json` (#126)\n- [ ] Task 5 (#127) <!-- atat:id=k3x9p2qa -->\n"
 	parsedItems, err := ParseTodoMarkdown(originalContent)
 	if err != nil {
 		t.Fatalf("ParseTodoMarkdown failed: %v", err)
@@ -0,0 +1,250 @@
+package markdown
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+// lineKind is what a line of a TODO file belongs to
+type lineKind int
+
+const (
+	// lineContent is content other than items and headings of sections with items
+	lineContent lineKind = iota
+	// lineItem is a line of a synced item
+	lineItem
+	// lineHeading is a line of the heading of a section with items
+	lineHeading
+	// lineIgnored is a line that is not synced
+	lineIgnored
+)
+
+// keptRange is a range of lines of a TODO file that is written back as it was
+type keptRange struct {
+	// start and end are the first line and the line after the last one
+	start, end int
+	// isSection reports whether the range is a section, starting with its heading
+	isSection bool
+	// heading is the index in the headings of the document of the heading the range
+	// starts with if it is a synced section without items, or -1
+	heading int
+}
+
+// keptBlock is content of a TODO file other than its synced items and the headings of
+// their sections, written back as it was
+//
+// Sections are told apart by the index of their heading in the document, as several
+// headings may have the same text.
+type keptBlock struct {
+	source string
+	// section is the index of the heading of the section the block is in, or -1 if the
+	// block is before any heading
+	section int
+	// preceding are the items of the section before the block, the nearest first
+	preceding []todo.TodoItem
+	// isSection reports whether the block is a section, starting with its heading
+	isSection bool
+	// next is the index of the heading following a section block, or -1 if there is none
+	next int
+	// heading is the index of the heading of a synced section without items the block
+	// starts with, or -1. Items added to that section are written after the block.
+	heading int
+}
+
+// keptItem is an item of a TODO file and where it was written
+type keptItem struct {
+	item todo.TodoItem
+	// prefix is the indent and list marker before its checkbox
+	prefix string
+	// heading is the index of the heading of its section, or -1 if it is before any heading
+	heading int
+}
+
+// keptHeading is a heading of a synced section and its source
+type keptHeading struct {
+	section todo.Section
+	source  string
+}
+
+// keptRanges returns the ranges of lines of doc that are not items or headings of
+// sections with items, in order: content that is not synced, prose, other lists and
+// blocks, and sections without items.
+//
+// Blank lines between a range and an item are kept in the range, so the item is still
+// separated from the content when it is written back.
+func keptRanges(doc document) []keptRange {
+	n := len(doc.lines)
+	kinds := make([]lineKind, n)
+	for i, start := range doc.itemLines {
+		for line := start; line < min(doc.itemEnds[i], n); line++ {
+			kinds[line] = lineItem
+		}
+	}
+
+	// emptyHeadings are the indexes of the headings of sections without items by their line
+	emptyHeadings := make(map[int]int)
+	for k, h := range doc.headings {
+		end := n
+		if k+1 < len(doc.headings) {
+			end = doc.headings[k+1].line
+		}
+		if slices.ContainsFunc(doc.itemLines, func(line int) bool { return line > h.line && line < end }) {
+			for line := h.line; line < min(h.end, n); line++ {
+				kinds[line] = lineHeading
+			}
+		} else {
+			emptyHeadings[h.line] = k
+		}
+	}
+
+	var ranges []keptRange
+	for _, r := range doc.ignored {
+		for line := r.start; line < r.end; line++ {
+			kinds[line] = lineIgnored
+		}
+		// Keep blank lines between the range and the following content out of the range
+		end := r.end
+		for end > r.start && isBlank(doc.lines[end-1]) {
+			end--
+		}
+		ranges = append(ranges, keptRange{start: r.start, end: end, isSection: r.section, heading: -1})
+	}
+
+	add := func(start, end int) {
+		if start == 0 || kinds[start-1] != lineItem {
+			for start < end && isBlank(doc.lines[start]) {
+				start++
+			}
+		}
+		if end == n || kinds[end] != lineItem {
+			for end > start && isBlank(doc.lines[end-1]) {
+				end--
+			}
+		}
+		if start == end {
+			return
+		}
+		r := keptRange{start: start, end: end, heading: -1}
+		if k, ok := emptyHeadings[start]; ok {
+			r.isSection = true
+			r.heading = k
+		}
+		ranges = append(ranges, r)
+	}
+
+	start := -1
+	for line := 0; line < n; line++ {
+		if kinds[line] != lineContent {
+			if start >= 0 {
+				add(start, line)
+				start = -1
+			}
+			continue
+		}
+		// Sections without items start a range of their own
+		if _, ok := emptyHeadings[line]; ok && start >= 0 {
+			add(start, line)
+			start = -1
+		}
+		if start < 0 {
+			start = line
+		}
+	}
+	if start >= 0 {
+		add(start, n)
+	}
+
+	slices.SortFunc(ranges, func(a, b keptRange) int { return a.start - b.start })
+	return ranges
+}
+
+// keptBlocks returns the content of doc other than its synced items and the headings of
+// their sections as blocks placed relative to the items and headings
+func keptBlocks(doc document) []keptBlock {
+	var blocks []keptBlock
+	for _, r := range keptRanges(doc) {
+		block := keptBlock{
+			source:    strings.Join(doc.lines[r.start:r.end], "\n"),
+			section:   headingBefore(doc, r.start),
+			isSection: r.isSection,
+			next:      -1,
+			heading:   r.heading,
+		}
+
+		for k, h := range doc.headings {
+			if h.line >= r.end {
+				block.next = k
+				break
+			}
+		}
+		for i := len(doc.items) - 1; i >= 0; i-- {
+			if doc.itemLines[i] < r.start && headingBefore(doc, doc.itemLines[i]) == block.section {
+				block.preceding = append(block.preceding, doc.items[i])
+			}
+		}
+
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// keptItems returns the items of doc with the indent and list marker they were written
+// with, so they are written back at the same depth, with the same marker and in the same
+// section
+func keptItems(doc document) []keptItem {
+	items := make([]keptItem, len(doc.items))
+	for i, item := range doc.items {
+		items[i] = keptItem{item: item, prefix: doc.itemPrefixes[i], heading: headingBefore(doc, doc.itemLines[i])}
+	}
+	return items
+}
+
+// keptHeadings returns the headings of doc with their source, so they are written back
+// with their formatting
+func keptHeadings(doc document) []keptHeading {
+	headings := make([]keptHeading, len(doc.headings))
+	for k, h := range doc.headings {
+		headings[k] = keptHeading{section: h.section, source: strings.Join(doc.lines[h.line:min(h.end, len(doc.lines))], "\n")}
+	}
+	return headings
+}
+
+// headingBefore returns the index of the last heading of doc before line, or -1 if there
+// is none
+func headingBefore(doc document, line int) int {
+	index := -1
+	for k, h := range doc.headings {
+		if h.line < line {
+			index = k
+		}
+	}
+	return index
+}
+
+// trimLeadingBlankLines removes the blank lines source starts with
+func trimLeadingBlankLines(source string) string {
+	lines := strings.Split(source, "\n")
+	for len(lines) > 1 && isBlank(lines[0]) {
+		lines = lines[1:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isBlank checks if a line is empty or only whitespace
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// sameItem checks if two items are the same task: by ID or issue number if both have
+// one, or by text otherwise
+func sameItem(a, b todo.TodoItem) bool {
+	if a.ID != "" && b.ID != "" {
+		return a.ID == b.ID
+	}
+	if a.IssueNumber != nil && b.IssueNumber != nil {
+		return *a.IssueNumber == *b.IssueNumber
+	}
+	return a.Text == b.Text
+}
@@ -0,0 +1,172 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/toms74209200/gh-atat/internal/todo"
+)
+
+const structuredContent = "# Project\n\nIntro text.\n\n## Inbox\n\n## Todo\n\n" +
+	"- [ ] First (#1)\n- plain bullet\n- [ ] Second\n\n  Details of second.\n\n" +
+	"```sh\nmake\n\nmake test\n```\n\n- [ ] Third\n\nDone\n====\n\n- [x] Old (#2)\n\n### Later\n\nNothing yet.\n"
+
+func TestSerializeTodoMarkdownWithOptionsKeepsStructure(t *testing.T) {
+	items, err := ParseTodoMarkdown(structuredContent)
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdown failed: %v", err)
+	}
+
+	actual, err := SerializeTodoMarkdownWithOptions(items, structuredContent, ParseOptions{})
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+	if actual != structuredContent {
+		t.Errorf("roundtrip failed:\noriginal:\n%s\nserialized:\n%s", structuredContent, actual)
+	}
+}
+
+func TestSerializeTodoMarkdownWithOptionsPlacesItemsInEmptySections(t *testing.T) {
+	items, err := ParseTodoMarkdown(structuredContent)
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdown failed: %v", err)
+	}
+	// Remove "Third" and add an item to the empty Inbox section at the end
+	num5 := uint64(5)
+	items = append(items[:2], items[3:]...)
+	items = append(items, todo.TodoItem{Text: "Pulled", IssueNumber: &num5, Section: todo.Section{Title: "Inbox", Level: 2}})
+
+	actual, err := SerializeTodoMarkdownWithOptions(items, structuredContent, ParseOptions{})
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+
+	expected := "# Project\n\nIntro text.\n\n## Inbox\n\n- [ ] Pulled (#5)\n\n## Todo\n\n" +
+		"- [ ] First (#1)\n- plain bullet\n- [ ] Second\n\n  Details of second.\n\n" +
+		"```sh\nmake\n\nmake test\n```\n\nDone\n====\n\n- [x] Old (#2)\n\n### Later\n\nNothing yet.\n"
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+const repeatedHeadingsContent = "# Week 1\n\n## Todo\n\n- [ ] Plan (#1)\n\nPlanning notes.\n\n" +
+	"# Week 2\n\n## Todo\n\nNothing yet.\n\n# Week 3\n\n## Todo\n\n- [ ] Review (#2)\n"
+
+func TestSerializeTodoMarkdownWithOptionsKeepsRepeatedHeadingsApart(t *testing.T) {
+	items, err := ParseTodoMarkdown(repeatedHeadingsContent)
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdown failed: %v", err)
+	}
+
+	actual, err := SerializeTodoMarkdownWithOptions(items, repeatedHeadingsContent, ParseOptions{})
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+	if actual != repeatedHeadingsContent {
+		t.Errorf("roundtrip failed:\noriginal:\n%s\nserialized:\n%s", repeatedHeadingsContent, actual)
+	}
+
+	// Items added to the section go under the heading of the item before them
+	num3, num4 := uint64(3), uint64(4)
+	items = todo.InsertInSection(items, todo.TodoItem{Text: "Pulled", IssueNumber: &num3, Section: todo.Section{Title: "Todo", Level: 2}})
+	items = append([]todo.TodoItem{{Text: "Urgent", IssueNumber: &num4, Section: todo.Section{Title: "Todo", Level: 2}}}, items...)
+
+	actual, err = SerializeTodoMarkdownWithOptions(items, repeatedHeadingsContent, ParseOptions{})
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+
+	expected := "# Week 1\n\n## Todo\n\n- [ ] Urgent (#4)\n- [ ] Plan (#1)\n\nPlanning notes.\n\n" +
+		"# Week 2\n\n## Todo\n\nNothing yet.\n\n# Week 3\n\n## Todo\n\n- [ ] Review (#2)\n- [ ] Pulled (#3)\n"
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestParseSections(t *testing.T) {
+	sections, err := ParseSections(structuredContent+"\n## Notes\n", ParseOptions{IgnoreSections: []string{"Notes"}})
+	if err != nil {
+		t.Fatalf("ParseSections failed: %v", err)
+	}
+
+	expected := []todo.Section{
+		{Title: "Project", Level: 1},
+		{Title: "Inbox", Level: 2},
+		{Title: "Todo", Level: 2},
+		{Title: "Done", Level: 1},
+		{Title: "Later", Level: 3},
+	}
+	if !reflect.DeepEqual(sections, expected) {
+		t.Errorf("expected %+v, got %+v", expected, sections)
+	}
+}
+
+func TestSerializeTodoMarkdownWithOptionsKeepsListMarkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"nested list", "- [ ] Parent (#1)\n  - [ ] Child (#2)\n    - [x] Grandchild (#3)\n- [ ] Next\n"},
+		{"quoted list", "- [ ] Task\n\n> - [ ] Quoted task\n> - [x] Quoted done task\n"},
+		{"ordered list", "1. [ ] First\n2. [x] Second (#1)\n3) [-] Third\n"},
+		{"other bullets", "* [ ] Star\n+ [ ] Plus\n"},
+		{"CRLF", "# Todo\r\n\r\n- [ ] First\r\n  - [ ] Nested\r\n\r\nNotes\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := ParseTodoMarkdown(tt.content)
+			if err != nil {
+				t.Fatalf("ParseTodoMarkdown failed: %v", err)
+			}
+
+			actual, err := SerializeTodoMarkdownWithOptions(items, tt.content, ParseOptions{})
+			if err != nil {
+				t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+			}
+			if actual != tt.content {
+				t.Errorf("roundtrip failed:\noriginal:\n%q\nserialized:\n%q", tt.content, actual)
+			}
+		})
+	}
+}
+
+func TestSerializeTodoMarkdownWithOptionsUpdatesNestedItems(t *testing.T) {
+	content := "* [ ] Parent\n  * [ ] Child\n"
+	items, err := ParseTodoMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdown failed: %v", err)
+	}
+	num4 := uint64(4)
+	items[1].IssueNumber = &num4
+	items[1].IsChecked = true
+	items = append(items, todo.TodoItem{Text: "Added"})
+
+	actual, err := SerializeTodoMarkdownWithOptions(items, content, ParseOptions{})
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+
+	expected := "* [ ] Parent\n  * [x] Child (#4)\n- [ ] Added\n"
+	if actual != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, actual)
+	}
+}
+
+func TestSerializeTodoMarkdownWithOptionsCollapsesBlankLinesOfRemovedItems(t *testing.T) {
+	content := "## Todo\n\n- [ ] First\n\nNotes\n\n- [ ] Second\n"
+	items, err := ParseTodoMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseTodoMarkdown failed: %v", err)
+	}
+
+	actual, err := SerializeTodoMarkdownWithOptions(items[1:], content, ParseOptions{})
+	if err != nil {
+		t.Fatalf("SerializeTodoMarkdownWithOptions failed: %v", err)
+	}
+
+	expected := "## Todo\n\nNotes\n\n- [ ] Second\n"
+	if actual != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, actual)
+	}
+}
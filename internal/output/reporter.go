@@ -0,0 +1,136 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Format is how a Reporter writes events
+type Format string
+
+const (
+	// FormatText writes events as human readable lines
+	FormatText Format = "text"
+	// FormatJSON writes events as JSON objects, one per line (NDJSON)
+	FormatJSON Format = "json"
+)
+
+// Level is the severity of an event
+type Level string
+
+const (
+	LevelInfo    Level = "info"
+	LevelWarning Level = "warning"
+)
+
+// Fields are the data of an event. In JSON format they are written as fields of
+// the event object next to "event", "level" and "message".
+type Fields map[string]any
+
+// reservedFields are the fields written for every event in JSON format
+var reservedFields = []string{"event", "level", "message"}
+
+// Reporter writes what a command does, as text for people or as JSON lines for programs.
+//
+// Each event has a type with a stable name, such as "issue_created", a human readable
+// message, and fields with stable names holding its data.
+type Reporter struct {
+	format Format
+	out    io.Writer
+}
+
+// NewReporter creates a Reporter writing events to out in the given format
+func NewReporter(format Format, out io.Writer) *Reporter {
+	return &Reporter{format: format, out: out}
+}
+
+// JSON reports whether events are written as JSON
+func (r *Reporter) JSON() bool {
+	return r.format == FormatJSON
+}
+
+// Info reports an event. In text format, message is written as a line.
+func (r *Reporter) Info(event, message string, fields Fields) {
+	r.write(LevelInfo, event, message, fields)
+}
+
+// Warn reports a warning. In text format, message is written prefixed with "Warning: ".
+func (r *Reporter) Warn(event, message string, fields Fields) {
+	r.write(LevelWarning, event, message, fields)
+}
+
+// Heading writes a line grouping the events that follow, such as the path of the file
+// being processed. Headings are only written in text format, as events carry their own fields.
+func (r *Reporter) Heading(text string) {
+	if r.format != FormatJSON {
+		fmt.Fprintln(r.out, text)
+	}
+}
+
+func (r *Reporter) write(level Level, event, message string, fields Fields) {
+	if r.format != FormatJSON {
+		if level == LevelWarning {
+			message = "Warning: " + message
+		}
+		fmt.Fprintln(r.out, message)
+		return
+	}
+
+	line, err := EncodeEvent(level, event, message, fields)
+	if err != nil {
+		// Values that can't be encoded are a programming error; keep the event readable
+		line, _ = EncodeEvent(level, event, message, Fields{"error": err.Error()})
+	}
+	r.out.Write(append(line, '\n'))
+}
+
+// EncodeEvent encodes an event as a single line JSON object.
+// The "event", "level" and "message" fields come first, followed by the other fields
+// in alphabetical order. Fields named like the reserved fields are ignored.
+func EncodeEvent(level Level, event, message string, fields Fields) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	writeField := func(key string, value any) error {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode field %s of event %s: %w", key, event, err)
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(encoded)
+		return nil
+	}
+
+	for _, field := range []struct {
+		key   string
+		value any
+	}{{"event", event}, {"level", level}, {"message", message}} {
+		if err := writeField(field.key, field.value); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if !slices.Contains(reservedFields, key) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if err := writeField(key, fields[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
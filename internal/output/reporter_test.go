@@ -0,0 +1,53 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReporterText(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewReporter(FormatText, &buf)
+
+	reporter.Heading("docs/TODO.md:")
+	reporter.Info("issue_created", "Created issue #12: Task", Fields{"issue": 12})
+	reporter.Warn("reference_orphaned", "#9 was deleted", Fields{"issue": 9})
+
+	expected := "docs/TODO.md:\nCreated issue #12: Task\nWarning: #9 was deleted\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+	if reporter.JSON() {
+		t.Error("expected text reporter")
+	}
+}
+
+func TestReporterJSON(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewReporter(FormatJSON, &buf)
+
+	reporter.Heading("docs/TODO.md:")
+	reporter.Info("issue_created", "Created issue #12: Task", Fields{"title": "Task", "issue": 12, "file": "TODO.md"})
+	reporter.Warn("reference_orphaned", "#9 was deleted", Fields{"issue": 9, "message": "ignored"})
+
+	expected := `{"event":"issue_created","level":"info","message":"Created issue #12: Task","file":"TODO.md","issue":12,"title":"Task"}` + "\n" +
+		`{"event":"reference_orphaned","level":"warning","message":"#9 was deleted","issue":9}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+	if !reporter.JSON() {
+		t.Error("expected JSON reporter")
+	}
+}
+
+func TestReporterJSONUnencodableField(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewReporter(FormatJSON, &buf)
+
+	reporter.Info("config_value", "key=value", Fields{"value": func() {}})
+
+	expected := `{"event":"config_value","level":"info","message":"key=value","error":"failed to encode field value of event config_value: json: unsupported type: func()"}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
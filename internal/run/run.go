@@ -1,38 +1,72 @@
 package run
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand/v2"
 	"os"
 	"os/exec"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/toms74209200/gh-atat/internal/clean"
 	"github.com/toms74209200/gh-atat/internal/cli"
 	"github.com/toms74209200/gh-atat/internal/config"
+	"github.com/toms74209200/gh-atat/internal/errs"
 	"github.com/toms74209200/gh-atat/internal/github"
+	"github.com/toms74209200/gh-atat/internal/history"
 	"github.com/toms74209200/gh-atat/internal/markdown"
+	"github.com/toms74209200/gh-atat/internal/output"
 	"github.com/toms74209200/gh-atat/internal/storage"
 	"github.com/toms74209200/gh-atat/internal/todo"
 )
 
+// reporter reports what commands do, as text or as JSON lines with --json
+var reporter = output.NewReporter(output.FormatText, os.Stdout)
+
 // Run executes the given command
 func Run(args []string, version string) error {
 	command := cli.ParseArgs(args)
+	reporter = output.NewReporter(outputFormat(command), os.Stdout)
 
 	switch cmd := command.(type) {
 	case cli.Push:
-		return runPush()
+		return runPush(cmd.Orphans, cmd.Strategy, cmd.File, cmd.Config)
 	case cli.Pull:
-		return runPull()
+		return runPull(cmd.Orphans, cmd.Strategy, cmd.File, cmd.Config)
+	case cli.Sync:
+		return runSync(cmd.Orphans, cmd.Strategy, cmd.File, cmd.Config)
 	case cli.Clean:
-		return runClean(cmd.DryRun)
+		return runClean(cmd.DryRun, cmd.File, cmd.Config)
+	case cli.Status:
+		return runStatus(cmd.Overdue, cmd.File, cmd.Config)
 	case cli.RemoteList:
 		return runRemoteList()
 	case cli.RemoteAdd:
 		return runRemoteAdd(cmd.Repo)
 	case cli.RemoteRemove:
 		return runRemoteRemove(cmd.Repo)
+	case cli.ConfigList:
+		return runConfigList(cmd.Scope)
+	case cli.ConfigGet:
+		return runConfigGet(cmd.Key, cmd.Scope)
+	case cli.ConfigSet:
+		return runConfigSet(cmd.Key, cmd.Value, cmd.Scope)
+	case cli.ConfigUnset:
+		return runConfigUnset(cmd.Key, cmd.Scope)
+	case cli.Undo:
+		return runUndo(cmd.Force)
+	case cli.Log:
+		return runLog(cmd.Since)
 	case cli.Login:
 		return fmt.Errorf("login command is not needed for gh extension. Authentication is handled by gh CLI")
 	case cli.Whoami:
@@ -50,465 +84,2527 @@ func Run(args []string, version string) error {
 	}
 }
 
-func runPush() error {
+// outputFormat returns the format commands report in, JSON if --json is given
+func outputFormat(command cli.Command) output.Format {
+	var jsonOutput bool
+	switch cmd := command.(type) {
+	case cli.Push:
+		jsonOutput = cmd.JSON
+	case cli.Pull:
+		jsonOutput = cmd.JSON
+	case cli.Sync:
+		jsonOutput = cmd.JSON
+	case cli.Clean:
+		jsonOutput = cmd.JSON
+	case cli.Status:
+		jsonOutput = cmd.JSON
+	case cli.RemoteList:
+		jsonOutput = cmd.JSON
+	case cli.ConfigList:
+		jsonOutput = cmd.JSON
+	case cli.ConfigGet:
+		jsonOutput = cmd.JSON
+	case cli.Undo:
+		jsonOutput = cmd.JSON
+	case cli.Log:
+		jsonOutput = cmd.JSON
+	}
+	if jsonOutput {
+		return output.FormatJSON
+	}
+	return output.FormatText
+}
+
+func runPush(orphanAction cli.OrphanAction, strategy cli.ConflictStrategy, fileFlag string, overrides cli.ConfigOverrides) error {
+	// Keep other runs from changing the project files at the same time
+	unlock, err := lockProject()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Record the run so it can be undone
+	journal := history.NewEntry(history.CommandPush, time.Now())
+	journal.User = currentUser()
+	defer saveJournal(journal)
+
 	// Load configuration
-	configStorage, err := storage.NewLocalConfigStorage()
+	configMap, err := loadConfig(overrides)
 	if err != nil {
-		return fmt.Errorf("failed to read project configuration: %w", err)
+		return err
 	}
 
-	configMap, err := configStorage.LoadConfig()
+	opts, err := resolveRunOptions(configMap, orphanAction, strategy)
 	if err != nil {
-		return fmt.Errorf("error loading project config: %w", err)
+		return err
 	}
 
-	// Get repository
-	repo, err := getFirstRepository(configMap)
+	// Read the tracked TODO files
+	todoFiles, selected, readStates, err := readTodoFiles(configMap, fileFlag, true)
 	if err != nil {
 		return err
 	}
 
-	// Read TODO.md
-	todoContent, err := os.ReadFile("TODO.md")
+	// Fetch project board
+	if err := opts.fetchBoard(); err != nil {
+		return err
+	}
+
+	issuesByRepo := make(map[string][]github.GitHubIssue)
+	for _, i := range selected {
+		file := todoFiles[i]
+		printFileHeader(file.Path, len(selected))
+
+		// Fetch GitHub issues
+		journal.AddRepository(file.Repo)
+		githubIssues, err := fetchGitHubIssuesCached(issuesByRepo, file.Repo)
+		if err != nil {
+			return partialFailure(journal, err)
+		}
+
+		readItems := file.Items
+
+		// Give each task a hidden ID to find it again after its text is edited
+		if opts.itemIDs {
+			file.Items = todo.AssignIDs(file.Items, newItemID)
+		}
+
+		// Link new tasks to existing issues with similar titles
+		var held []github.LinkCandidate
+		if opts.fuzzyMatch {
+			file.Items, held, err = linkSimilarIssues(file, github.FilterIssuesForFile(githubIssues, file, todoFiles))
+			if err != nil {
+				return partialFailure(journal, err)
+			}
+		}
+
+		updatedTodoItems, err := pushTodoFile(file, githubIssues, held, opts, journal)
+		if err != nil {
+			return partialFailure(journal, err)
+		}
+		todoFiles[i].Items = updatedTodoItems
+
+		// Write updated TODO file
+		if err := writeTodoFile(file.Path, readStates[i], readItems, updatedTodoItems, opts.parse, journal); err != nil {
+			return partialFailure(journal, err)
+		}
+	}
+
+	return nil
+}
+
+// pushTodoFile pushes the items of a TODO file to its repository and returns the updated items
+// No issue is created for the tasks of held link candidates, or for tasks unlinked by the orphan action.
+// Operations made on GitHub are recorded in journal.
+func pushTodoFile(file github.TodoFile, githubIssues []github.GitHubIssue, held []github.LinkCandidate, opts runOptions, journal *history.Entry) ([]todo.TodoItem, error) {
+	repo := file.Repo
+
+	// Follow transferred issues and handle references to deleted issues
+	todoItems, unlinked, err := resolveOrphanedReferences(repo, file.Items, githubIssues, opts.orphanAction)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate title updates with rename history
+	titleUpdates, err := github.CalculateTitleUpdatesWithHistory(todoItems, githubIssues, func(issueNumber uint64) ([]json.RawMessage, error) {
+		return fetchIssueEvents(repo, issueNumber)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Settle issues renamed on GitHub whose task text was not updated
+	todoItems, conflictRenames, unresolved, err := resolveTitleConflicts(file, todoItems, githubIssues, titleUpdates.StaleIssues, opts.strategy)
+	if err != nil {
+		return nil, err
+	}
+	for _, issueNumber := range unresolved {
+		reporter.Warn("issue_renamed_remotely",
+			fmt.Sprintf("issue #%d was renamed on GitHub; run `gh atat pull` to update %s", issueNumber, file.Path),
+			output.Fields{"file": file.Path, "repo": repo, "issue": issueNumber})
+	}
+
+	// Calculate create/close operations. Tasks unlinked in this run get no new issue yet.
+	operations := github.HoldLinkCandidates(github.CalculateGitHubOperations(todoItems, githubIssues), held)
+	operations = github.HoldUnlinkedItems(operations, unlinked)
+
+	// Settle issues whose close reason was changed on GitHub instead of overwriting it
+	pastReasons, err := github.CollectPastCloseReasons(todoItems, githubIssues, func(issueNumber uint64) ([]json.RawMessage, error) {
+		return fetchIssueEvents(repo, issueNumber)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if stateConflicts := github.FindPushStateConflicts(todoItems, githubIssues, pastReasons); len(stateConflicts) > 0 {
+		if opts.strategy == cli.StrategyFail {
+			return nil, &errs.ConflictError{Err: conflictsError(stateConflictDescriptions(file.Path, stateConflicts))}
+		}
+		cancelled, err := chooseCloseStates(file.Path, stateConflicts, opts.strategy)
+		if err != nil {
+			return nil, err
+		}
+		todoItems, operations = github.ResolveStateConflicts(todoItems, operations, stateConflicts, cancelled)
+		for _, conflict := range stateConflicts {
+			if _, ok := cancelled[conflict.Number]; ok {
+				continue
+			}
+			reporter.Warn("state_conflict",
+				fmt.Sprintf("issue #%d was closed as %s on GitHub but is %s in %s; use --strategy to settle it", conflict.Number, closeStateName(conflict.RemoteCancelled), closeStateName(conflict.LocalCancelled), file.Path),
+				output.Fields{"file": file.Path, "repo": repo, "issue": conflict.Number, "local_cancelled": conflict.LocalCancelled, "remote_cancelled": conflict.RemoteCancelled})
+		}
+	}
+
+	// Combine title rename operations with create/close operations
+	allOperations := append(append(titleUpdates.Operations, conflictRenames...), operations...)
+
+	// Execute operations
+	updatedTodoItems, createdIssues, err := applyIssueOperations(file, allOperations, todoItems, githubIssues, journal)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("TODO.md file not found")
+		return nil, err
+	}
+
+	// Assign issues to the milestones for their due dates
+	if opts.dueSync {
+		dueOperations := github.CalculateDueDateOperations(updatedTodoItems, append(githubIssues, createdIssues...))
+		if err := applyDueDateOperations(repo, dueOperations, journal); err != nil {
+			return nil, err
+		}
+	}
+
+	// Place issues on the project board according to their sections, with their due dates
+	if opts.hasProject {
+		projectOperations := github.CalculateProjectOperations(updatedTodoItems, opts.board, repo, createdIssues)
+		if opts.projectDue {
+			projectOperations = append(projectOperations, github.CalculateProjectDueDateOperations(updatedTodoItems, opts.board, repo)...)
+		}
+		if err := applyProjectOperations(repo, opts.board, append(githubIssues, createdIssues...), projectOperations, journal); err != nil {
+			return nil, err
 		}
-		return fmt.Errorf("failed to read TODO.md: %w", err)
 	}
 
-	todoItems, err := markdown.ParseTodoMarkdown(string(todoContent))
+	return updatedTodoItems, nil
+}
+
+func runPull(orphanAction cli.OrphanAction, strategy cli.ConflictStrategy, fileFlag string, overrides cli.ConfigOverrides) error {
+	// Keep other runs from changing the project files at the same time
+	unlock, err := lockProject()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Record the run so it can be undone
+	journal := history.NewEntry(history.CommandPull, time.Now())
+	journal.User = currentUser()
+	defer saveJournal(journal)
+
+	// Load configuration
+	configMap, err := loadConfig(overrides)
 	if err != nil {
 		return err
 	}
 
-	// Fetch GitHub issues
-	githubIssues, err := fetchGitHubIssues(repo)
+	opts, err := resolveRunOptions(configMap, orphanAction, strategy)
 	if err != nil {
 		return err
 	}
 
-	// Calculate title updates with rename history
-	titleUpdates, err := github.CalculateTitleUpdatesWithHistory(todoItems, githubIssues, func(issueNumber uint64) ([]json.RawMessage, error) {
+	// Read the tracked TODO files
+	todoFiles, selected, readStates, err := readTodoFiles(configMap, fileFlag, true)
+	if err != nil {
+		return err
+	}
+
+	// Fetch project board
+	if err := opts.fetchBoard(); err != nil {
+		return err
+	}
+
+	issuesByRepo := make(map[string][]github.GitHubIssue)
+	for _, i := range selected {
+		file := todoFiles[i]
+		readItems := file.Items
+		printFileHeader(file.Path, len(selected))
+
+		// Give each task a hidden ID to find it again after its text is edited
+		if opts.itemIDs {
+			file.Items = todo.AssignIDs(file.Items, newItemID)
+		}
+
+		// Fetch GitHub issues
+		journal.AddRepository(file.Repo)
+		githubIssues, err := fetchGitHubIssuesCached(issuesByRepo, file.Repo)
+		if err != nil {
+			return partialFailure(journal, err)
+		}
+
+		// Follow transferred issues and handle references to deleted issues
+		file.Items, _, err = resolveOrphanedReferences(file.Repo, file.Items, githubIssues, opts.orphanAction)
+		if err != nil {
+			return partialFailure(journal, err)
+		}
+
+		// Only pull the issues that belong to this file
+		fileIssues := github.FilterIssuesForFile(githubIssues, file, todoFiles)
+
+		// Link new tasks to existing issues with similar titles
+		if opts.fuzzyMatch {
+			var held []github.LinkCandidate
+			file.Items, held, err = linkSimilarIssues(file, fileIssues)
+			if err != nil {
+				return partialFailure(journal, err)
+			}
+			fileIssues = github.WithoutLinkCandidates(fileIssues, held)
+		}
+
+		updatedTodoItems, err := pullTodoFile(file, fileIssues, opts, journal)
+		if err != nil {
+			return partialFailure(journal, err)
+		}
+		// Later files must not pull the issues added to this one
+		todoFiles[i].Items = updatedTodoItems
+
+		// Write updated TODO file, with IDs for the tasks added from GitHub
+		if opts.itemIDs {
+			updatedTodoItems = todo.AssignIDs(updatedTodoItems, newItemID)
+		}
+		if err := writeTodoFile(file.Path, readStates[i], readItems, updatedTodoItems, opts.parse, journal); err != nil {
+			return partialFailure(journal, err)
+		}
+	}
+
+	return nil
+}
+
+// pullTodoFile updates the items of a TODO file from the issues that belong to it
+// Issues renamed to settle title conflicts are recorded in journal.
+func pullTodoFile(file github.TodoFile, githubIssues []github.GitHubIssue, opts runOptions, journal *history.Entry) ([]todo.TodoItem, error) {
+	repo := file.Repo
+
+	// Synchronize titles with rename history
+	titleSync, err := github.SynchronizeTitlesWithHistory(file.Items, githubIssues, func(issueNumber uint64) ([]json.RawMessage, error) {
 		return fetchIssueEvents(repo, issueNumber)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Settle tasks edited locally whose issue title was not updated
+	todoItems, conflictRenames, unresolved, err := resolveTitleConflicts(file, titleSync.Items, githubIssues, titleSync.LocallyEditedIssues, opts.strategy)
+	if err != nil {
+		return nil, err
+	}
+	for _, issueNumber := range unresolved {
+		reporter.Warn("task_edited_locally",
+			fmt.Sprintf("%s text for issue #%d was changed locally; run `gh atat push` to update the issue title", file.Path, issueNumber),
+			output.Fields{"file": file.Path, "repo": repo, "issue": issueNumber})
+	}
+	for _, todoOp := range conflictRenames {
+		if err := renameIssue(file, todoOp.Operation.(github.RenameIssueOp), githubIssues, journal); err != nil {
+			return nil, err
+		}
+	}
+
+	// Synchronize with GitHub issues, placing the tasks of new issues as configured
+	updatedTodoItems := github.SynchronizeWithGitHubIssues(todoItems, githubIssues)
+	updatedTodoItems = github.PlaceNewItems(todoItems, updatedTodoItems, file.Sections, githubIssues, opts.placement)
+
+	// Synchronize due dates with milestones
+	if opts.dueSync {
+		updatedTodoItems = github.SynchronizeDueDates(updatedTodoItems, githubIssues)
+	}
+	if opts.projectDue {
+		updatedTodoItems = github.SynchronizeProjectDueDates(updatedTodoItems, opts.board, repo)
+	}
+
+	// Show pull requests linked to each task
+	updatedTodoItems, err = synchronizePullRequests(repo, updatedTodoItems, githubIssues, opts.markMergedDone)
+	if err != nil {
+		return nil, err
+	}
+
+	// Arrange items according to the project board
+	if opts.hasProject {
+		updatedTodoItems = github.ArrangeByBoard(updatedTodoItems, opts.board, repo)
+	}
+
+	return updatedTodoItems, nil
+}
+
+func runSync(orphanAction cli.OrphanAction, strategy cli.ConflictStrategy, fileFlag string, overrides cli.ConfigOverrides) error {
+	// Keep other runs from changing the project files at the same time
+	unlock, err := lockProject()
 	if err != nil {
 		return err
 	}
+	defer unlock()
+
+	// Record the run so it can be undone
+	journal := history.NewEntry(history.CommandSync, time.Now())
+	journal.User = currentUser()
+	defer saveJournal(journal)
 
-	for _, issueNumber := range titleUpdates.StaleIssues {
-		fmt.Printf("Warning: issue #%d was renamed on GitHub; run `gh atat pull` to update TODO.md\n", issueNumber)
+	// Load configuration
+	configMap, err := loadConfig(overrides)
+	if err != nil {
+		return err
 	}
 
-	// Calculate create/close operations
-	operations := github.CalculateGitHubOperations(todoItems, githubIssues)
+	opts, err := resolveRunOptions(configMap, orphanAction, strategy)
+	if err != nil {
+		return err
+	}
 
-	// Combine title rename operations with create/close operations
-	allOperations := append(titleUpdates.Operations, operations...)
+	// Read the tracked TODO files
+	todoFiles, selected, readStates, err := readTodoFiles(configMap, fileFlag, true)
+	if err != nil {
+		return err
+	}
+
+	// Fetch project board
+	if err := opts.fetchBoard(); err != nil {
+		return err
+	}
+
+	issuesByRepo := make(map[string][]github.GitHubIssue)
+	for _, i := range selected {
+		file := todoFiles[i]
+		readItems := file.Items
+		printFileHeader(file.Path, len(selected))
+
+		// Give each task a hidden ID to find it again after its text is edited
+		if opts.itemIDs {
+			file.Items = todo.AssignIDs(file.Items, newItemID)
+		}
+
+		// Fetch GitHub issues once for both directions
+		journal.AddRepository(file.Repo)
+		githubIssues, err := fetchGitHubIssuesCached(issuesByRepo, file.Repo)
+		if err != nil {
+			return partialFailure(journal, err)
+		}
+
+		// Follow transferred issues and handle references to deleted issues
+		var unlinked []todo.TodoItem
+		file.Items, unlinked, err = resolveOrphanedReferences(file.Repo, file.Items, githubIssues, opts.orphanAction)
+		if err != nil {
+			return partialFailure(journal, err)
+		}
+
+		// Only sync the issues that belong to this file
+		fileIssues := github.FilterIssuesForFile(githubIssues, file, todoFiles)
+
+		// Link new tasks to existing issues with similar titles
+		var held []github.LinkCandidate
+		if opts.fuzzyMatch {
+			file.Items, held, err = linkSimilarIssues(file, fileIssues)
+			if err != nil {
+				return partialFailure(journal, err)
+			}
+			fileIssues = github.WithoutLinkCandidates(fileIssues, held)
+		}
+
+		updatedTodoItems, err := syncTodoFile(file, fileIssues, held, unlinked, opts, journal)
+		if err != nil {
+			return partialFailure(journal, err)
+		}
+		// Later files must not pull the issues added to this one
+		todoFiles[i].Items = updatedTodoItems
+
+		// Write updated TODO file, with IDs for the tasks added from GitHub
+		if opts.itemIDs {
+			updatedTodoItems = todo.AssignIDs(updatedTodoItems, newItemID)
+		}
+		if err := writeTodoFile(file.Path, readStates[i], readItems, updatedTodoItems, opts.parse, journal); err != nil {
+			return partialFailure(journal, err)
+		}
+	}
+
+	return nil
+}
+
+// syncTodoFile synchronizes the items of a TODO file and the issues that belong to it in both
+// directions, and returns the updated items.
+// No issue is created for the tasks of held link candidates, or for the unlinked tasks.
+// Operations made on GitHub are recorded in journal.
+func syncTodoFile(file github.TodoFile, githubIssues []github.GitHubIssue, held []github.LinkCandidate, unlinked []todo.TodoItem, opts runOptions, journal *history.Entry) ([]todo.TodoItem, error) {
+	repo := file.Repo
+	todoItems := file.Items
+
+	// Show pull requests linked to each task. Tasks are marked done by merged pull requests
+	// only after their issues are synchronized, so the pull requests close the issues.
+	links, pullRequests, err := fetchLinkedPullRequests(repo, todoItems, githubIssues)
+	if err != nil {
+		return nil, err
+	}
+	todoItems = github.SynchronizePullRequests(todoItems, pullRequests, links, false)
+
+	// Merge titles and states in both directions with rename history
+	pastTitles, err := github.CollectPastTitles(todoItems, githubIssues, func(issueNumber uint64) ([]json.RawMessage, error) {
+		return fetchIssueEvents(repo, issueNumber)
+	})
+	if err != nil {
+		return nil, err
+	}
+	plan := github.PlanSync(todoItems, githubIssues, pastTitles, github.SyncResolutions{})
+
+	// Settle the changes made on both sides
+	if len(plan.TitleConflicts) > 0 || len(plan.StateConflicts) > 0 {
+		if opts.strategy == cli.StrategyFail {
+			descriptions := append(titleConflictDescriptions(file.Path, plan.TitleConflicts), stateConflictDescriptions(file.Path, plan.StateConflicts)...)
+			return nil, &errs.ConflictError{Err: conflictsError(descriptions)}
+		}
+		titles, err := chooseTitles(file.Path, plan.TitleConflicts, opts.strategy)
+		if err != nil {
+			return nil, err
+		}
+		cancelled, err := chooseCloseStates(file.Path, plan.StateConflicts, opts.strategy)
+		if err != nil {
+			return nil, err
+		}
+		plan = github.PlanSync(todoItems, githubIssues, pastTitles, github.SyncResolutions{Titles: titles, Cancelled: cancelled})
+	}
+	for _, conflict := range plan.TitleConflicts {
+		reporter.Warn("title_conflict",
+			fmt.Sprintf("issue #%d was renamed on GitHub to %q and edited in %s to %q; use --strategy to settle it", conflict.Number, conflict.Remote, file.Path, conflict.Local),
+			output.Fields{"file": file.Path, "repo": repo, "issue": conflict.Number, "local": conflict.Local, "remote": conflict.Remote})
+	}
+	for _, conflict := range plan.StateConflicts {
+		reporter.Warn("state_conflict",
+			fmt.Sprintf("issue #%d was closed as %s on GitHub but is %s in %s; use --strategy to settle it", conflict.Number, closeStateName(conflict.RemoteCancelled), closeStateName(conflict.LocalCancelled), file.Path),
+			output.Fields{"file": file.Path, "repo": repo, "issue": conflict.Number, "local_cancelled": conflict.LocalCancelled, "remote_cancelled": conflict.RemoteCancelled})
+	}
+
+	// Place the tasks of new issues as configured
+	placedItems := github.PlaceNewItems(todoItems, plan.Items, file.Sections, githubIssues, opts.placement)
+
+	// Create, close and rename issues
+	operations := github.HoldUnlinkedItems(github.HoldLinkCandidates(plan.Operations, held), unlinked)
+	updatedTodoItems, createdIssues, err := applyIssueOperations(file, operations, placedItems, githubIssues, journal)
+	if err != nil {
+		return nil, err
+	}
+	updatedTodoItems = github.SynchronizePullRequests(updatedTodoItems, pullRequests, links, opts.markMergedDone)
+
+	// Assign issues to the milestones for their due dates. Due dates in the file take
+	// precedence, and tasks without a due date take the due date of their milestone.
+	if opts.dueSync {
+		dueOperations := github.CalculateDueDateOperations(updatedTodoItems, append(githubIssues, createdIssues...))
+		if err := applyDueDateOperations(repo, dueOperations, journal); err != nil {
+			return nil, err
+		}
+		pulledDueDates := github.SynchronizeDueDates(updatedTodoItems, githubIssues)
+		for j := range updatedTodoItems {
+			if updatedTodoItems[j].DueDate == nil {
+				updatedTodoItems[j].DueDate = pulledDueDates[j].DueDate
+			}
+		}
+	}
+
+	// Arrange items according to the project board, and place new issues on it. Due dates
+	// are synced with the board like with milestones.
+	if opts.hasProject {
+		updatedTodoItems = github.ArrangeByBoard(updatedTodoItems, opts.board, repo)
+		projectOperations := github.CalculateProjectOperations(updatedTodoItems, opts.board, repo, createdIssues)
+		if opts.projectDue {
+			projectOperations = append(projectOperations, github.CalculateProjectDueDateOperations(updatedTodoItems, opts.board, repo)...)
+		}
+		if err := applyProjectOperations(repo, opts.board, append(githubIssues, createdIssues...), projectOperations, journal); err != nil {
+			return nil, err
+		}
+		if opts.projectDue {
+			pulledDueDates := github.SynchronizeProjectDueDates(updatedTodoItems, opts.board, repo)
+			for j := range updatedTodoItems {
+				if updatedTodoItems[j].DueDate == nil {
+					updatedTodoItems[j].DueDate = pulledDueDates[j].DueDate
+				}
+			}
+		}
+	}
+
+	return updatedTodoItems, nil
+}
+
+// applyIssueOperations creates, closes and renames issues, and returns the items updated with
+// the numbers of the created issues, and the created issues.
+// Operations made on GitHub are recorded in journal.
+func applyIssueOperations(file github.TodoFile, operations []github.TodoOperation, todoItems []todo.TodoItem, githubIssues []github.GitHubIssue, journal *history.Entry) ([]todo.TodoItem, []github.GitHubIssue, error) {
+	repo := file.Repo
 
-	// Execute operations
 	updatedTodoItems := make([]todo.TodoItem, len(todoItems))
 	copy(updatedTodoItems, todoItems)
 
-	for _, todoOp := range allOperations {
-		switch op := todoOp.Operation.(type) {
-		case github.CreateIssueOp:
-			issueNumber, err := createGitHubIssue(repo, op.Title)
-			if err != nil {
-				return err
-			}
-			fmt.Printf("Created issue #%d: %s\n", issueNumber, todoOp.Todo.Text)
+	var labels []string
+	if file.Label != "" {
+		labels = []string{file.Label}
+	}
+
+	var createdIssues []github.GitHubIssue
+	for _, todoOp := range operations {
+		switch op := todoOp.Operation.(type) {
+		case github.CreateIssueOp:
+			createdIssue, err := createGitHubIssue(repo, op.Title, labels)
+			if err != nil {
+				return nil, nil, err
+			}
+			reporter.Info("issue_created",
+				fmt.Sprintf("Created issue #%d: %s", createdIssue.Number, todoOp.Todo.Text),
+				output.Fields{"file": file.Path, "repo": repo, "issue": createdIssue.Number, "title": op.Title})
+			journal.Record(history.Operation{Kind: history.OperationCreate, Repo: repo, Number: createdIssue.Number, Title: op.Title})
+
+			// Update TODO item with issue number
+			issueNum := createdIssue.Number
+			createdIssues = append(createdIssues, createdIssue)
+			for j := range updatedTodoItems {
+				if isCreatedItem(updatedTodoItems[j], todoOp.Todo) {
+					updatedTodoItems[j].IssueNumber = &issueNum
+					break
+				}
+			}
+		case github.CloseIssueOp:
+			err := closeGitHubIssue(repo, int(op.Number), op.Reason)
+			if err != nil {
+				return nil, nil, err
+			}
+			journal.Record(history.Operation{Kind: history.OperationClose, Repo: repo, Number: op.Number, Reason: string(op.Reason)})
+			message := fmt.Sprintf("Closed issue #%d", op.Number)
+			if op.Reason == github.IssueStateReasonNotPlanned {
+				message += " as not planned"
+			}
+			reporter.Info("issue_closed", message, output.Fields{"file": file.Path, "repo": repo, "issue": op.Number, "reason": string(op.Reason)})
+		case github.RenameIssueOp:
+			if err := renameIssue(file, op, githubIssues, journal); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return updatedTodoItems, createdIssues, nil
+}
+
+// isCreatedItem checks if item is the todo item an issue was created for: by ID if the
+// item has one, or by its text and state otherwise
+func isCreatedItem(item, created todo.TodoItem) bool {
+	if item.IssueNumber != nil || item.ExternalIssue != nil {
+		return false
+	}
+	if created.ID != "" {
+		return item.ID == created.ID
+	}
+	return item.ID == "" && item.Text == created.Text && item.IsChecked == created.IsChecked
+}
+
+// renameIssue renames an issue on GitHub, and reports and records the rename
+func renameIssue(file github.TodoFile, op github.RenameIssueOp, githubIssues []github.GitHubIssue, journal *history.Entry) error {
+	if err := renameGitHubIssue(file.Repo, int(op.Number), op.Title); err != nil {
+		return err
+	}
+	reporter.Info("issue_renamed",
+		fmt.Sprintf("Renamed issue #%d: %s", op.Number, op.Title),
+		output.Fields{"file": file.Path, "repo": file.Repo, "issue": op.Number, "title": op.Title})
+	journal.Record(history.Operation{Kind: history.OperationRename, Repo: file.Repo, Number: op.Number, Title: op.Title, PreviousTitle: issueTitle(githubIssues, op.Number)})
+	return nil
+}
+
+// resolveTitleConflicts settles the conflicts between task texts and the titles of the given issues
+// according to strategy.
+// Returns the updated items, the renames that settle the conflicts on GitHub, and the issues
+// whose conflicts were left unresolved.
+func resolveTitleConflicts(file github.TodoFile, todoItems []todo.TodoItem, githubIssues []github.GitHubIssue, issueNumbers []uint64, strategy cli.ConflictStrategy) ([]todo.TodoItem, []github.TodoOperation, []uint64, error) {
+	conflicts := github.FindTitleConflicts(todoItems, githubIssues, issueNumbers)
+	if len(conflicts) == 0 {
+		return todoItems, nil, nil, nil
+	}
+	if strategy == cli.StrategyFail {
+		return nil, nil, nil, &errs.ConflictError{Err: conflictsError(titleConflictDescriptions(file.Path, conflicts))}
+	}
+
+	titles, err := chooseTitles(file.Path, conflicts, strategy)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var unresolved []uint64
+	for _, conflict := range conflicts {
+		if _, ok := titles[conflict.Number]; !ok {
+			unresolved = append(unresolved, conflict.Number)
+		}
+	}
+
+	updatedItems, renames := github.ResolveTitleConflicts(todoItems, githubIssues, titles)
+	return updatedItems, renames, unresolved, nil
+}
+
+// chooseTitles chooses the titles settling title conflicts according to strategy.
+// With the default strategy, each conflict is asked about when a terminal is attached.
+// Conflicts left unresolved have no title.
+func chooseTitles(path string, conflicts []github.TitleConflict, strategy cli.ConflictStrategy) (map[uint64]string, error) {
+	titles := make(map[uint64]string)
+	for _, conflict := range conflicts {
+		switch strategy {
+		case cli.StrategyOurs:
+			titles[conflict.Number] = conflict.Local
+		case cli.StrategyTheirs:
+			titles[conflict.Number] = conflict.Remote
+		default:
+			if !isInteractive() {
+				continue
+			}
+			title, ok, err := promptTitleConflict(path, conflict)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				titles[conflict.Number] = title
+			}
+		}
+	}
+	return titles, nil
+}
+
+// chooseCloseStates chooses whether the issues of state conflicts are cancelled according to strategy.
+// With the default strategy, each conflict is asked about when a terminal is attached.
+// Conflicts left unresolved have no state.
+func chooseCloseStates(path string, conflicts []github.StateConflict, strategy cli.ConflictStrategy) (map[uint64]bool, error) {
+	cancelled := make(map[uint64]bool)
+	for _, conflict := range conflicts {
+		switch strategy {
+		case cli.StrategyOurs:
+			cancelled[conflict.Number] = conflict.LocalCancelled
+		case cli.StrategyTheirs:
+			cancelled[conflict.Number] = conflict.RemoteCancelled
+		default:
+			if !isInteractive() {
+				continue
+			}
+			state, ok, err := promptStateConflict(path, conflict)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				cancelled[conflict.Number] = state
+			}
+		}
+	}
+	return cancelled, nil
+}
+
+// titleConflictDescriptions describes the title conflicts of a TODO file
+func titleConflictDescriptions(path string, conflicts []github.TitleConflict) []string {
+	descriptions := make([]string, len(conflicts))
+	for i, conflict := range conflicts {
+		descriptions[i] = fmt.Sprintf("#%d has title %q on GitHub and %q in %s", conflict.Number, conflict.Remote, conflict.Local, path)
+	}
+	return descriptions
+}
+
+// stateConflictDescriptions describes the state conflicts of a TODO file
+func stateConflictDescriptions(path string, conflicts []github.StateConflict) []string {
+	descriptions := make([]string, len(conflicts))
+	for i, conflict := range conflicts {
+		descriptions[i] = fmt.Sprintf("#%d is %s on GitHub and %s in %s", conflict.Number, closeStateName(conflict.RemoteCancelled), closeStateName(conflict.LocalCancelled), path)
+	}
+	return descriptions
+}
+
+// closeStateName names the state of a closed issue or checked task
+func closeStateName(cancelled bool) string {
+	if cancelled {
+		return "not planned"
+	}
+	return "completed"
+}
+
+// conflictsError returns the error stopping a command with --strategy=fail
+func conflictsError(descriptions []string) error {
+	return fmt.Errorf("conflicts: %s; use --strategy=ours or --strategy=theirs to settle them", strings.Join(descriptions, "; "))
+}
+
+// stdinReader reads the answers to prompts
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// promptTitleConflict asks which title to keep for a conflicting issue.
+// Returns false if the conflict is skipped or there is no more input.
+func promptTitleConflict(path string, conflict github.TitleConflict) (string, bool, error) {
+	fmt.Printf("Issue #%d has a different title on GitHub than in %s:\n", conflict.Number, path)
+	fmt.Printf("  local:  %s\n", conflict.Local)
+	fmt.Printf("  remote: %s\n", conflict.Remote)
+
+	for {
+		answer, err := prompt("Keep [l]ocal, keep [r]emote, [e]dit or [s]kip? ")
+		if err == io.EOF {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+
+		choice, ok := cli.ParseConflictChoice(answer)
+		if !ok {
+			continue
+		}
+		switch choice {
+		case cli.ChoiceLocal:
+			return conflict.Local, true, nil
+		case cli.ChoiceRemote:
+			return conflict.Remote, true, nil
+		case cli.ChoiceEdit:
+			title, err := prompt("New title: ")
+			if err == io.EOF {
+				return "", false, nil
+			}
+			if err != nil {
+				return "", false, err
+			}
+			if strings.TrimSpace(title) == "" {
+				continue
+			}
+			return strings.TrimSpace(title), true, nil
+		default:
+			return "", false, nil
+		}
+	}
+}
+
+// promptStateConflict asks whether a task and its closed issue are cancelled.
+// Returns false if the conflict is skipped or there is no more input.
+func promptStateConflict(path string, conflict github.StateConflict) (bool, bool, error) {
+	fmt.Printf("Issue #%d was closed with a different reason on GitHub than in %s:\n", conflict.Number, path)
+	fmt.Printf("  local:  %s\n", closeStateName(conflict.LocalCancelled))
+	fmt.Printf("  remote: %s\n", closeStateName(conflict.RemoteCancelled))
+
+	for {
+		answer, err := prompt("Keep [l]ocal, keep [r]emote or [s]kip? ")
+		if err == io.EOF {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, err
+		}
+
+		choice, ok := cli.ParseConflictChoice(answer)
+		if !ok {
+			continue
+		}
+		switch choice {
+		case cli.ChoiceLocal:
+			return conflict.LocalCancelled, true, nil
+		case cli.ChoiceRemote:
+			return conflict.RemoteCancelled, true, nil
+		case cli.ChoiceSkip:
+			return false, false, nil
+		}
+	}
+}
+
+// linkSimilarIssues offers to link the tasks of file that reference no issue to open issues
+// with similar titles, and returns the updated items and the candidates held back.
+//
+// Links are confirmed at a prompt when running interactively. Candidates that are not
+// confirmed or declined are reported and held back, so no duplicate task or issue is created.
+func linkSimilarIssues(file github.TodoFile, githubIssues []github.GitHubIssue) ([]todo.TodoItem, []github.LinkCandidate, error) {
+	var confirmed, held []github.LinkCandidate
+	for _, candidate := range github.FindLinkCandidates(file.Items, githubIssues) {
+		answered := false
+		if isInteractive() {
+			link, ok, err := promptLinkCandidate(file.Path, candidate)
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok && link {
+				confirmed = append(confirmed, candidate)
+			}
+			answered = ok
+		}
+		if answered {
+			continue
+		}
+
+		held = append(held, candidate)
+		reporter.Warn("link_candidate",
+			fmt.Sprintf("task %q in %s looks like issue #%d %q; add #%d to the task to link it, or run interactively to confirm", candidate.Item.Text, file.Path, candidate.Issue.Number, candidate.Issue.Title, candidate.Issue.Number),
+			output.Fields{"file": file.Path, "repo": file.Repo, "issue": candidate.Issue.Number, "text": candidate.Item.Text, "title": candidate.Issue.Title, "distance": candidate.Distance})
+	}
+
+	for _, candidate := range confirmed {
+		reporter.Info("issue_linked",
+			fmt.Sprintf("Linked task to issue #%d: %s", candidate.Issue.Number, candidate.Issue.Title),
+			output.Fields{"file": file.Path, "repo": file.Repo, "issue": candidate.Issue.Number, "text": candidate.Item.Text, "title": candidate.Issue.Title})
+	}
+
+	return github.LinkCandidates(file.Items, confirmed), held, nil
+}
+
+// promptLinkCandidate asks whether a task is linked to an issue with a similar title.
+// Returns false as the second value if there is no more input.
+func promptLinkCandidate(path string, candidate github.LinkCandidate) (bool, bool, error) {
+	fmt.Printf("Task in %s looks like an existing issue:\n", path)
+	fmt.Printf("  task:  %s\n", candidate.Item.Text)
+	fmt.Printf("  issue: #%d %s\n", candidate.Issue.Number, candidate.Issue.Title)
+
+	for {
+		answer, err := prompt("Link the task to the issue? [y/N] ")
+		if err == io.EOF {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, err
+		}
+
+		if link, ok := cli.ParseConfirmation(answer); ok {
+			return link, true, nil
+		}
+	}
+}
+
+// prompt prints question and reads a line of input.
+// Returns io.EOF if the input is closed before an answer.
+func prompt(question string) (string, error) {
+	fmt.Print(question)
+	answer, err := stdinReader.ReadString('\n')
+	if err == io.EOF && answer != "" {
+		return answer, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return answer, nil
+}
+
+// isInteractive checks if conflicts can be asked about: output is text and
+// both stdin and stdout are terminals
+func isInteractive() bool {
+	return !reporter.JSON() && isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// isTerminal checks if f is a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func runClean(dryRun bool, fileFlag string, overrides cli.ConfigOverrides) error {
+	// Keep other runs from changing the project files at the same time,
+	// and record the run so it can be undone
+	journal := history.NewEntry(history.CommandClean, time.Now())
+	journal.User = currentUser()
+	if !dryRun {
+		unlock, err := lockProject()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		defer saveJournal(journal)
+	}
+
+	// Load configuration
+	configMap, err := loadConfig(overrides)
+	if err != nil {
+		return err
+	}
+
+	options, err := parseOptions(configMap)
+	if err != nil {
+		return err
+	}
+
+	// Read the tracked TODO files
+	todoFiles, selected, readStates, err := readTodoFiles(configMap, fileFlag, true)
+	if err != nil {
+		return err
+	}
+
+	issuesByRepo := make(map[string][]github.GitHubIssue)
+	for _, i := range selected {
+		file := todoFiles[i]
+
+		// Build clean candidates from checked items with issue numbers
+		var candidates []clean.CleanCandidate
+		for _, item := range file.Items {
+			if candidate, ok := clean.NewCleanCandidate(item); ok {
+				candidates = append(candidates, candidate)
+			}
+		}
+
+		// Fetch GitHub issues
+		journal.AddRepository(file.Repo)
+		githubIssues, err := fetchGitHubIssuesCached(issuesByRepo, file.Repo)
+		if err != nil {
+			return partialFailure(journal, err)
+		}
+
+		// Find removable items
+		removable := clean.FindRemovableItems(candidates, githubIssues)
+
+		if len(removable) == 0 {
+			continue
+		}
+
+		printFileHeader(file.Path, len(selected))
+
+		// Build sets of removable IDs and issue numbers for quick lookup
+		removableIDs := make(map[string]bool)
+		removableSet := make(map[uint64]bool)
+		for _, r := range removable {
+			if r.ID != "" {
+				removableIDs[r.ID] = true
+			} else {
+				removableSet[r.IssueNumber] = true
+			}
+			reporter.Info("task_removed",
+				fmt.Sprintf("Removing: %s (#%d)", r.Text, r.IssueNumber),
+				output.Fields{"file": file.Path, "repo": file.Repo, "issue": r.IssueNumber, "text": r.Text, "dry_run": dryRun})
+		}
+
+		if dryRun {
+			continue
+		}
+
+		// Filter out removable items from the todo list
+		var remaining []todo.TodoItem
+		for _, item := range file.Items {
+			if item.ID != "" && removableIDs[item.ID] ||
+				item.ID == "" && item.IssueNumber != nil && removableSet[*item.IssueNumber] {
+				continue
+			}
+			remaining = append(remaining, item)
+		}
+
+		// Write updated TODO file
+		if err := writeTodoFile(file.Path, readStates[i], file.Items, remaining, options, journal); err != nil {
+			return partialFailure(journal, err)
+		}
+	}
+
+	return nil
+}
+
+func runStatus(overdueOnly bool, fileFlag string, overrides cli.ConfigOverrides) error {
+	// Configuration is optional, and missing files load as empty. Tasks are listed from
+	// the files alone if GitHub can't be reached.
+	configMap, err := loadConfig(overrides)
+	if err != nil {
+		return err
+	}
+
+	// Read the tracked TODO files
+	todoFiles, selected, _, err := readTodoFiles(configMap, fileFlag, false)
+	if err != nil {
+		return err
+	}
+
+	issuesByRepo := make(map[string][]github.GitHubIssue)
+	offline := false
+	for _, i := range selected {
+		file := todoFiles[i]
+		printFileHeader(file.Path, len(selected))
+
+		// Report orphaned references when a repository is configured and GitHub can be reached
+		if file.Repo != "" && !offline {
+			err := reportOrphanedReferences(file, issuesByRepo)
+			var networkErr *errs.NetworkError
+			if errors.As(err, &networkErr) {
+				offline = true
+				reporter.Warn("orphans_unchecked",
+					fmt.Sprintf("could not reach GitHub, so references to deleted or moved issues are not checked: %v", err),
+					output.Fields{"file": file.Path, "repo": file.Repo})
+			} else if err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		for _, item := range file.Items {
+			overdue := len(todo.FilterOverdue([]todo.TodoItem{item}, now)) > 0
+			if overdueOnly && !overdue {
+				continue
+			}
+
+			line := strings.TrimSuffix(markdown.SerializeTodoMarkdown([]todo.TodoItem{item}), "\n")
+			if overdue {
+				line += " [overdue]"
+			}
+			reporter.Info("task", line, taskFields(file.Path, item, overdue))
+		}
+	}
+
+	return nil
+}
+
+// reportOrphanedReferences reports the references of a TODO file to issues that were
+// deleted, transferred or turned into pull requests. Issues are fetched once per repository.
+func reportOrphanedReferences(file github.TodoFile, issuesByRepo map[string][]github.GitHubIssue) error {
+	githubIssues, err := fetchGitHubIssuesCached(issuesByRepo, file.Repo)
+	if err != nil {
+		return err
+	}
+	orphans, err := github.CollectOrphanedReferences(file.Items, githubIssues, file.Repo, func(issueNumber uint64) (json.RawMessage, error) {
+		return lookupIssue(file.Repo, issueNumber)
+	})
+	if err != nil {
+		return err
+	}
+	printOrphanedReferences(file.Repo, orphans)
+	return nil
+}
+
+func runRemoteList() error {
+	configMap, err := loadConfig(nil)
+	if err != nil {
+		return err
+	}
+
+	repos, err := config.StringList(configMap, config.Repositories)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		reporter.Info("repository", repo, output.Fields{"repo": repo})
+	}
+
+	return nil
+}
+
+func runRemoteAdd(repo string) error {
+	// Keep other runs from changing the project files at the same time
+	unlock, err := lockProject()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	configStorage, err := storage.NewLocalConfigStorage()
+	if err != nil {
+		return fmt.Errorf("error initializing config storage: %w", err)
+	}
+
+	configMap, err := configStorage.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	// Get or create repositories array
+	var reposArray []interface{}
+	if reposValue, ok := configMap[config.Repositories]; ok {
+		if arr, ok := reposValue.([]interface{}); ok {
+			reposArray = arr
+		}
+	}
+
+	// Check if repository already exists
+	for _, repoVal := range reposArray {
+		if repoStr, ok := repoVal.(string); ok && repoStr == repo {
+			// Already exists, nothing to do
+			return nil
+		}
+	}
+
+	// Check if repository exists on GitHub
+	exists, err := checkRepoExists(repo)
+	if err != nil {
+		return fmt.Errorf("failed to check repository %s: %w", repo, err)
+	}
+	if !exists {
+		return fmt.Errorf("repository %s not found or not accessible", repo)
+	}
+
+	// Add repository
+	reposArray = append(reposArray, repo)
+	configMap[config.Repositories] = reposArray
+
+	// Save configuration
+	if err := configStorage.SaveConfig(configMap); err != nil {
+		return fmt.Errorf("error saving project config: %w", err)
+	}
+
+	return nil
+}
+
+func runRemoteRemove(repo string) error {
+	// Keep other runs from changing the project files at the same time
+	unlock, err := lockProject()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	configStorage, err := storage.NewLocalConfigStorage()
+	if err != nil {
+		return fmt.Errorf("error initializing config storage: %w", err)
+	}
+
+	configMap, err := configStorage.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	// Get repositories array
+	var reposArray []interface{}
+	if reposValue, ok := configMap[config.Repositories]; ok {
+		if arr, ok := reposValue.([]interface{}); ok {
+			reposArray = arr
+		}
+	}
+
+	// Filter out the repository
+	var filteredRepos []interface{}
+	for _, repoVal := range reposArray {
+		if repoStr, ok := repoVal.(string); ok && repoStr != repo {
+			filteredRepos = append(filteredRepos, repoStr)
+		}
+	}
+
+	// Update configuration
+	if len(filteredRepos) == 0 {
+		delete(configMap, config.Repositories)
+	} else {
+		configMap[config.Repositories] = filteredRepos
+	}
+
+	// Save configuration
+	if err := configStorage.SaveConfig(configMap); err != nil {
+		return fmt.Errorf("error saving project config: %w", err)
+	}
+
+	return nil
+}
+
+func runConfigList(scope cli.ConfigScope) error {
+	configStorage, err := storage.NewLayeredConfigStorage()
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	if scope != cli.ConfigScopeDefault {
+		configMap, err := configStorage.LoadScope(configScope(scope))
+		if err != nil {
+			return err
+		}
+		for _, spec := range config.Registry() {
+			if value, ok := configMap[spec.Key]; ok {
+				reporter.Info("config_value",
+					fmt.Sprintf("%s=%s", spec.Key, config.FormatValue(value)),
+					output.Fields{"key": spec.Key, "value": value, "origin": configScope(scope)})
+			}
+		}
+		return nil
+	}
+
+	configMap, origins, err := configStorage.LoadConfigWithOrigins()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	printConfigWarnings(configStorage)
+	configMap, origins, err = config.ApplyOverrides(configMap, origins, os.LookupEnv, nil)
+	if err != nil {
+		return &errs.ParseError{Err: err}
+	}
+	for _, spec := range config.Registry() {
+		if value, ok := configMap[spec.Key]; ok {
+			reporter.Info("config_value",
+				fmt.Sprintf("%s\t%s=%s", origins[spec.Key], spec.Key, config.FormatValue(value)),
+				output.Fields{"key": spec.Key, "value": value, "origin": origins[spec.Key]})
+		} else if spec.Default != nil {
+			reporter.Info("config_value",
+				fmt.Sprintf("default\t%s=%s", spec.Key, config.FormatValue(spec.Default)),
+				output.Fields{"key": spec.Key, "value": spec.Default, "origin": "default"})
+		}
+	}
+
+	return nil
+}
+
+func runConfigGet(key string, scope cli.ConfigScope) error {
+	spec, err := lookupConfigKey(key)
+	if err != nil {
+		return err
+	}
+
+	var configMap map[config.ConfigKey]any
+	if scope == cli.ConfigScopeDefault {
+		configMap, err = loadConfig(nil)
+		if err != nil {
+			return err
+		}
+	} else {
+		configStorage, err := storage.NewLayeredConfigStorage()
+		if err != nil {
+			return fmt.Errorf("failed to read configuration: %w", err)
+		}
+		configMap, err = configStorage.LoadScope(configScope(scope))
+		if err != nil {
+			return fmt.Errorf("error loading config: %w", err)
+		}
+	}
+
+	value, ok := configMap[spec.Key]
+	if !ok && scope == cli.ConfigScopeDefault && spec.Default != nil {
+		value, ok = spec.Default, true
+	}
+	if !ok {
+		return fmt.Errorf("%s is not set", key)
+	}
+
+	reporter.Info("config_value", config.FormatValue(value), output.Fields{"key": spec.Key, "value": value})
+	return nil
+}
+
+func runConfigSet(key, input string, scope cli.ConfigScope) error {
+	spec, err := lookupConfigKey(key)
+	if err != nil {
+		return err
+	}
+
+	value, err := spec.ParseValue(input)
+	if err != nil {
+		return &errs.ParseError{Err: fmt.Errorf("invalid value for %w", err)}
+	}
+
+	configStorage, err := storage.NewLayeredConfigStorage()
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	target := configScope(scope)
+	if target == config.ScopeProject {
+		unlock, err := lockProject()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	configMap, err := configStorage.LoadScope(target)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	configMap[spec.Key] = value
+	if err := configStorage.SaveScope(target, configMap); err != nil {
+		return fmt.Errorf("error saving config: %w", err)
+	}
+
+	return nil
+}
+
+func runConfigUnset(key string, scope cli.ConfigScope) error {
+	spec, err := lookupConfigKey(key)
+	if err != nil {
+		return err
+	}
+
+	configStorage, err := storage.NewLayeredConfigStorage()
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	target := configScope(scope)
+	if target == config.ScopeProject {
+		unlock, err := lockProject()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	configMap, err := configStorage.LoadScope(target)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	if _, ok := configMap[spec.Key]; !ok {
+		return fmt.Errorf("%s is not set in the %s config", key, target)
+	}
+
+	delete(configMap, spec.Key)
+	if err := configStorage.SaveScope(target, configMap); err != nil {
+		return fmt.Errorf("error saving config: %w", err)
+	}
+
+	return nil
+}
+
+// runLog lists the recorded runs, most recent first
+func runLog(sinceFlag string) error {
+	historyStorage, err := storage.NewHistoryStorage()
+	if err != nil {
+		return err
+	}
+	entries, err := historyStorage.List()
+	if err != nil {
+		return err
+	}
+
+	if sinceFlag != "" {
+		since, err := history.ParseSince(sinceFlag, time.Now())
+		if err != nil {
+			return err
+		}
+		entries = history.Since(entries, since)
+	}
+	// Show the most recent runs first
+	slices.Reverse(entries)
+
+	for i, entry := range entries {
+		if i > 0 {
+			reporter.Heading("")
+		}
+		reportLogEntry(entry)
+	}
+	return nil
+}
+
+// reportLogEntry reports a history entry for `gh atat log`.
+// In JSON format, each entry is a single "run" event with the fields of history.LogRecord.
+func reportLogEntry(entry history.Entry) {
+	header := fmt.Sprintf("%s %s", entry.Time.Local().Format(time.DateTime), entry.Command)
+	if entry.User != "" {
+		header += " by " + entry.User
+	}
+	if len(entry.Repositories) > 0 {
+		header += " on " + strings.Join(entry.Repositories, ", ")
+	}
+	if entry.UndoneAt != nil {
+		header += " (undone)"
+	}
+	if reporter.JSON() {
+		reporter.Info("run", header, logRecordFields(history.NewLogRecord(entry)))
+		return
+	}
+
+	reporter.Heading(header)
+	for _, op := range entry.Operations {
+		reporter.Heading("  " + op.String())
+	}
+	for _, file := range entry.Files {
+		reporter.Heading("  updated " + file.Path)
+	}
+}
+
+// logRecordFields returns the fields of a log record as event fields
+func logRecordFields(record history.LogRecord) output.Fields {
+	fields := output.Fields{
+		"id":           record.ID,
+		"command":      record.Command,
+		"time":         record.Time,
+		"repositories": record.Repositories,
+		"files":        record.Files,
+		"operations":   record.Operations,
+	}
+	if record.User != "" {
+		fields["user"] = record.User
+	}
+	if record.UndoneAt != nil {
+		fields["undone_at"] = record.UndoneAt
+	}
+	return fields
+}
+
+func runUndo(force bool) error {
+	unlock, err := lockProject()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	historyStorage, err := storage.NewHistoryStorage()
+	if err != nil {
+		return err
+	}
+	entries, err := historyStorage.List()
+	if err != nil {
+		return err
+	}
+	entry, ok := history.LatestUndoable(entries)
+	if !ok {
+		return fmt.Errorf("nothing to undo")
+	}
+
+	rootDir, err := storage.ProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	// Don't overwrite edits made after the run
+	if !force {
+		for _, file := range entry.Files {
+			content, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(file.Path)))
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to read %s: %w", file.Path, err)
+			}
+			if err != nil || contentHash(content) != file.WrittenHash {
+				return &errs.ConflictError{Err: fmt.Errorf("%s was changed after the %s run; use --force to restore it anyway", file.Path, entry.Command)}
+			}
+		}
+	}
+
+	reporter.Info("undo_started",
+		fmt.Sprintf("Undoing %s run of %s", entry.Command, entry.Time.Local().Format(time.DateTime)),
+		output.Fields{"id": entry.ID, "command": entry.Command, "time": entry.Time})
+
+	reversed, irreversible := history.ReverseOperations(entry)
+	for i, op := range reversed {
+		if err := applyHistoryOperation(op); err != nil {
+			if i > 0 {
+				return &errs.PartialFailureError{Changes: i, Err: err}
+			}
+			return err
+		}
+		reporter.Info("operation_undone", fmt.Sprintf("Undo: %s", op), operationFields(op))
+	}
+	for _, op := range irreversible {
+		reporter.Warn("operation_not_undone", fmt.Sprintf("can't undo: %s", op), operationFields(op))
+	}
+
+	restored := 0
+	for _, file := range entry.Files {
+		path := filepath.Join(rootDir, filepath.FromSlash(file.Path))
+		if file.Existed {
+			err = storage.WriteFileAtomic(path, []byte(file.Content), 0644)
+		} else if err = os.Remove(path); os.IsNotExist(err) {
+			err = nil
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to restore %s: %w", file.Path, err)
+			if changes := len(reversed) + restored; changes > 0 {
+				return &errs.PartialFailureError{Changes: changes, Err: err}
+			}
+			return err
+		}
+		restored++
+		reporter.Info("file_restored", fmt.Sprintf("Restored %s", file.Path), output.Fields{"file": file.Path})
+	}
+
+	undoneAt := time.Now().UTC()
+	entry.UndoneAt = &undoneAt
+	return historyStorage.Save(entry)
+}
+
+// applyHistoryOperation makes an operation undoing a recorded operation on GitHub
+func applyHistoryOperation(op history.Operation) error {
+	switch op.Kind {
+	case history.OperationClose:
+		return closeGitHubIssue(op.Repo, int(op.Number), github.IssueStateReason(op.Reason))
+	case history.OperationReopen:
+		return reopenGitHubIssue(op.Repo, int(op.Number))
+	case history.OperationRename:
+		return renameGitHubIssue(op.Repo, int(op.Number), op.Title)
+	default:
+		return fmt.Errorf("can't apply %s operation to issue #%d", op.Kind, op.Number)
+	}
+}
+
+// lookupConfigKey returns the spec of a configuration key given on the command line
+func lookupConfigKey(key string) (config.KeySpec, error) {
+	spec, ok := config.LookupKey(key)
+	if !ok {
+		keys := make([]string, 0, len(config.AllConfigKeys()))
+		for _, k := range config.AllConfigKeys() {
+			keys = append(keys, string(k))
+		}
+		return config.KeySpec{}, fmt.Errorf("unknown config key: %s. Valid keys: %s", key, strings.Join(keys, ", "))
+	}
+	return spec, nil
+}
+
+// configScope returns the configuration file a config subcommand works on.
+// Without --global or --local, changes go to the project configuration.
+func configScope(scope cli.ConfigScope) config.Scope {
+	if scope == cli.ConfigScopeGlobal {
+		return config.ScopeUser
+	}
+	return config.ScopeProject
+}
+
+func getFirstRepository(configMap map[config.ConfigKey]any) (string, error) {
+	repos, err := config.StringList(configMap, config.Repositories)
+	if err != nil {
+		return "", err
+	}
+	if len(repos) == 0 {
+		return "", fmt.Errorf("no repository configured")
+	}
+
+	return repos[0], nil
+}
+
+// getDueTarget returns where due dates are synchronized to, or "" if they aren't
+func getDueTarget(configMap map[config.ConfigKey]any) (string, error) {
+	return config.String(configMap, config.Due)
+}
+
+// isMergedPullRequestDone reports whether tasks are marked as done when a linked pull request is merged
+func isMergedPullRequestDone(configMap map[config.ConfigKey]any) (bool, error) {
+	action, err := config.String(configMap, config.MergedPullRequests)
+	return action == config.MergedPullRequestsDone, err
+}
+
+// isFuzzyMatch reports whether tasks are offered links to issues with similar titles
+func isFuzzyMatch(configMap map[config.ConfigKey]any) (bool, error) {
+	match, err := config.String(configMap, config.Match)
+	return match == config.MatchFuzzy, err
+}
+
+// getPlacement returns where tasks of pulled issues are placed, as configured
+func getPlacement(configMap map[config.ConfigKey]any) (github.Placement, error) {
+	var placement github.Placement
+
+	section, err := config.String(configMap, config.Placement)
+	if err != nil {
+		return github.Placement{}, err
+	}
+	switch section {
+	case config.PlacementInbox:
+		placement.Section = github.PlaceInInbox
+	case config.PlacementLabel:
+		placement.Section = github.PlaceByLabel
+	case config.PlacementMilestone:
+		placement.Section = github.PlaceByMilestone
+	}
+
+	order, err := config.String(configMap, config.Order)
+	if err != nil {
+		return github.Placement{}, err
+	}
+	switch order {
+	case config.OrderNumber:
+		placement.Order = github.OrderByNumber
+	case config.OrderCreated:
+		placement.Order = github.OrderByCreated
+	case config.OrderPriority:
+		placement.Order = github.OrderByPriority
+	}
+
+	placement.PriorityLabels, err = config.StringList(configMap, config.PriorityLabels)
+	if err != nil {
+		return github.Placement{}, err
+	}
+
+	return placement, nil
+}
+
+// isItemIDs reports whether hidden IDs are added to tasks
+func isItemIDs(configMap map[config.ConfigKey]any) (bool, error) {
+	marker, err := config.String(configMap, config.ItemIDs)
+	return marker == config.ItemIDsComment, err
+}
+
+// newItemID returns a random hidden ID for a task
+func newItemID() string {
+	const alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+	id := make([]byte, 8)
+	for i := range id {
+		id[i] = alphabet[rand.IntN(len(alphabet))]
+	}
+	return string(id)
+}
+
+// parseOptions returns how the tasks of TODO files are read, as configured
+func parseOptions(configMap map[config.ConfigKey]any) (markdown.ParseOptions, error) {
+	inlineCode, err := config.String(configMap, config.InlineCode)
+	if err != nil {
+		return markdown.ParseOptions{}, err
+	}
+	ignoreSections, err := config.StringList(configMap, config.IgnoreSections)
+	if err != nil {
+		return markdown.ParseOptions{}, err
+	}
+
+	return markdown.ParseOptions{
+		KeepInlineCode: inlineCode == config.InlineCodeKeep,
+		IgnoreSections: ignoreSections,
+	}, nil
+}
+
+// runOptions is the configuration of a push, pull or sync run, resolved once from the
+// configuration and the command line flags
+type runOptions struct {
+	orphanAction cli.OrphanAction
+	strategy     cli.ConflictStrategy
+	// dueSync reports whether due dates are synchronized with milestones, and projectDue
+	// whether they are synchronized with the Due field of the project board
+	dueSync    bool
+	projectDue bool
+	// project is the project board issues are placed on if hasProject is true
+	project    projectRef
+	hasProject bool
+	// board is the project board, once fetched with fetchBoard
+	board          github.ProjectBoard
+	markMergedDone bool
+	fuzzyMatch     bool
+	itemIDs        bool
+	placement      github.Placement
+	parse          markdown.ParseOptions
+}
+
+// resolveRunOptions resolves the configuration of a push, pull or sync run
+func resolveRunOptions(configMap map[config.ConfigKey]any, orphanAction cli.OrphanAction, strategy cli.ConflictStrategy) (runOptions, error) {
+	opts := runOptions{orphanAction: orphanAction, strategy: strategy}
+
+	due, err := getDueTarget(configMap)
+	if err != nil {
+		return runOptions{}, err
+	}
+	opts.dueSync = due == config.DueMilestone
+	opts.projectDue = due == config.DueProject
+	if opts.project, opts.hasProject, err = getFirstProject(configMap); err != nil {
+		return runOptions{}, err
+	}
+	if opts.projectDue && !opts.hasProject {
+		return runOptions{}, &errs.ParseError{Err: fmt.Errorf("due is set to %q, but no project is configured in projects", config.DueProject)}
+	}
+	if opts.markMergedDone, err = isMergedPullRequestDone(configMap); err != nil {
+		return runOptions{}, err
+	}
+	if opts.fuzzyMatch, err = isFuzzyMatch(configMap); err != nil {
+		return runOptions{}, err
+	}
+	if opts.itemIDs, err = isItemIDs(configMap); err != nil {
+		return runOptions{}, err
+	}
+	if opts.placement, err = getPlacement(configMap); err != nil {
+		return runOptions{}, err
+	}
+	if opts.parse, err = parseOptions(configMap); err != nil {
+		return runOptions{}, err
+	}
+
+	return opts, nil
+}
+
+// fetchBoard fetches the project board of the run, if one is configured
+func (opts *runOptions) fetchBoard() error {
+	if !opts.hasProject {
+		return nil
+	}
+	board, err := fetchProjectBoard(opts.project)
+	if err != nil {
+		return err
+	}
+	if opts.projectDue && board.DueDateFieldID == "" {
+		return &errs.NotFoundError{Err: fmt.Errorf("project has no date field named %q", github.ProjectDueDateField)}
+	}
+	opts.board = board
+	return nil
+}
+
+// projectRef identifies a GitHub Projects v2 board by its owner and number
+type projectRef struct {
+	Owner  string
+	Number int
+}
+
+// getFirstProject returns the first configured project board, if any
+func getFirstProject(configMap map[config.ConfigKey]any) (projectRef, bool, error) {
+	projects, err := config.StringList(configMap, config.Projects)
+	if err != nil || len(projects) == 0 {
+		return projectRef{}, false, err
+	}
+
+	// The registry checked the project is <owner>/<number>
+	owner, numberStr, _ := strings.Cut(projects[0], "/")
+	number, _ := strconv.Atoi(numberStr)
+
+	return projectRef{Owner: owner, Number: number}, true, nil
+}
+
+func fetchGitHubIssues(repo string) ([]github.GitHubIssue, error) {
+	fetchFunc := func(repo string, token string, page int, perPage int) ([]json.RawMessage, error) {
+		endpoint := fmt.Sprintf("repos/%s/issues?state=all&per_page=%d&page=%d", repo, perPage, page)
+		data, err := ghAPI(endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		var issues []json.RawMessage
+		if err := json.Unmarshal(data, &issues); err != nil {
+			return nil, err
+		}
+		return issues, nil
+	}
+
+	return github.FetchGitHubIssues(repo, "", fetchFunc)
+}
+
+// synchronizePullRequests shows the pull requests linked to each task of repo, and marks
+// tasks with a merged pull request as done if markMergedDone is true.
+func synchronizePullRequests(repo string, todoItems []todo.TodoItem, githubIssues []github.GitHubIssue, markMergedDone bool) ([]todo.TodoItem, error) {
+	links, pullRequests, err := fetchLinkedPullRequests(repo, todoItems, githubIssues)
+	if err != nil {
+		return nil, err
+	}
+	return github.SynchronizePullRequests(todoItems, pullRequests, links, markMergedDone), nil
+}
+
+// fetchLinkedPullRequests looks up the pull requests linked to the tasks of repo, keyed by
+// issue number. Pull requests are only looked up if a task may have any.
+func fetchLinkedPullRequests(repo string, todoItems []todo.TodoItem, githubIssues []github.GitHubIssue) (map[uint64][]uint64, []github.PullRequest, error) {
+	if !github.NeedsPullRequests(todoItems) {
+		return nil, nil, nil
+	}
+
+	owner, name, _ := strings.Cut(repo, "/")
+	return github.FetchLinkedPullRequests(todoItems, githubIssues, repo, func(issueNumbers []uint64) ([]byte, error) {
+		return ghGraphQL(github.LinkedPullRequestsQuery(issueNumbers), map[string]any{"owner": owner, "name": name})
+	})
+}
+
+func createGitHubIssue(repo, title string, labels []string) (github.GitHubIssue, error) {
+	body := map[string]any{"title": title}
+	if len(labels) > 0 {
+		body["labels"] = labels
+	}
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return github.GitHubIssue{}, err
+	}
+
+	output, err := ghAPIPost(fmt.Sprintf("repos/%s/issues", repo), string(bodyJSON))
+	if err != nil {
+		return github.GitHubIssue{}, err
+	}
+
+	issues := github.ParseGitHubIssues([]json.RawMessage{output})
+	if len(issues) == 0 {
+		return github.GitHubIssue{}, fmt.Errorf("unexpected response when creating issue")
+	}
+
+	return issues[0], nil
+}
+
+func closeGitHubIssue(repo string, number int, reason github.IssueStateReason) error {
+	body := map[string]string{"state": "closed"}
+	if reason != github.IssueStateReasonNone {
+		body["state_reason"] = string(reason)
+	}
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	_, err = ghAPIPatch(fmt.Sprintf("repos/%s/issues/%d", repo, number), string(bodyJSON))
+	return err
+}
+
+func reopenGitHubIssue(repo string, number int) error {
+	bodyJSON, err := json.Marshal(map[string]string{"state": "open"})
+	if err != nil {
+		return err
+	}
+
+	_, err = ghAPIPatch(fmt.Sprintf("repos/%s/issues/%d", repo, number), string(bodyJSON))
+	return err
+}
+
+func fetchMilestones(repo string) ([]github.Milestone, error) {
+	var milestones []github.Milestone
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("repos/%s/milestones?state=all&per_page=100&page=%d", repo, page)
+		data, err := ghAPI(endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		var milestonesJSON []json.RawMessage
+		if err := json.Unmarshal(data, &milestonesJSON); err != nil {
+			return nil, err
+		}
+		if len(milestonesJSON) == 0 {
+			return milestones, nil
+		}
+		milestones = append(milestones, github.ParseMilestones(milestonesJSON)...)
+	}
+}
+
+func createMilestone(repo string, dueDate time.Time) (github.Milestone, error) {
+	body := map[string]string{
+		"title":  github.MilestoneTitleForDueDate(dueDate),
+		"due_on": dueDate.UTC().Format(time.RFC3339),
+	}
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return github.Milestone{}, err
+	}
+
+	output, err := ghAPIPost(fmt.Sprintf("repos/%s/milestones", repo), string(bodyJSON))
+	if err != nil {
+		return github.Milestone{}, err
+	}
+
+	milestones := github.ParseMilestones([]json.RawMessage{output})
+	if len(milestones) == 0 {
+		return github.Milestone{}, fmt.Errorf("unexpected response when creating milestone")
+	}
+	return milestones[0], nil
+}
+
+func setIssueMilestone(repo string, number int, milestone uint64) error {
+	body := map[string]uint64{"milestone": milestone}
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	_, err = ghAPIPatch(fmt.Sprintf("repos/%s/issues/%d", repo, number), string(bodyJSON))
+	return err
+}
+
+// applyDueDateOperations assigns issues to the milestone due on their due date,
+// creating the milestone when none exists yet.
+func applyDueDateOperations(repo string, operations []github.TodoOperation, journal *history.Entry) error {
+	if len(operations) == 0 {
+		return nil
+	}
+
+	milestones, err := fetchMilestones(repo)
+	if err != nil {
+		return err
+	}
+
+	for _, todoOp := range operations {
+		op, ok := todoOp.Operation.(github.SetMilestoneOp)
+		if !ok {
+			continue
+		}
+
+		milestone, found := github.FindMilestoneByDueDate(milestones, op.DueDate)
+		if !found {
+			milestone, err = createMilestone(repo, op.DueDate)
+			if err != nil {
+				return err
+			}
+			milestones = append(milestones, milestone)
+			reporter.Info("milestone_created",
+				fmt.Sprintf("Created milestone %s", milestone.Title),
+				output.Fields{"repo": repo, "milestone": milestone.Title})
+		}
+
+		if err := setIssueMilestone(repo, int(op.Number), milestone.Number); err != nil {
+			return err
+		}
+		reporter.Info("milestone_set",
+			fmt.Sprintf("Set milestone of issue #%d: %s", op.Number, milestone.Title),
+			output.Fields{"repo": repo, "issue": op.Number, "milestone": milestone.Title})
+		journal.Record(history.Operation{Kind: history.OperationSetMilestone, Repo: repo, Number: op.Number, Title: milestone.Title})
+	}
+
+	return nil
+}
+
+func fetchIssueEvents(repo string, issueNumber uint64) ([]json.RawMessage, error) {
+	endpoint := fmt.Sprintf("repos/%s/issues/%d/events", repo, issueNumber)
+	data, err := ghAPI(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []json.RawMessage
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func renameGitHubIssue(repo string, number int, title string) error {
+	body := map[string]string{"title": title}
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	_, err = ghAPIPatch(fmt.Sprintf("repos/%s/issues/%d", repo, number), string(bodyJSON))
+	return err
+}
+
+const projectQuery = `query($owner: String!, $number: Int!, $cursor: String) {
+  repositoryOwner(login: $owner) {
+    ... on User { projectV2(number: $number) { ...board } }
+    ... on Organization { projectV2(number: $number) { ...board } }
+  }
+}
+fragment board on ProjectV2 {
+  id
+  field(name: "Status") {
+    ... on ProjectV2SingleSelectField { id options { id name } }
+  }
+  dueDateField: field(name: "Due") {
+    ... on ProjectV2Field { id dataType }
+  }
+  items(first: 100, after: $cursor) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      id
+      fieldValueByName(name: "Status") {
+        ... on ProjectV2ItemFieldSingleSelectValue { name }
+      }
+      dueDate: fieldValueByName(name: "Due") {
+        ... on ProjectV2ItemFieldDateValue { date }
+      }
+      content {
+        ... on Issue { number repository { nameWithOwner } }
+      }
+    }
+  }
+}`
+
+const addProjectItemMutation = `mutation($project: ID!, $content: ID!) {
+  addProjectV2ItemById(input: {projectId: $project, contentId: $content}) { item { id } }
+}`
+
+const setProjectStatusMutation = `mutation($project: ID!, $item: ID!, $field: ID!, $option: String!) {
+  updateProjectV2ItemFieldValue(input: {projectId: $project, itemId: $item, fieldId: $field, value: {singleSelectOptionId: $option}}) { projectV2Item { id } }
+}`
+
+const setProjectDateMutation = `mutation($project: ID!, $item: ID!, $field: ID!, $date: Date!) {
+  updateProjectV2ItemFieldValue(input: {projectId: $project, itemId: $item, fieldId: $field, value: {date: $date}}) { projectV2Item { id } }
+}`
+
+func fetchProjectBoard(project projectRef) (github.ProjectBoard, error) {
+	return github.FetchProjectBoard(func(cursor string) ([]byte, error) {
+		variables := map[string]any{"owner": project.Owner, "number": project.Number}
+		if cursor != "" {
+			variables["cursor"] = cursor
+		}
+		return ghGraphQL(projectQuery, variables)
+	})
+}
+
+func addProjectItem(board github.ProjectBoard, contentID string) (string, error) {
+	output, err := ghGraphQL(addProjectItemMutation, map[string]any{
+		"project": board.ID,
+		"content": contentID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Data struct {
+			AddProjectV2ItemByID struct {
+				Item struct {
+					ID string `json:"id"`
+				} `json:"item"`
+			} `json:"addProjectV2ItemById"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(output, &response); err != nil {
+		return "", err
+	}
+	return response.Data.AddProjectV2ItemByID.Item.ID, nil
+}
+
+func setProjectStatus(board github.ProjectBoard, itemID, optionID string) error {
+	_, err := ghGraphQL(setProjectStatusMutation, map[string]any{
+		"project": board.ID,
+		"item":    itemID,
+		"field":   board.StatusFieldID,
+		"option":  optionID,
+	})
+	return err
+}
+
+func setProjectDueDate(board github.ProjectBoard, itemID string, dueDate time.Time) error {
+	_, err := ghGraphQL(setProjectDateMutation, map[string]any{
+		"project": board.ID,
+		"item":    itemID,
+		"field":   board.DueDateFieldID,
+		"date":    dueDate.Format(todo.DueDateLayout),
+	})
+	return err
+}
 
-			// Update TODO item with issue number
-			issueNum := uint64(issueNumber)
-			for j := range updatedTodoItems {
-				if updatedTodoItems[j].Text == todoOp.Todo.Text &&
-					updatedTodoItems[j].IsChecked == todoOp.Todo.IsChecked &&
-					updatedTodoItems[j].IssueNumber == todoOp.Todo.IssueNumber {
-					updatedTodoItems[j].IssueNumber = &issueNum
+// applyProjectOperations adds issues to the project board and updates their status and due date.
+func applyProjectOperations(repo string, board github.ProjectBoard, githubIssues []github.GitHubIssue, operations []github.TodoOperation, journal *history.Entry) error {
+	for _, todoOp := range operations {
+		switch op := todoOp.Operation.(type) {
+		case github.AddProjectItemOp:
+			var contentID string
+			for _, issue := range githubIssues {
+				if issue.Number == op.Number {
+					contentID = issue.NodeID
 					break
 				}
 			}
-		case github.CloseIssueOp:
-			err := closeGitHubIssue(repo, int(op.Number))
+			if contentID == "" {
+				continue
+			}
+
+			itemID, err := addProjectItem(board, contentID)
 			if err != nil {
 				return err
 			}
-			fmt.Printf("Closed issue #%d\n", op.Number)
-		case github.RenameIssueOp:
-			err := renameGitHubIssue(repo, int(op.Number), op.Title)
-			if err != nil {
+			board.Items = append(board.Items, github.ProjectItem{
+				ID:          itemID,
+				Repository:  repo,
+				IssueNumber: op.Number,
+			})
+			reporter.Info("project_item_added",
+				fmt.Sprintf("Added issue #%d to project", op.Number),
+				output.Fields{"repo": repo, "issue": op.Number})
+			journal.Record(history.Operation{Kind: history.OperationAddToProject, Repo: repo, Number: op.Number})
+		case github.SetProjectStatusOp:
+			item, found := github.FindProjectItem(board, repo, op.Number)
+			if !found {
+				continue
+			}
+			if err := setProjectStatus(board, item.ID, op.OptionID); err != nil {
+				return err
+			}
+			reporter.Info("project_status_set",
+				fmt.Sprintf("Moved issue #%d to %s", op.Number, op.Status),
+				output.Fields{"repo": repo, "issue": op.Number, "status": op.Status})
+			journal.Record(history.Operation{Kind: history.OperationSetProjectStatus, Repo: repo, Number: op.Number, Title: op.Status})
+		case github.SetProjectDueDateOp:
+			item, found := github.FindProjectItem(board, repo, op.Number)
+			if !found {
+				continue
+			}
+			if err := setProjectDueDate(board, item.ID, op.DueDate); err != nil {
 				return err
 			}
-			fmt.Printf("Renamed issue #%d: %s\n", op.Number, op.Title)
+			dueDate := op.DueDate.Format(todo.DueDateLayout)
+			reporter.Info("project_due_date_set",
+				fmt.Sprintf("Set due date of issue #%d on project to %s", op.Number, dueDate),
+				output.Fields{"repo": repo, "issue": op.Number, "due": dueDate})
+			journal.Record(history.Operation{Kind: history.OperationSetProjectDue, Repo: repo, Number: op.Number, Title: dueDate})
 		}
 	}
 
-	// Write updated TODO.md
-	updatedContent := markdown.SerializeTodoMarkdown(updatedTodoItems)
-	if err := os.WriteFile("TODO.md", []byte(updatedContent), 0644); err != nil {
-		return fmt.Errorf("failed to write TODO.md: %w", err)
-	}
-
 	return nil
 }
 
-func runPull() error {
-	// Load configuration
-	configStorage, err := storage.NewLocalConfigStorage()
-	if err != nil {
-		return fmt.Errorf("failed to read project configuration: %w", err)
-	}
+// lockTimeout is how long a run waits for another run on the same project to finish
+const lockTimeout = 10 * time.Second
 
-	configMap, err := configStorage.LoadConfig()
+// lockProject takes the lock of the current project and returns a function releasing it
+func lockProject() (func(), error) {
+	rootDir, err := storage.ProjectRoot()
 	if err != nil {
-		return fmt.Errorf("error loading project config: %w", err)
+		return nil, err
 	}
 
-	// Get repository
-	repo, err := getFirstRepository(configMap)
+	lock, err := storage.AcquireLock(rootDir, lockTimeout)
+	if errors.Is(err, storage.ErrLocked) {
+		return nil, &errs.ConflictError{Err: err}
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Read TODO.md
-	todoContent, err := os.ReadFile("TODO.md")
-	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("TODO.md file not found")
+	return func() {
+		if err := lock.Release(); err != nil {
+			reporter.Warn("lock_error", err.Error(), nil)
 		}
-		return fmt.Errorf("failed to read TODO.md: %w", err)
-	}
+	}, nil
+}
 
-	todoItems, err := markdown.ParseTodoMarkdown(string(todoContent))
+// loadConfig loads the configuration files and applies the ATAT_<KEY> environment
+// variables and the configuration flags on top of them.
+// This is where the effective configuration of a command is resolved.
+func loadConfig(overrides cli.ConfigOverrides) (map[config.ConfigKey]any, error) {
+	configStorage, err := storage.NewLayeredConfigStorage()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
 	}
 
-	// Fetch GitHub issues
-	githubIssues, err := fetchGitHubIssues(repo)
+	configMap, origins, err := configStorage.LoadConfigWithOrigins()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("error loading config: %w", err)
 	}
+	printConfigWarnings(configStorage)
 
-	// Synchronize titles with rename history
-	titleSync, err := github.SynchronizeTitlesWithHistory(todoItems, githubIssues, func(issueNumber uint64) ([]json.RawMessage, error) {
-		return fetchIssueEvents(repo, issueNumber)
-	})
+	configMap, _, err = config.ApplyOverrides(configMap, origins, os.LookupEnv, overrides)
 	if err != nil {
-		return err
-	}
-
-	for _, issueNumber := range titleSync.LocallyEditedIssues {
-		fmt.Printf("Warning: TODO.md text for issue #%d was changed locally; run `gh atat push` to update the issue title\n", issueNumber)
+		return nil, &errs.ParseError{Err: err}
 	}
 
-	// Synchronize with GitHub issues
-	updatedTodoItems := github.SynchronizeWithGitHubIssues(titleSync.Items, githubIssues)
-
-	// Write updated TODO.md
-	updatedContent := markdown.SerializeTodoMarkdown(updatedTodoItems)
-	if err := os.WriteFile("TODO.md", []byte(updatedContent), 0644); err != nil {
-		return fmt.Errorf("failed to write TODO.md: %w", err)
-	}
-
-	return nil
+	return configMap, nil
 }
 
-func runClean(dryRun bool) error {
-	// Load configuration
-	configStorage, err := storage.NewLocalConfigStorage()
-	if err != nil {
-		return fmt.Errorf("failed to read project configuration: %w", err)
+// printConfigWarnings prints the warnings found while loading the configuration
+func printConfigWarnings(configStorage *storage.LayeredConfigStorage) {
+	for _, warning := range configStorage.Warnings() {
+		reporter.Warn("config_warning", warning, nil)
 	}
+}
 
-	configMap, err := configStorage.LoadConfig()
+// readTodoFiles reads all tracked TODO files and returns them with the indices of the
+// files to operate on: the file given by --file, or all tracked files, and the state
+// each file was read in.
+// The file given by --file is tracked for this run even if it is not configured.
+// If requireRepo is false, files may have no repository.
+func readTodoFiles(configMap map[config.ConfigKey]any, fileFlag string, requireRepo bool) ([]github.TodoFile, []int, []storage.FileState, error) {
+	trackedFiles, err := config.ParseTrackedFiles(configMap[config.Files])
 	if err != nil {
-		return fmt.Errorf("error loading project config: %w", err)
+		return nil, nil, nil, err
 	}
 
-	// Get repository
-	repo, err := getFirstRepository(configMap)
+	rootDir, err := storage.ProjectRoot()
 	if err != nil {
-		return err
+		return nil, nil, nil, err
 	}
 
-	// Read TODO.md
-	todoContent, err := os.ReadFile("TODO.md")
-	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("TODO.md file not found")
+	var selected []int
+	if fileFlag != "" {
+		absPath, err := filepath.Abs(fileFlag)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid file path %s: %w", fileFlag, err)
+		}
+		relPath, err := filepath.Rel(rootDir, absPath)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid file path %s: %w", fileFlag, err)
+		}
+		path, err := config.NormalizeFilePath(filepath.ToSlash(relPath))
+		if err != nil {
+			return nil, nil, nil, err
 		}
-		return fmt.Errorf("failed to read TODO.md: %w", err)
-	}
 
-	todoItems, err := markdown.ParseTodoMarkdown(string(todoContent))
-	if err != nil {
-		return err
+		index := slices.IndexFunc(trackedFiles, func(file config.TrackedFile) bool { return file.Path == path })
+		if index < 0 {
+			trackedFiles = append(trackedFiles, config.TrackedFile{Path: path})
+			index = len(trackedFiles) - 1
+		}
+		selected = []int{index}
+	} else {
+		for i := range trackedFiles {
+			selected = append(selected, i)
+		}
 	}
 
-	// Build clean candidates from checked items with issue numbers
-	var candidates []clean.CleanCandidate
-	for _, item := range todoItems {
-		if candidate, ok := clean.NewCleanCandidate(item); ok {
-			candidates = append(candidates, candidate)
+	// Resolve repositories before reading any file
+	todoFiles := make([]github.TodoFile, len(trackedFiles))
+	for i, trackedFile := range trackedFiles {
+		repo := trackedFile.Repository
+		if repo == "" {
+			repo, err = getFirstRepository(configMap)
+			if err != nil && requireRepo {
+				return nil, nil, nil, err
+			}
 		}
+		todoFiles[i] = github.TodoFile{Path: trackedFile.Path, Repo: repo, Label: trackedFile.Label}
 	}
 
-	// Fetch GitHub issues
-	githubIssues, err := fetchGitHubIssues(repo)
+	options, err := parseOptions(configMap)
 	if err != nil {
-		return err
+		return nil, nil, nil, err
 	}
 
-	// Find removable items
-	removable := clean.FindRemovableItems(candidates, githubIssues)
+	readStates := make([]storage.FileState, len(todoFiles))
+	for i := range todoFiles {
+		content, state, err := storage.ReadFileState(filepath.Join(rootDir, filepath.FromSlash(todoFiles[i].Path)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				// Files that are not operated on may not have been created yet
+				if !slices.Contains(selected, i) {
+					continue
+				}
+				return nil, nil, nil, &errs.NotFoundError{Err: fmt.Errorf("%s file not found", todoFiles[i].Path)}
+			}
+			return nil, nil, nil, fmt.Errorf("failed to read %s: %w", todoFiles[i].Path, err)
+		}
 
-	if len(removable) == 0 {
-		return nil
+		items, err := markdown.ParseTodoMarkdownWithOptions(string(content), options)
+		if err != nil {
+			if len(todoFiles) > 1 {
+				err = fmt.Errorf("%s: %w", todoFiles[i].Path, err)
+			}
+			return nil, nil, nil, &errs.ParseError{Err: err}
+		}
+		sections, err := markdown.ParseSections(string(content), options)
+		if err != nil {
+			return nil, nil, nil, &errs.ParseError{Err: err}
+		}
+		todoFiles[i].Items = items
+		todoFiles[i].Sections = sections
+		readStates[i] = state
 	}
 
-	// Build a set of removable issue numbers for quick lookup
-	removableSet := make(map[uint64]bool)
-	for _, r := range removable {
-		removableSet[r.IssueNumber] = true
-		fmt.Printf("Removing: %s (#%d)\n", r.Text, r.IssueNumber)
+	if err := github.FindDuplicateIssueReferences(todoFiles); err != nil {
+		return nil, nil, nil, &errs.ParseError{Err: err}
 	}
 
-	if dryRun {
-		return nil
+	return todoFiles, selected, readStates, nil
+}
+
+// writeTodoFile writes todo items to a TODO file given relative to the project root.
+//
+// readState and readItems are the state and items of the file when it was read with
+// options. If the file was changed since, the changes from readItems to items are applied
+// to its new content instead of overwriting it. The content before writing is backed up
+// in journal.
+func writeTodoFile(path string, readState storage.FileState, readItems, items []todo.TodoItem, options markdown.ParseOptions, journal *history.Entry) error {
+	rootDir, err := storage.ProjectRoot()
+	if err != nil {
+		return err
 	}
+	fullPath := filepath.Join(rootDir, filepath.FromSlash(path))
 
-	// Filter out removable items from the todo list
-	var remaining []todo.TodoItem
-	for _, item := range todoItems {
-		if item.IssueNumber != nil && removableSet[*item.IssueNumber] {
-			continue
+	changed, content, _, err := storage.CheckFileState(fullPath, readState)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if changed {
+		currentItems, err := markdown.ParseTodoMarkdownWithOptions(string(content), options)
+		if err != nil {
+			return fmt.Errorf("%s was changed during the sync and can't be read: %w", path, err)
 		}
-		remaining = append(remaining, item)
+		reporter.Warn("file_changed_during_sync",
+			fmt.Sprintf("%s was changed during the sync; applying the updates to the new content", path),
+			output.Fields{"file": path})
+		items = todo.Rebase(readItems, items, currentItems)
 	}
 
-	// Write updated TODO.md
-	updatedContent := markdown.SerializeTodoMarkdown(remaining)
-	if err := os.WriteFile("TODO.md", []byte(updatedContent), 0644); err != nil {
-		return fmt.Errorf("failed to write TODO.md: %w", err)
+	previous, err := os.ReadFile(fullPath)
+	existed := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
 	}
 
-	return nil
-}
-
-func runRemoteList() error {
-	configStorage, err := storage.NewLocalConfigStorage()
+	// Keep the content that is not synced where it was
+	newContent, err := markdown.SerializeTodoMarkdownWithOptions(items, string(previous), options)
 	if err != nil {
-		return fmt.Errorf("failed to read project configuration: %w", err)
+		return &errs.ParseError{Err: fmt.Errorf("%s can't be read: %w", path, err)}
 	}
-
-	configMap, err := configStorage.LoadConfig()
-	if err != nil {
-		return fmt.Errorf("error loading project config: %w", err)
+	if err := storage.WriteFileAtomic(fullPath, []byte(newContent), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
 	}
 
-	if reposValue, ok := configMap[config.Repositories]; ok {
-		if reposArray, ok := reposValue.([]interface{}); ok {
-			for _, repoVal := range reposArray {
-				if repoStr, ok := repoVal.(string); ok {
-					fmt.Println(repoStr)
-				}
-			}
-		}
+	if !existed || string(previous) != newContent {
+		journal.AddFile(history.FileBackup{
+			Path:        path,
+			Existed:     existed,
+			Content:     string(previous),
+			WrittenHash: contentHash([]byte(newContent)),
+		})
 	}
-
 	return nil
 }
 
-func runRemoteAdd(repo string) error {
-	configStorage, err := storage.NewLocalConfigStorage()
-	if err != nil {
-		return fmt.Errorf("error initializing config storage: %w", err)
-	}
+// contentHash returns the hex SHA-256 of content, as recorded in history entries
+func contentHash(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
 
-	configMap, _ := configStorage.LoadConfig()
-	if configMap == nil {
-		configMap = make(map[config.ConfigKey]any)
+// currentUser returns the GitHub login gh is logged in with, as read from its local
+// configuration, or the name of the local user if there is none. It makes no network calls.
+func currentUser() string {
+	host := os.Getenv("GH_HOST")
+	if host == "" {
+		host = "github.com"
 	}
-
-	// Get or create repositories array
-	var reposArray []interface{}
-	if reposValue, ok := configMap[config.Repositories]; ok {
-		if arr, ok := reposValue.([]interface{}); ok {
-			reposArray = arr
+	if output, err := exec.Command("gh", "config", "get", "-h", host, "user").Output(); err == nil {
+		if login := strings.TrimSpace(string(output)); login != "" {
+			return login
 		}
 	}
-
-	// Check if repository already exists
-	for _, repoVal := range reposArray {
-		if repoStr, ok := repoVal.(string); ok && repoStr == repo {
-			// Already exists, nothing to do
-			return nil
-		}
+	if u, err := user.Current(); err == nil {
+		return u.Username
 	}
+	return ""
+}
 
-	// Check if repository exists on GitHub
-	exists, err := checkRepoExists(repo)
-	if err != nil {
-		return fmt.Errorf("failed to check repository %s: %w", repo, err)
-	}
-	if !exists {
-		return fmt.Errorf("repository %s not found or not accessible", repo)
+// issueTitle returns the title of the issue with the given number, or "" if it is not found
+func issueTitle(githubIssues []github.GitHubIssue, number uint64) string {
+	for _, issue := range githubIssues {
+		if issue.Number == number {
+			return issue.Title
+		}
 	}
+	return ""
+}
 
-	// Add repository
-	reposArray = append(reposArray, repo)
-	configMap[config.Repositories] = reposArray
-
-	// Save configuration
-	if err := configStorage.SaveConfig(configMap); err != nil {
-		return fmt.Errorf("error saving project config: %w", err)
+// partialFailure returns err as a partial failure if the run recorded in journal
+// already changed issues or files
+func partialFailure(journal *history.Entry, err error) error {
+	if journal.IsEmpty() {
+		return err
 	}
-
-	return nil
+	return &errs.PartialFailureError{Changes: len(journal.Operations) + len(journal.Files), Err: err}
 }
 
-func runRemoteRemove(repo string) error {
-	configStorage, err := storage.NewLocalConfigStorage()
-	if err != nil {
-		return fmt.Errorf("error initializing config storage: %w", err)
+// saveJournal saves the history entry of a run if the run changed anything.
+// Failing to save it doesn't fail the run, as the changes were already made.
+func saveJournal(journal *history.Entry) {
+	if journal.IsEmpty() {
+		return
 	}
-
-	configMap, _ := configStorage.LoadConfig()
-	if configMap == nil {
-		configMap = make(map[config.ConfigKey]any)
+	historyStorage, err := storage.NewHistoryStorage()
+	if err == nil {
+		err = historyStorage.Save(*journal)
 	}
-
-	// Get repositories array
-	var reposArray []interface{}
-	if reposValue, ok := configMap[config.Repositories]; ok {
-		if arr, ok := reposValue.([]interface{}); ok {
-			reposArray = arr
-		}
+	if err != nil {
+		reporter.Warn("history_error", fmt.Sprintf("failed to record the run in the history: %v", err), nil)
 	}
+}
 
-	// Filter out the repository
-	var filteredRepos []interface{}
-	for _, repoVal := range reposArray {
-		if repoStr, ok := repoVal.(string); ok && repoStr != repo {
-			filteredRepos = append(filteredRepos, repoStr)
-		}
+// taskFields returns the event fields of a task listed by `gh atat status`
+func taskFields(path string, item todo.TodoItem, overdue bool) output.Fields {
+	fields := output.Fields{
+		"file":      path,
+		"text":      item.Text,
+		"checked":   item.IsChecked,
+		"cancelled": item.IsCancelled,
+		"overdue":   overdue,
 	}
-
-	// Update configuration
-	if len(filteredRepos) == 0 {
-		delete(configMap, config.Repositories)
-	} else {
-		configMap[config.Repositories] = filteredRepos
+	if item.IssueNumber != nil {
+		fields["issue"] = *item.IssueNumber
+	} else if item.ExternalIssue != nil {
+		fields["issue"] = item.ExternalIssue.Number
+		fields["repo"] = item.ExternalIssue.Repo
 	}
-
-	// Save configuration
-	if err := configStorage.SaveConfig(configMap); err != nil {
-		return fmt.Errorf("error saving project config: %w", err)
+	if item.DueDate != nil {
+		fields["due"] = item.DueDate.Format(todo.DueDateLayout)
 	}
-
-	return nil
+	if item.Section.Title != "" {
+		fields["section"] = item.Section.Title
+	}
+	return fields
 }
 
-func getFirstRepository(configMap map[config.ConfigKey]any) (string, error) {
-	reposValue, ok := configMap[config.Repositories]
-	if !ok {
-		return "", fmt.Errorf("no repository configured")
+// operationFields returns the event fields of a recorded GitHub operation
+func operationFields(op history.Operation) output.Fields {
+	fields := output.Fields{"kind": string(op.Kind), "repo": op.Repo, "issue": op.Number}
+	if op.Title != "" {
+		fields["title"] = op.Title
 	}
-
-	reposArray, ok := reposValue.([]interface{})
-	if !ok || len(reposArray) == 0 {
-		return "", fmt.Errorf("no repository configured")
+	if op.PreviousTitle != "" {
+		fields["previous_title"] = op.PreviousTitle
 	}
+	return fields
+}
 
-	repo, ok := reposArray[0].(string)
-	if !ok {
-		return "", fmt.Errorf("invalid repository configuration")
+// printFileHeader prints the path of the TODO file being processed when there are several
+func printFileHeader(path string, fileCount int) {
+	if fileCount > 1 {
+		reporter.Heading(path + ":")
 	}
-
-	return repo, nil
 }
 
-func fetchGitHubIssues(repo string) ([]github.GitHubIssue, error) {
-	fetchFunc := func(repo string, token string, page int, perPage int) ([]json.RawMessage, error) {
-		endpoint := fmt.Sprintf("repos/%s/issues?state=all&per_page=%d&page=%d", repo, perPage, page)
-		data, err := ghAPI(endpoint)
-		if err != nil {
-			return nil, err
-		}
-
-		var issues []json.RawMessage
-		if err := json.Unmarshal(data, &issues); err != nil {
-			return nil, err
-		}
+// fetchGitHubIssuesCached fetches the issues of a repository once per run
+func fetchGitHubIssuesCached(cache map[string][]github.GitHubIssue, repo string) ([]github.GitHubIssue, error) {
+	if issues, ok := cache[repo]; ok {
 		return issues, nil
 	}
 
-	return github.FetchGitHubIssues(repo, "", fetchFunc)
-}
-
-func createGitHubIssue(repo, title string) (int, error) {
-	body := map[string]string{"title": title}
-	bodyJSON, err := json.Marshal(body)
+	issues, err := fetchGitHubIssues(repo)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
+	cache[repo] = issues
+	return issues, nil
+}
 
-	output, err := ghAPIPost(fmt.Sprintf("repos/%s/issues", repo), string(bodyJSON))
+// resolveOrphanedReferences relinks transferred issues and applies the orphan action
+// to references whose issues are gone. Returns the items and the items that were unlinked.
+func resolveOrphanedReferences(repo string, todoItems []todo.TodoItem, githubIssues []github.GitHubIssue, orphanAction cli.OrphanAction) ([]todo.TodoItem, []todo.TodoItem, error) {
+	orphans, err := github.CollectOrphanedReferences(todoItems, githubIssues, repo, func(issueNumber uint64) (json.RawMessage, error) {
+		return lookupIssue(repo, issueNumber)
+	})
 	if err != nil {
-		return 0, err
+		return nil, nil, err
+	}
+	if len(orphans) == 0 {
+		return todoItems, nil, nil
 	}
 
-	var issue github.GitHubIssue
-	if err := json.Unmarshal(output, &issue); err != nil {
-		return 0, err
+	for _, orphan := range orphans {
+		if orphan.Kind == github.OrphanTransferred {
+			reporter.Info("issue_transferred",
+				fmt.Sprintf("Issue #%d was transferred to %s#%d", orphan.Number, orphan.MovedTo.Repo, orphan.MovedTo.Number),
+				orphanFields(repo, orphan))
+		}
 	}
+	todoItems = github.RelinkTransferredIssues(todoItems, orphans)
 
-	return int(issue.Number), nil
+	switch orphanAction {
+	case cli.OrphansUnlink:
+		for _, orphan := range orphans {
+			if orphan.Kind != github.OrphanTransferred {
+				reporter.Info("reference_unlinked",
+					fmt.Sprintf("Unlinked #%d (%s)", orphan.Number, orphanDescription(orphan)),
+					orphanFields(repo, orphan))
+			}
+		}
+		return github.UnlinkOrphanedReferences(todoItems, orphans), github.UnlinkedItems(todoItems, orphans), nil
+	case cli.OrphansRemove:
+		for _, orphan := range orphans {
+			if orphan.Kind != github.OrphanTransferred {
+				reporter.Info("reference_removed",
+					fmt.Sprintf("Removing #%d (%s)", orphan.Number, orphanDescription(orphan)),
+					orphanFields(repo, orphan))
+			}
+		}
+		return github.RemoveOrphanedReferences(todoItems, orphans), nil, nil
+	default:
+		printOrphanedReferences(repo, orphans)
+		return todoItems, nil, nil
+	}
 }
 
-func closeGitHubIssue(repo string, number int) error {
-	body := map[string]string{"state": "closed"}
-	bodyJSON, err := json.Marshal(body)
-	if err != nil {
-		return err
+// printOrphanedReferences warns about references whose issues are gone
+func printOrphanedReferences(repo string, orphans []github.OrphanedReference) {
+	for _, orphan := range orphans {
+		if orphan.Kind == github.OrphanTransferred {
+			continue
+		}
+		reporter.Warn("reference_orphaned",
+			fmt.Sprintf("#%d %s; use --orphans=unlink or --orphans=remove to update TODO.md", orphan.Number, orphanDescription(orphan)),
+			orphanFields(repo, orphan))
 	}
+}
 
-	_, err = ghAPIPatch(fmt.Sprintf("repos/%s/issues/%d", repo, number), string(bodyJSON))
-	return err
+// orphanFields returns the event fields of an orphaned reference
+func orphanFields(repo string, orphan github.OrphanedReference) output.Fields {
+	fields := output.Fields{"repo": repo, "issue": orphan.Number, "kind": orphanKindName(orphan.Kind)}
+	if orphan.MovedTo != nil {
+		fields["moved_to"] = fmt.Sprintf("%s#%d", orphan.MovedTo.Repo, orphan.MovedTo.Number)
+	}
+	return fields
 }
 
-func fetchIssueEvents(repo string, issueNumber uint64) ([]json.RawMessage, error) {
-	endpoint := fmt.Sprintf("repos/%s/issues/%d/events", repo, issueNumber)
-	data, err := ghAPI(endpoint)
-	if err != nil {
-		return nil, err
+// orphanKindName returns the stable name of an orphan kind used in events
+func orphanKindName(kind github.OrphanKind) string {
+	switch kind {
+	case github.OrphanPullRequest:
+		return "pull_request"
+	case github.OrphanTransferred:
+		return "transferred"
+	default:
+		return "deleted"
 	}
+}
 
-	var events []json.RawMessage
-	if err := json.Unmarshal(data, &events); err != nil {
-		return nil, err
+func orphanDescription(orphan github.OrphanedReference) string {
+	switch orphan.Kind {
+	case github.OrphanPullRequest:
+		return "is a pull request"
+	case github.OrphanTransferred:
+		return fmt.Sprintf("was transferred to %s#%d", orphan.MovedTo.Repo, orphan.MovedTo.Number)
+	default:
+		return "was deleted"
 	}
-	return events, nil
 }
 
-func renameGitHubIssue(repo string, number int, title string) error {
-	body := map[string]string{"title": title}
-	bodyJSON, err := json.Marshal(body)
+// lookupIssue fetches a single issue, following redirects for transferred issues.
+// Returns nil if the issue was deleted or does not exist.
+func lookupIssue(repo string, issueNumber uint64) (json.RawMessage, error) {
+	data, err := ghAPI(fmt.Sprintf("repos/%s/issues/%d", repo, issueNumber))
 	if err != nil {
-		return err
+		// 404 and 410 mean the issue doesn't exist anymore
+		var notFoundErr *errs.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, err
 	}
-
-	_, err = ghAPIPatch(fmt.Sprintf("repos/%s/issues/%d", repo, number), string(bodyJSON))
-	return err
+	return data, nil
 }
 
 func checkRepoExists(repo string) (bool, error) {
 	_, err := ghAPI(fmt.Sprintf("repos/%s", repo))
 	if err != nil {
-		// If error contains "404", repo doesn't exist
-		if strings.Contains(err.Error(), "404") {
+		var notFoundErr *errs.NotFoundError
+		if errors.As(err, &notFoundErr) {
 			return false, nil
 		}
 		return false, err
@@ -516,33 +2612,58 @@ func checkRepoExists(repo string) (bool, error) {
 	return true, nil
 }
 
-func ghAPI(endpoint string) ([]byte, error) {
-	cmd := exec.Command("gh", "api", endpoint)
-	output, err := cmd.CombinedOutput()
+// ghAPIRequest calls `gh api` on endpoint with method and, unless empty, body as the request body.
+// Returns the response body.
+func ghAPIRequest(method, endpoint, body string) ([]byte, error) {
+	args := []string{"api", "--include", endpoint}
+	if method != "" {
+		args = append(args, "-X", method)
+	}
+	if body != "" {
+		args = append(args, "--input", "-")
+	}
+	cmd := exec.Command("gh", args...)
+	if body != "" {
+		cmd.Stdin = strings.NewReader(body)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	response := github.ParseAPIResponse(output)
 	if err != nil {
-		return nil, fmt.Errorf("gh api failed: %w: %s", err, string(output))
+		name := "gh api"
+		if method != "" {
+			name += " " + method
+		}
+		exitCode := -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		return nil, github.ClassifyAPIError(fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(stderr.String())), exitCode, response)
 	}
-	return output, nil
+	return response.Body, nil
+}
+
+func ghAPI(endpoint string) ([]byte, error) {
+	return ghAPIRequest("", endpoint, "")
 }
 
 func ghAPIPost(endpoint, body string) ([]byte, error) {
-	cmd := exec.Command("gh", "api", endpoint, "-X", "POST", "--input", "-")
-	cmd.Stdin = strings.NewReader(body)
-	output, err := cmd.CombinedOutput()
+	return ghAPIRequest("POST", endpoint, body)
+}
+
+func ghGraphQL(query string, variables map[string]any) ([]byte, error) {
+	body := map[string]any{"query": query, "variables": variables}
+	bodyJSON, err := json.Marshal(body)
 	if err != nil {
-		return nil, fmt.Errorf("gh api POST failed: %w: %s", err, string(output))
+		return nil, err
 	}
-	return output, nil
+	return ghAPIPost("graphql", string(bodyJSON))
 }
 
 func ghAPIPatch(endpoint, body string) ([]byte, error) {
-	cmd := exec.Command("gh", "api", endpoint, "-X", "PATCH", "--input", "-")
-	cmd.Stdin = strings.NewReader(body)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("gh api PATCH failed: %w: %s", err, string(output))
-	}
-	return output, nil
+	return ghAPIRequest("PATCH", endpoint, body)
 }
 
 func printVersion(version string) {
@@ -561,20 +2682,67 @@ Usage:
 Commands:
   push          Push TODO items to GitHub Issues
   pull          Pull GitHub Issues to TODO items
+  sync          Pull and push in one step, reporting changes made on both sides
   clean         Remove completed TODO items with closed issues
+  status        Show TODO items and their due dates
   remote        List configured repositories
   remote add    Add a repository
   remote remove Remove a repository
+  config        List configuration values and where they come from
+  config get    Show a configuration value
+  config set    Set a configuration value
+  config unset  Remove a configuration value
+  undo          Undo the last push, pull, sync or clean
+  log           Show the history of push, pull, sync and clean runs
   help          Show this help message
 
+Options:
+  --file <path>                 Only process the given TODO file (push, pull, sync, clean, status)
+  --orphans=keep|unlink|remove  Handle references to deleted issues (push, pull, sync)
+  --strategy=ours|theirs|fail   Settle title conflicts without asking: keep the task text,
+                                keep the issue title, or stop (push, pull, sync)
+  --global, --local             Use the user or project configuration (config)
+  --force                       Restore files even if they were changed after the run (undo)
+  --json                        Print one JSON event per line (push, pull, sync, clean, status,
+                                undo, log, remote list, config list, config get)
+  --since <date|duration>       Only show runs since a date or for a duration such as 7d (log)
+  --<key> <value>               Override a configuration value, such as --repositories owner/repo
+                                (push, pull, sync, clean, status)
+
+Environment:
+  ATAT_<KEY>                    Override a configuration value, such as ATAT_REPOSITORIES=owner/repo
+
+Exit codes:
+  0  Success
+  1  Error
+  2  Invalid TODO file or configuration
+  3  Repository, issue or file not found
+  4  GitHub authentication failed or access denied
+  5  GitHub API rate limit exceeded
+  6  GitHub could not be reached
+  7  Another run is in progress, a file was changed (undo), or titles conflict (--strategy=fail)
+  8  The command failed after changing issues or files
+
 Examples:
   gh atat push
   gh atat pull
+  gh atat pull --orphans=unlink
+  gh atat pull --strategy=theirs
+  gh atat sync
+  gh atat pull --file docs/ROADMAP.md
+  gh atat push --repositories owner/repo
   gh atat clean
   gh atat clean --dry-run
+  gh atat status --overdue
+  gh atat undo
+  gh atat log --since 7d
   gh atat remote
   gh atat remote add owner/repo
   gh atat remote remove owner/repo
+  gh atat config set due milestone
+  gh atat config set match fuzzy
+  gh atat config set --global repositories owner/repo
+  gh atat config get files
 `
 	fmt.Print(help)
 }
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/toms74209200/gh-atat/internal/config"
+	"github.com/toms74209200/gh-atat/internal/history"
+)
+
+// HistoryDirname is the name of the directory holding history entries in the project config directory
+const HistoryDirname = "history"
+
+// maxHistoryEntries is the number of entries kept; older entries are removed
+const maxHistoryEntries = 100
+
+// HistoryStorage stores the history of runs as one JSON file per entry
+type HistoryStorage struct {
+	dir string
+}
+
+// NewHistoryStorage creates a new HistoryStorage for the project containing the current directory
+func NewHistoryStorage() (*HistoryStorage, error) {
+	rootDir, err := ProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	return &HistoryStorage{dir: filepath.Join(rootDir, config.ProjectConfigDir, HistoryDirname)}, nil
+}
+
+// Save writes an entry, replacing an existing entry with the same ID, and removes
+// the oldest entries beyond the number kept
+func (s *HistoryStorage) Save(entry history.Entry) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create history directory at %s: %w", s.dir, err)
+	}
+
+	content, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize history entry %s: %w", entry.ID, err)
+	}
+	if err := WriteFileAtomic(s.entryPath(entry.ID), content, 0644); err != nil {
+		return err
+	}
+
+	return s.prune()
+}
+
+// List returns all entries in the order the runs started.
+// Returns no entries if there is no history.
+func (s *HistoryStorage) List() ([]history.Entry, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]history.Entry, 0, len(ids))
+	for _, id := range ids {
+		content, err := os.ReadFile(s.entryPath(id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history entry %s: %w", id, err)
+		}
+		var entry history.Entry
+		if err := json.Unmarshal(content, &entry); err != nil {
+			return nil, fmt.Errorf("invalid history entry %s: %w", s.entryPath(id), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ids returns the IDs of all entries in the order the runs started
+func (s *HistoryStorage) ids() ([]string, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read history directory at %s: %w", s.dir, err)
+	}
+
+	var ids []string
+	for _, dirEntry := range dirEntries {
+		if id, ok := strings.CutSuffix(dirEntry.Name(), ".json"); ok && !dirEntry.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// prune removes the oldest entries beyond maxHistoryEntries
+func (s *HistoryStorage) prune() error {
+	ids, err := s.ids()
+	if err != nil {
+		return err
+	}
+	for len(ids) > maxHistoryEntries {
+		if err := os.Remove(s.entryPath(ids[0])); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove old history entry %s: %w", ids[0], err)
+		}
+		ids = ids[1:]
+	}
+	return nil
+}
+
+// entryPath returns the path of the file holding the entry with the given ID
+func (s *HistoryStorage) entryPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/toms74209200/gh-atat/internal/history"
+)
+
+func TestHistoryStorageSaveAndList(t *testing.T) {
+	s := &HistoryStorage{dir: filepath.Join(t.TempDir(), ".atat", HistoryDirname)}
+
+	entries, err := s.List()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected empty history, got %v, %v", entries, err)
+	}
+
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	second := history.NewEntry(history.CommandClean, start.Add(time.Minute))
+	second.AddFile(history.FileBackup{Path: "TODO.md", Existed: true, Content: "- [x] Done (#1)\n"})
+	first := history.NewEntry(history.CommandPush, start)
+	first.Record(history.Operation{Kind: history.OperationCreate, Repo: "owner/repo", Number: 1, Title: "Done"})
+
+	for _, entry := range []*history.Entry{second, first} {
+		if err := s.Save(*entry); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	entries, err = s.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != first.ID || entries[1].ID != second.ID {
+		t.Fatalf("expected entries in the order the runs started, got %+v", entries)
+	}
+	if entries[1].Files[0].Content != "- [x] Done (#1)\n" || entries[0].Operations[0].Title != "Done" {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+
+	// Saving an entry again replaces it
+	undoneAt := start.Add(time.Hour)
+	entries[1].UndoneAt = &undoneAt
+	if err := s.Save(entries[1]); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	entries, err = s.List()
+	if err != nil || len(entries) != 2 || entries[1].UndoneAt == nil {
+		t.Errorf("expected entry to be replaced, got %+v, %v", entries, err)
+	}
+}
+
+func TestHistoryStorageRemovesOldEntries(t *testing.T) {
+	s := &HistoryStorage{dir: t.TempDir()}
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	for i := range maxHistoryEntries + 2 {
+		entry := history.NewEntry(history.CommandPull, start.Add(time.Duration(i)*time.Second))
+		if err := s.Save(*entry); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+	if len(dirEntries) != maxHistoryEntries {
+		t.Errorf("expected %d entries, got %d", maxHistoryEntries, len(dirEntries))
+	}
+	oldest := history.NewEntry(history.CommandPull, start.Add(2*time.Second))
+	if dirEntries[0].Name() != fmt.Sprintf("%s.json", oldest.ID) {
+		t.Errorf("expected oldest entries to be removed, first is %s", dirEntries[0].Name())
+	}
+}
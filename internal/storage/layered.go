@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/toms74209200/gh-atat/internal/config"
+	"github.com/toms74209200/gh-atat/internal/errs"
+)
+
+// systemConfigDir is the directory containing the system-wide configuration
+const systemConfigDir = "/etc"
+
+// configFile is a configuration file of a single scope
+type configFile struct {
+	scope config.Scope
+	path  string
+}
+
+// LayeredConfigStorage is a configuration persistence implementation that merges
+// the system, user and project configuration files.
+//
+// Values are looked up with the following precedence, from highest to lowest:
+//   - project: .atat/config.json at the project root
+//   - user: $XDG_CONFIG_HOME/gh-atat/config.json, or ~/.config/gh-atat/config.json
+//   - system: /etc/gh-atat/config.json
+//
+// Missing files are treated as empty configuration.
+type LayeredConfigStorage struct {
+	files []configFile
+	// warnings are the warnings of the last load
+	warnings []string
+}
+
+// NewLayeredConfigStorage creates a new LayeredConfigStorage instance
+func NewLayeredConfigStorage() (*LayeredConfigStorage, error) {
+	project, err := NewLocalConfigStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	files := []configFile{
+		{scope: config.ScopeSystem, path: filepath.Join(systemConfigDir, config.UserConfigDir, config.ProjectConfigFilename)},
+	}
+	if userPath, ok := userConfigPath(); ok {
+		files = append(files, configFile{scope: config.ScopeUser, path: userPath})
+	}
+	files = append(files, configFile{scope: config.ScopeProject, path: project.configPath})
+
+	return &LayeredConfigStorage{files: files}, nil
+}
+
+// LoadConfigWithOrigins loads the merged configuration of all scopes and
+// the scope each value came from.
+func (s *LayeredConfigStorage) LoadConfigWithOrigins() (map[config.ConfigKey]any, map[config.ConfigKey]config.Scope, error) {
+	layers, err := s.loadLayers()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged, origins := config.MergeLayers(layers)
+	return merged, origins, nil
+}
+
+// LoadScope loads the configuration of a single scope
+func (s *LayeredConfigStorage) LoadScope(scope config.Scope) (map[config.ConfigKey]any, error) {
+	file, err := s.scopeStorage(scope)
+	if err != nil {
+		return nil, err
+	}
+	return file.LoadConfig()
+}
+
+// SaveScope saves the configuration of a single scope.
+// The system configuration can't be written.
+func (s *LayeredConfigStorage) SaveScope(scope config.Scope, configData map[config.ConfigKey]any) error {
+	if scope == config.ScopeSystem {
+		return fmt.Errorf("the system config file can't be changed")
+	}
+	file, err := s.scopeStorage(scope)
+	if err != nil {
+		return err
+	}
+	return file.SaveConfig(configData)
+}
+
+// scopeStorage returns the storage of the configuration file of a scope
+func (s *LayeredConfigStorage) scopeStorage(scope config.Scope) (*LocalConfigStorage, error) {
+	for _, file := range s.files {
+		if file.scope == scope {
+			return &LocalConfigStorage{
+				scope:      file.scope,
+				configPath: file.path,
+				configDir:  filepath.Dir(file.path),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s config file available", scope)
+}
+
+// Warnings returns the warnings about the configuration files found by the last load,
+// such as unknown keys
+func (s *LayeredConfigStorage) Warnings() []string {
+	return s.warnings
+}
+
+// loadLayers reads the configuration file of each scope, from lowest to highest precedence
+func (s *LayeredConfigStorage) loadLayers() ([]config.Layer, error) {
+	layers := make([]config.Layer, 0, len(s.files))
+	s.warnings = nil
+	for _, file := range s.files {
+		content, err := readFileBytes(file.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s config file at %s: %w", file.scope, file.path, err)
+		}
+
+		configMap, warnings, err := config.ParseConfigWithWarnings(content)
+		if err != nil {
+			return nil, &errs.ParseError{Err: fmt.Errorf("invalid %s config file at %s: %w", file.scope, file.path, err)}
+		}
+		for _, warning := range warnings {
+			s.warnings = append(s.warnings, fmt.Sprintf("%s: %s", file.path, warning))
+		}
+
+		layers = append(layers, config.Layer{Scope: file.scope, Config: configMap})
+	}
+	return layers, nil
+}
+
+// userConfigPath returns the path of the user configuration file following the
+// XDG Base Directory specification. Returns false if no home directory is known.
+func userConfigPath() (string, bool) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" || !filepath.IsAbs(configHome) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, config.UserConfigDir, config.ProjectConfigFilename), true
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/toms74209200/gh-atat/internal/config"
+)
+
+// newTestLayeredStorage creates a LayeredConfigStorage for a project in a temporary
+// directory, with the user configuration in XDG_CONFIG_HOME and the system
+// configuration in a temporary directory
+func newTestLayeredStorage(t *testing.T) (*LayeredConfigStorage, map[config.Scope]string) {
+	t.Helper()
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	if err := os.MkdirAll(filepath.Join(project, config.ProjectConfigDir), 0755); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "xdg"))
+	t.Chdir(project)
+
+	s, err := NewLayeredConfigStorage()
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	s.files[0].path = filepath.Join(root, "etc", config.UserConfigDir, config.ProjectConfigFilename)
+
+	paths := make(map[config.Scope]string)
+	for _, file := range s.files {
+		paths[file.scope] = file.path
+	}
+	return s, paths
+}
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestLayeredConfigStoragePrecedence(t *testing.T) {
+	s, paths := newTestLayeredStorage(t)
+	writeConfigFile(t, paths[config.ScopeSystem], `{"match": "fuzzy", "due": "milestone", "repositories": ["system/repo"]}`)
+	writeConfigFile(t, paths[config.ScopeUser], `{"match": "exact", "repositories": ["user/repo"]}`)
+	writeConfigFile(t, paths[config.ScopeProject], `{"repositories": ["project/repo"]}`)
+
+	merged, origins, err := s.LoadConfigWithOrigins()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[config.ConfigKey]any{
+		config.Match:        "exact",
+		config.Due:          "milestone",
+		config.Repositories: []any{"project/repo"},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("expected %+v, got %+v", expected, merged)
+	}
+	expectedOrigins := map[config.ConfigKey]config.Scope{
+		config.Match:        config.ScopeUser,
+		config.Due:          config.ScopeSystem,
+		config.Repositories: config.ScopeProject,
+	}
+	if !reflect.DeepEqual(origins, expectedOrigins) {
+		t.Errorf("expected %+v, got %+v", expectedOrigins, origins)
+	}
+}
+
+func TestLayeredConfigStorageUserPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		name       string
+		configHome string
+		expected   string
+	}{
+		{"XDG_CONFIG_HOME", "/xdg", filepath.Join("/xdg", config.UserConfigDir, config.ProjectConfigFilename)},
+		{"unset XDG_CONFIG_HOME", "", filepath.Join(home, ".config", config.UserConfigDir, config.ProjectConfigFilename)},
+		{"relative XDG_CONFIG_HOME", "xdg", filepath.Join(home, ".config", config.UserConfigDir, config.ProjectConfigFilename)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", tt.configHome)
+			actual, ok := userConfigPath()
+			if !ok {
+				t.Fatalf("expected a user config path")
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestLayeredConfigStorageMissingFiles(t *testing.T) {
+	s, paths := newTestLayeredStorage(t)
+	writeConfigFile(t, paths[config.ScopeUser], `{"match": "fuzzy"}`)
+
+	merged, origins, err := s.LoadConfigWithOrigins()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[config.ConfigKey]any{config.Match: "fuzzy"}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("expected %+v, got %+v", expected, merged)
+	}
+	expectedOrigins := map[config.ConfigKey]config.Scope{config.Match: config.ScopeUser}
+	if !reflect.DeepEqual(origins, expectedOrigins) {
+		t.Errorf("expected %+v, got %+v", expectedOrigins, origins)
+	}
+}
+
+func TestLayeredConfigStorageUnreadableFile(t *testing.T) {
+	s, paths := newTestLayeredStorage(t)
+	writeConfigFile(t, paths[config.ScopeProject], `{"match": "fuzzy"}`)
+	// A directory in place of the user config file can't be read
+	if err := os.MkdirAll(paths[config.ScopeUser], 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", paths[config.ScopeUser], err)
+	}
+
+	if _, _, err := s.LoadConfigWithOrigins(); err == nil {
+		t.Errorf("expected an error for an unreadable user config file")
+	}
+}
+
+func TestLayeredConfigStorageInvalidFile(t *testing.T) {
+	s, paths := newTestLayeredStorage(t)
+	writeConfigFile(t, paths[config.ScopeSystem], `{"match": `)
+
+	if _, _, err := s.LoadConfigWithOrigins(); err == nil {
+		t.Errorf("expected an error for an invalid system config file")
+	}
+}
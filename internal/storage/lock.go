@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toms74209200/gh-atat/internal/config"
+)
+
+// LockFilename is the name of the lock file in the project config directory
+const LockFilename = "lock"
+
+// lockRetryInterval is how often a held lock is checked while waiting for it
+const lockRetryInterval = 100 * time.Millisecond
+
+// lockStaleAge is how old a lock may be before it is taken over, whether its owner is
+// still running or not. It covers owners that can't be checked, such as processes of
+// other hosts sharing the project, and process IDs reused by other programs.
+const lockStaleAge = time.Hour
+
+// ErrLocked is returned when the project lock is held by another run
+var ErrLocked = errors.New("another gh atat run is in progress")
+
+// Lock is an advisory lock on a project, taken by runs that change its files so
+// that overlapping runs don't interleave their writes
+type Lock struct {
+	path string
+}
+
+// AcquireLock takes the lock of the project at rootDir by creating .atat/lock, holding
+// the process ID, host name and start time of the run.
+//
+// If another process holds the lock, AcquireLock waits up to timeout for it to be released.
+// Locks left behind by processes of this host that no longer run, and locks older than
+// lockStaleAge, are taken over.
+// Returns an error wrapping ErrLocked if the lock is still held after timeout.
+func AcquireLock(rootDir string, timeout time.Duration) (*Lock, error) {
+	lockDir := filepath.Join(rootDir, config.ProjectConfigDir)
+	if err := os.MkdirAll(lockDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory at %s: %w", lockDir, err)
+	}
+	path := filepath.Join(lockDir, LockFilename)
+
+	deadline := time.Now().Add(timeout)
+	for {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, writeErr := fmt.Fprintf(file, "%d\n%s\n%s\n", os.Getpid(), hostname(), time.Now().UTC().Format(time.RFC3339))
+			closeErr := file.Close()
+			if writeErr != nil || closeErr != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock file at %s: %w", path, errors.Join(writeErr, closeErr))
+			}
+			return &Lock{path: path}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file at %s: %w", path, err)
+		}
+
+		owner, ok := readLockOwner(path)
+		if ok && owner.isStale(time.Now()) {
+			// The owner exited without releasing the lock, or is stuck
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to remove stale lock file at %s: %w", path, err)
+			}
+			continue
+		}
+
+		if !time.Now().Before(deadline) {
+			if ok {
+				return nil, fmt.Errorf("%w (process %d holds %s)", ErrLocked, owner.pid, path)
+			}
+			return nil, fmt.Errorf("%w (%s exists; remove it if no other run is in progress)", ErrLocked, path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Release releases the lock
+func (l *Lock) Release() error {
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove lock file at %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// lockOwner is the run holding a lock, as written to the lock file
+type lockOwner struct {
+	pid int
+	// host is the host name of the owner, or empty if unknown
+	host string
+	// started is when the owner took the lock
+	started time.Time
+}
+
+// isStale reports whether the owner of a lock is gone: it is older than lockStaleAge, or
+// it ran on this host and its process no longer runs.
+func (o lockOwner) isStale(now time.Time) bool {
+	if now.Sub(o.started) > lockStaleAge {
+		return true
+	}
+	if o.host != "" && o.host != hostname() {
+		return false
+	}
+	return !processExists(o.pid)
+}
+
+// readLockOwner returns the owner written to a lock file: its process ID, followed by
+// its host name and start time. Lock files without a start time take their modification
+// time, and lock files without a host name are assumed to be of this host.
+// Returns false if the file can't be read or is still being written.
+func readLockOwner(path string) (lockOwner, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return lockOwner{}, false
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil || pid <= 0 {
+		return lockOwner{}, false
+	}
+
+	owner := lockOwner{pid: pid}
+	if len(lines) > 1 {
+		owner.host = strings.TrimSpace(lines[1])
+	}
+	if len(lines) > 2 {
+		owner.started, _ = time.Parse(time.RFC3339, strings.TrimSpace(lines[2]))
+	}
+	if owner.started.IsZero() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return lockOwner{}, false
+		}
+		owner.started = info.ModTime()
+	}
+	return owner, true
+}
+
+// hostname returns the host name of this machine, or empty if it is unknown
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return name
+}
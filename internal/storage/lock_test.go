@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireLock(t *testing.T) {
+	root := t.TempDir()
+	lockPath := filepath.Join(root, ".atat", LockFilename)
+
+	lock, err := AcquireLock(root, 0)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+	content, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatalf("failed to read lock file: %v", err)
+	}
+	owner, ok := readLockOwner(lockPath)
+	if !ok || owner.pid != os.Getpid() || owner.host != hostname() || time.Since(owner.started) > time.Minute {
+		t.Errorf("unexpected lock file content %q", content)
+	}
+
+	if _, err := AcquireLock(root, 150*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked while the lock is held, got %v", err)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("expected lock file to be removed, got %v", err)
+	}
+
+	lock, err = AcquireLock(root, 0)
+	if err != nil {
+		t.Fatalf("AcquireLock after release failed: %v", err)
+	}
+	lock.Release()
+}
+
+func TestAcquireLockWaitsForRelease(t *testing.T) {
+	root := t.TempDir()
+
+	lock, err := AcquireLock(root, 0)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		lock.Release()
+	}()
+
+	second, err := AcquireLock(root, 5*time.Second)
+	if err != nil {
+		t.Fatalf("expected to acquire the lock after it was released, got %v", err)
+	}
+	second.Release()
+}
+
+func TestAcquireLockTakesOverStaleLock(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".atat"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	// Process IDs are far below this on supported systems
+	if err := os.WriteFile(filepath.Join(root, ".atat", LockFilename), []byte("2147483646\n"), 0644); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+
+	lock, err := AcquireLock(root, 0)
+	if err != nil {
+		t.Fatalf("expected stale lock to be taken over, got %v", err)
+	}
+	lock.Release()
+}
+
+func TestAcquireLockStaleness(t *testing.T) {
+	// Process IDs are far below this on supported systems
+	const deadPID = 2147483646
+
+	tests := []struct {
+		name     string
+		content  string
+		takeOver bool
+	}{
+		{
+			name:     "running process of this host",
+			content:  fmt.Sprintf("%d\n%s\n%s\n", os.Getpid(), hostname(), time.Now().UTC().Format(time.RFC3339)),
+			takeOver: false,
+		},
+		{
+			name:     "exited process of this host",
+			content:  fmt.Sprintf("%d\n%s\n%s\n", deadPID, hostname(), time.Now().UTC().Format(time.RFC3339)),
+			takeOver: true,
+		},
+		{
+			name:     "recent lock of another host",
+			content:  fmt.Sprintf("%d\nother-host\n%s\n", deadPID, time.Now().UTC().Format(time.RFC3339)),
+			takeOver: false,
+		},
+		{
+			name:     "old lock of a process that still runs",
+			content:  fmt.Sprintf("%d\n%s\n%s\n", os.Getpid(), hostname(), time.Now().Add(-2*lockStaleAge).UTC().Format(time.RFC3339)),
+			takeOver: true,
+		},
+		{
+			name:     "old lock of another host",
+			content:  fmt.Sprintf("%d\nother-host\n%s\n", os.Getpid(), time.Now().Add(-2*lockStaleAge).UTC().Format(time.RFC3339)),
+			takeOver: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(root, ".atat"), 0755); err != nil {
+				t.Fatalf("failed to create directory: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(root, ".atat", LockFilename), []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write lock file: %v", err)
+			}
+
+			lock, err := AcquireLock(root, 0)
+			if tt.takeOver {
+				if err != nil {
+					t.Fatalf("expected the lock to be taken over, got %v", err)
+				}
+				lock.Release()
+			} else if !errors.Is(err, ErrLocked) {
+				t.Errorf("expected ErrLocked, got %v", err)
+			}
+		})
+	}
+}
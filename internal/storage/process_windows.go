@@ -0,0 +1,21 @@
+//go:build windows
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// processExists reports whether a process with the given ID is running.
+// FindProcess opens the process on Windows, so it fails if the process doesn't exist,
+// except when access to it is denied.
+func processExists(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return errors.Is(err, syscall.ERROR_ACCESS_DENIED)
+	}
+	process.Release()
+	return true
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FileState identifies the content of a file at the time it was read
+type FileState struct {
+	ModTime time.Time
+	Size    int64
+	Hash    [sha256.Size]byte
+}
+
+// Changed reports whether the file was modified between the two states.
+// Files touched without changing their content are not considered changed.
+func (s FileState) Changed(other FileState) bool {
+	return s.Hash != other.Hash
+}
+
+// ReadFileState reads the file at path and returns its content and state.
+// Errors from reading the file are returned as is, so callers can check for os.ErrNotExist.
+func ReadFileState(path string) ([]byte, FileState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, FileState{}, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, FileState{}, err
+	}
+	return content, FileState{ModTime: info.ModTime(), Size: int64(len(content)), Hash: sha256.Sum256(content)}, nil
+}
+
+// CheckFileState reports whether the file at path changed since it was in state.
+// The content is only read and hashed when the modification time or size differs.
+// Returns the current content and state when the file changed.
+func CheckFileState(path string, state FileState) (bool, []byte, FileState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, nil, FileState{}, fmt.Errorf("failed to check %s: %w", path, err)
+	}
+	if info.ModTime().Equal(state.ModTime) && info.Size() == state.Size {
+		return false, nil, state, nil
+	}
+
+	content, current, err := ReadFileState(path)
+	if err != nil {
+		return false, nil, FileState{}, fmt.Errorf("failed to check %s: %w", path, err)
+	}
+	return current.Changed(state), content, current, nil
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckFileState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "TODO.md")
+	if err := os.WriteFile(path, []byte("- [ ] Task\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	content, state, err := ReadFileState(path)
+	if err != nil {
+		t.Fatalf("ReadFileState failed: %v", err)
+	}
+	if string(content) != "- [ ] Task\n" {
+		t.Errorf("unexpected content %q", content)
+	}
+
+	if changed, _, _, err := CheckFileState(path, state); err != nil || changed {
+		t.Errorf("expected unchanged file, got changed = %v, err = %v", changed, err)
+	}
+
+	// Touching the file without changing its content is not a change
+	later := state.ModTime.Add(2 * time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("failed to touch file: %v", err)
+	}
+	if changed, _, _, err := CheckFileState(path, state); err != nil || changed {
+		t.Errorf("expected touched file to be unchanged, got changed = %v, err = %v", changed, err)
+	}
+
+	if err := os.WriteFile(path, []byte("- [x] Task\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	changed, content, current, err := CheckFileState(path, state)
+	if err != nil || !changed {
+		t.Fatalf("expected changed file, got changed = %v, err = %v", changed, err)
+	}
+	if string(content) != "- [x] Task\n" || !current.Changed(state) {
+		t.Errorf("unexpected current content %q", content)
+	}
+}
+
+func TestCheckFileStateMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "TODO.md")
+
+	if _, _, _, err := CheckFileState(path, FileState{}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
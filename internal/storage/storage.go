@@ -22,21 +22,24 @@ type ConfigStorage interface {
 
 // LocalConfigStorage is a file-based local configuration persistence implementation
 type LocalConfigStorage struct {
+	scope      config.Scope
 	configPath string
 	configDir  string
 }
 
-// NewLocalConfigStorage creates a new LocalConfigStorage instance
+// NewLocalConfigStorage creates a new LocalConfigStorage instance for the project
+// containing the current directory
 func NewLocalConfigStorage() (*LocalConfigStorage, error) {
-	currentDir, err := os.Getwd()
+	rootDir, err := ProjectRoot()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current directory: %w", err)
+		return nil, err
 	}
 
-	configDir := filepath.Join(currentDir, config.ProjectConfigDir)
+	configDir := filepath.Join(rootDir, config.ProjectConfigDir)
 	configPath := filepath.Join(configDir, config.ProjectConfigFilename)
 
 	return &LocalConfigStorage{
+		scope:      config.ScopeProject,
 		configPath: configPath,
 		configDir:  configDir,
 	}, nil
@@ -46,7 +49,7 @@ func NewLocalConfigStorage() (*LocalConfigStorage, error) {
 func (s *LocalConfigStorage) LoadConfig() (map[config.ConfigKey]any, error) {
 	content, err := readFileBytes(s.configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read project config file at %s: %w", s.configPath, err)
+		return nil, fmt.Errorf("failed to read %s config file at %s: %w", s.scope, s.configPath, err)
 	}
 
 	return config.ParseConfig(content)
@@ -57,15 +60,16 @@ func (s *LocalConfigStorage) SaveConfig(configData map[config.ConfigKey]any) err
 	// Create config directory if it doesn't exist
 	if _, err := os.Stat(s.configDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(s.configDir, 0755); err != nil {
-			return fmt.Errorf("failed to create project config directory at %s: %w", s.configDir, err)
+			return fmt.Errorf("failed to create %s config directory at %s: %w", s.scope, s.configDir, err)
 		}
 	}
 
-	// Convert map to JSON
+	// Convert map to JSON, stamped with the schema version it follows
 	jsonMap := make(map[string]any)
 	for key, value := range configData {
 		jsonMap[string(key)] = value
 	}
+	jsonMap[config.VersionKey] = config.CurrentVersion
 
 	// Serialize to pretty JSON
 	contentBytes, err := json.MarshalIndent(jsonMap, "", "  ")
@@ -74,13 +78,44 @@ func (s *LocalConfigStorage) SaveConfig(configData map[config.ConfigKey]any) err
 	}
 
 	// Write to file
-	if err := os.WriteFile(s.configPath, contentBytes, 0644); err != nil {
-		return fmt.Errorf("failed to write to project config file at %s: %w", s.configPath, err)
+	if err := WriteFileAtomic(s.configPath, contentBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write to %s config file at %s: %w", s.scope, s.configPath, err)
 	}
 
 	return nil
 }
 
+// ProjectRoot returns the root directory of the project containing the current directory
+func ProjectRoot() (string, error) {
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	return FindProjectRoot(currentDir), nil
+}
+
+// FindProjectRoot returns the nearest directory at or above dir that contains a
+// .atat directory or a .git entry, the way git finds its repository.
+// Returns dir if no parent directory contains either.
+func FindProjectRoot(dir string) string {
+	for current := dir; ; {
+		if info, err := os.Stat(filepath.Join(current, config.ProjectConfigDir)); err == nil && info.IsDir() {
+			return current
+		}
+		// .git is a file in worktrees and submodules
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
 // readFileBytes reads the content of the file at the specified path into a byte slice.
 //
 // - Returns an empty byte slice if the file does not exist.
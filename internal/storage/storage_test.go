@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	mkdir := func(path string) string {
+		t.Helper()
+		dir := filepath.Join(root, path)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+		return dir
+	}
+
+	mkdir("git/.git")
+	mkdir("git/atat/.atat")
+	gitNested := mkdir("git/src/pkg")
+	atatNested := mkdir("git/atat/docs")
+	worktree := mkdir("worktree")
+	if err := os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: elsewhere\n"), 0644); err != nil {
+		t.Fatalf("failed to write .git file: %v", err)
+	}
+	worktreeNested := mkdir("worktree/sub")
+	fileNamedAtat := mkdir("plain/dir")
+	if err := os.WriteFile(filepath.Join(root, "plain", ".atat"), nil, 0644); err != nil {
+		t.Fatalf("failed to write .atat file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		dir      string
+		expected string
+	}{
+		{"git root itself", filepath.Join(root, "git"), filepath.Join(root, "git")},
+		{"below git root", gitNested, filepath.Join(root, "git")},
+		{"nearest .atat wins", atatNested, filepath.Join(root, "git", "atat")},
+		{".git file in worktree", worktreeNested, worktree},
+		{".atat file is ignored", fileNamedAtat, fileNamedAtat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := FindProjectRoot(tt.dir); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package todo
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Rebase applies the changes a sync made to a file's items onto the items the file
+// holds now, after it was edited while the sync was running.
+//
+// base is what the sync read, updated is what the sync computed from base, and current
+// is what the file holds now. Items are paired across versions by ID, by issue reference,
+// or by text for items without either. For each field of an item, changes made in the file win
+// over changes made by the sync. Items added by the sync are placed at the end of their
+// section, and items removed by the sync are removed unless they were edited in the file.
+func Rebase(base, updated, current []TodoItem) []TodoItem {
+	toUpdated := matchItems(base, updated)
+	toBase := matchItems(current, base)
+
+	updatedMatched := make([]bool, len(updated))
+	for _, u := range toUpdated {
+		if u >= 0 {
+			updatedMatched[u] = true
+		}
+	}
+
+	var result []TodoItem
+	var moved []TodoItem
+	for i, item := range current {
+		b := toBase[i]
+		if b < 0 {
+			// Added in the file
+			result = append(result, item)
+			continue
+		}
+
+		u := toUpdated[b]
+		if u < 0 {
+			// Removed by the sync, unless it was edited in the file since
+			if !itemsEqual(item, base[b]) {
+				result = append(result, item)
+			}
+			continue
+		}
+
+		merged := mergeItem(base[b], updated[u], item)
+		if merged.Section != item.Section {
+			moved = append(moved, merged)
+			continue
+		}
+		result = append(result, merged)
+	}
+
+	for _, item := range moved {
+		result = InsertInSection(result, item)
+	}
+	for u, item := range updated {
+		if !updatedMatched[u] {
+			result = InsertInSection(result, item)
+		}
+	}
+
+	return result
+}
+
+// matchItems pairs each item of from with the item of to representing the same task.
+//
+// Items are first paired by ID, then by issue reference, or by text for items without
+// one. Remaining items are then paired by text, which follows issue references that were
+// added, changed or removed. Items with different IDs are never paired. Returns the index in to for each item of from, or -1.
+func matchItems(from, to []TodoItem) []int {
+	matches := make([]int, len(from))
+	for i := range matches {
+		matches[i] = -1
+	}
+	used := make([]bool, len(to))
+
+	passes := []func(a, b TodoItem) bool{
+		func(a, b TodoItem) bool { return a.ID != "" && a.ID == b.ID },
+		func(a, b TodoItem) bool { return compatibleIDs(a, b) && itemKey(a) == itemKey(b) },
+		func(a, b TodoItem) bool { return compatibleIDs(a, b) && a.Text == b.Text },
+	}
+	for _, same := range passes {
+		for i := range from {
+			if matches[i] >= 0 {
+				continue
+			}
+			for j := range to {
+				if !used[j] && same(from[i], to[j]) {
+					matches[i] = j
+					used[j] = true
+					break
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// itemKey identifies an item by its issue reference, or by its text if it has none
+func itemKey(item TodoItem) string {
+	switch {
+	case item.ExternalIssue != nil:
+		return fmt.Sprintf("%s#%d", item.ExternalIssue.Repo, item.ExternalIssue.Number)
+	case item.IssueNumber != nil:
+		return fmt.Sprintf("#%d", *item.IssueNumber)
+	default:
+		return "text:" + item.Text
+	}
+}
+
+// compatibleIDs reports whether two items may be the same task, which they are not if
+// both have an ID and the IDs differ
+func compatibleIDs(a, b TodoItem) bool {
+	return a.ID == "" || b.ID == "" || a.ID == b.ID
+}
+
+// mergeItem merges the changes from base to ours and from base to theirs field by field.
+// Fields changed in theirs keep their value; other fields take the value of ours.
+func mergeItem(base, ours, theirs TodoItem) TodoItem {
+	merged := theirs
+	if theirs.Text == base.Text && theirs.Markdown == base.Markdown {
+		merged.Text = ours.Text
+		merged.Markdown = ours.Markdown
+	}
+	if theirs.IsChecked == base.IsChecked && theirs.IsCancelled == base.IsCancelled {
+		merged.IsChecked = ours.IsChecked
+		merged.IsCancelled = ours.IsCancelled
+	}
+	if sameIssue(theirs, base) {
+		merged.IssueNumber = ours.IssueNumber
+		merged.ExternalIssue = ours.ExternalIssue
+	}
+	if sameDueDate(theirs.DueDate, base.DueDate) {
+		merged.DueDate = ours.DueDate
+	}
+	if theirs.Section == base.Section {
+		merged.Section = ours.Section
+	}
+	if slices.Equal(theirs.PullRequests, base.PullRequests) {
+		merged.PullRequests = ours.PullRequests
+	}
+	if theirs.ID == base.ID {
+		merged.ID = ours.ID
+	}
+	return merged
+}
+
+// InsertInSection inserts item after the last item of its section, or at the end
+// if no item is in that section
+func InsertInSection(items []TodoItem, item TodoItem) []TodoItem {
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i].Section == item.Section {
+			return slices.Insert(items, i+1, item)
+		}
+	}
+	return append(items, item)
+}
+
+// itemsEqual reports whether two items have the same content
+func itemsEqual(a, b TodoItem) bool {
+	return a.Text == b.Text &&
+		a.Markdown == b.Markdown &&
+		a.IsChecked == b.IsChecked &&
+		a.IsCancelled == b.IsCancelled &&
+		sameIssue(a, b) &&
+		sameDueDate(a.DueDate, b.DueDate) &&
+		a.Section == b.Section &&
+		slices.Equal(a.PullRequests, b.PullRequests) &&
+		a.ID == b.ID
+}
+
+// sameIssue reports whether two items reference the same issue
+func sameIssue(a, b TodoItem) bool {
+	switch {
+	case a.ExternalIssue != nil || b.ExternalIssue != nil:
+		return a.ExternalIssue != nil && b.ExternalIssue != nil && *a.ExternalIssue == *b.ExternalIssue
+	case a.IssueNumber != nil || b.IssueNumber != nil:
+		return a.IssueNumber != nil && b.IssueNumber != nil && *a.IssueNumber == *b.IssueNumber
+	default:
+		return true
+	}
+}
+
+// sameDueDate reports whether two due dates are both unset or the same time
+func sameDueDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,145 @@
+package todo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func issue(number uint64) *uint64 {
+	return &number
+}
+
+func TestRebaseAppliesSyncChangesToEditedFile(t *testing.T) {
+	base := []TodoItem{
+		{Text: "New task"},
+		{Text: "Existing task", IssueNumber: issue(1)},
+		{Text: "Renamed on GitHub", IssueNumber: issue(2)},
+	}
+	updated := []TodoItem{
+		{Text: "New task", IssueNumber: issue(10)},
+		{Text: "Existing task", IsChecked: true, IssueNumber: issue(1)},
+		{Text: "New title", IssueNumber: issue(2)},
+		{Text: "Pulled issue", IssueNumber: issue(11)},
+	}
+	current := []TodoItem{
+		{Text: "Added in editor"},
+		{Text: "New task"},
+		{Text: "Existing task", IssueNumber: issue(1), DueDate: date(2026, 11, 1)},
+		{Text: "Renamed on GitHub", IssueNumber: issue(2)},
+	}
+
+	actual := Rebase(base, updated, current)
+
+	expected := []TodoItem{
+		{Text: "Added in editor"},
+		{Text: "New task", IssueNumber: issue(10)},
+		{Text: "Existing task", IsChecked: true, IssueNumber: issue(1), DueDate: date(2026, 11, 1)},
+		{Text: "New title", IssueNumber: issue(2)},
+		{Text: "Pulled issue", IssueNumber: issue(11)},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestRebaseKeepsChangesMadeInFile(t *testing.T) {
+	base := []TodoItem{{Text: "Task", IssueNumber: issue(1)}}
+	updated := []TodoItem{{Text: "Title from GitHub", IsChecked: true, IssueNumber: issue(1)}}
+	current := []TodoItem{{Text: "Edited task", IssueNumber: issue(1)}}
+
+	actual := Rebase(base, updated, current)
+
+	expected := []TodoItem{{Text: "Edited task", IsChecked: true, IssueNumber: issue(1)}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestRebaseKeepsMarkdownChangedInFile(t *testing.T) {
+	base := []TodoItem{{Text: "Fix login bug", IssueNumber: issue(1)}}
+	updated := []TodoItem{{Text: "Fix login bug", IsChecked: true, IssueNumber: issue(1)}}
+	current := []TodoItem{{Text: "Fix login bug", Markdown: "Fix **login** bug", IssueNumber: issue(1)}}
+
+	actual := Rebase(base, updated, current)
+
+	expected := []TodoItem{{Text: "Fix login bug", Markdown: "Fix **login** bug", IsChecked: true, IssueNumber: issue(1)}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestRebaseRemovedItems(t *testing.T) {
+	base := []TodoItem{
+		{Text: "Cleaned", IsChecked: true, IssueNumber: issue(1)},
+		{Text: "Cleaned but reopened", IsChecked: true, IssueNumber: issue(2)},
+		{Text: "Deleted in editor", IssueNumber: issue(3)},
+	}
+	updated := []TodoItem{
+		{Text: "Deleted in editor", IsChecked: true, IssueNumber: issue(3)},
+	}
+	current := []TodoItem{
+		{Text: "Cleaned", IsChecked: true, IssueNumber: issue(1)},
+		{Text: "Cleaned but reopened", IssueNumber: issue(2)},
+	}
+
+	actual := Rebase(base, updated, current)
+
+	expected := []TodoItem{{Text: "Cleaned but reopened", IssueNumber: issue(2)}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestRebasePlacesItemsInTheirSection(t *testing.T) {
+	todoSection := Section{Title: "Todo", Level: 2}
+	doneSection := Section{Title: "Done", Level: 2}
+	base := []TodoItem{
+		{Text: "First", IssueNumber: issue(1), Section: todoSection},
+		{Text: "Second", IssueNumber: issue(2), Section: todoSection},
+		{Text: "Finished", IssueNumber: issue(3), Section: doneSection},
+	}
+	updated := []TodoItem{
+		{Text: "Second", IssueNumber: issue(2), Section: todoSection},
+		{Text: "Pulled", IssueNumber: issue(4), Section: todoSection},
+		{Text: "Finished", IssueNumber: issue(3), Section: doneSection},
+		{Text: "First", IssueNumber: issue(1), Section: doneSection},
+	}
+	current := base
+
+	actual := Rebase(base, updated, current)
+
+	expected := []TodoItem{
+		{Text: "Second", IssueNumber: issue(2), Section: todoSection},
+		{Text: "Pulled", IssueNumber: issue(4), Section: todoSection},
+		{Text: "Finished", IssueNumber: issue(3), Section: doneSection},
+		{Text: "First", IssueNumber: issue(1), Section: doneSection},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestRebasePairsItemsByID(t *testing.T) {
+	base := []TodoItem{
+		{Text: "Task", ID: "a"},
+		{Text: "Task", ID: "b"},
+	}
+	updated := []TodoItem{
+		{Text: "Task", ID: "a", IssueNumber: issue(1)},
+		{Text: "Task", ID: "b", IssueNumber: issue(2)},
+	}
+	current := []TodoItem{
+		{Text: "Task", ID: "b"},
+		{Text: "Edited task", ID: "a"},
+	}
+
+	actual := Rebase(base, updated, current)
+
+	expected := []TodoItem{
+		{Text: "Task", ID: "b", IssueNumber: issue(2)},
+		{Text: "Edited task", ID: "a", IssueNumber: issue(1)},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
@@ -1,8 +1,88 @@
 package todo
 
+import "time"
+
+// DueDateLayout is the layout of due dates written as "due:YYYY-MM-DD" in task text.
+const DueDateLayout = "2006-01-02"
+
 // TodoItem represents a single todo item from a markdown checklist.
+// A cancelled item is also checked, as it needs no further work.
 type TodoItem struct {
-	Text        string
+	// Text is the plain text of the task, used as the title of its issue
+	Text string
+	// Markdown is the inline markdown the task was written with, such as links, emphasis
+	// and code spans, when it differs from Text. It is written back as long as Text is
+	// still its plain form.
+	Markdown    string
 	IsChecked   bool
+	IsCancelled bool
 	IssueNumber *uint64
+	// ExternalIssue references an issue in another repository, for example
+	// after the issue was transferred. IssueNumber is nil in that case.
+	ExternalIssue *IssueRef
+	DueDate       *time.Time
+	Section       Section
+	// PullRequests lists the pull requests linked to the item's issue
+	PullRequests []uint64
+	// ID is a hidden identifier written with the item, so it can be found again after
+	// its text is edited. Empty if the item has none.
+	ID string
+}
+
+// IssueRef references an issue in a specific repository.
+type IssueRef struct {
+	Repo   string
+	Number uint64
+}
+
+// Section represents the markdown heading a todo item is placed under.
+// The zero value means the item is not under any heading.
+type Section struct {
+	Title string
+	Level int
+}
+
+// AssignIDs returns the items with an ID given to each item that has none.
+// IDs are taken from newID, skipping IDs already used by other items.
+func AssignIDs(items []TodoItem, newID func() string) []TodoItem {
+	used := make(map[string]bool)
+	for _, item := range items {
+		if item.ID != "" {
+			used[item.ID] = true
+		}
+	}
+
+	assigned := make([]TodoItem, len(items))
+	copy(assigned, items)
+	for i := range assigned {
+		if assigned[i].ID != "" {
+			continue
+		}
+		id := newID()
+		for used[id] {
+			id = newID()
+		}
+		used[id] = true
+		assigned[i].ID = id
+	}
+	return assigned
+}
+
+// FilterOverdue returns unchecked items whose due date is before today.
+// Due dates are compared as calendar dates, so an item due today is not overdue.
+func FilterOverdue(items []TodoItem, today time.Time) []TodoItem {
+	todayDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	var overdue []TodoItem
+	for _, item := range items {
+		if item.IsChecked || item.DueDate == nil {
+			continue
+		}
+		dueDate := item.DueDate.UTC()
+		dueDate = time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, time.UTC)
+		if dueDate.Before(todayDate) {
+			overdue = append(overdue, item)
+		}
+	}
+	return overdue
 }
@@ -0,0 +1,62 @@
+package todo
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) *time.Time {
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &d
+}
+
+func TestFilterOverdueReturnsUncheckedItemsDueBeforeToday(t *testing.T) {
+	items := []TodoItem{
+		{Text: "Past due", IsChecked: false, DueDate: date(2026, 10, 1)},
+		{Text: "Due today", IsChecked: false, DueDate: date(2026, 10, 18)},
+		{Text: "Due later", IsChecked: false, DueDate: date(2026, 11, 1)},
+		{Text: "No due date", IsChecked: false},
+		{Text: "Done but past due", IsChecked: true, DueDate: date(2026, 9, 1)},
+	}
+
+	today := time.Date(2026, 10, 18, 23, 30, 0, 0, time.Local)
+	overdue := FilterOverdue(items, today)
+
+	if len(overdue) != 1 {
+		t.Fatalf("expected 1 overdue item, got %d", len(overdue))
+	}
+	if overdue[0].Text != "Past due" {
+		t.Errorf("expected 'Past due', got '%s'", overdue[0].Text)
+	}
+}
+
+func TestFilterOverdueEmptyInput(t *testing.T) {
+	overdue := FilterOverdue(nil, time.Now())
+
+	if len(overdue) != 0 {
+		t.Errorf("expected 0 overdue items, got %d", len(overdue))
+	}
+}
+
+func TestAssignIDs(t *testing.T) {
+	items := []TodoItem{
+		{Text: "Without ID"},
+		{Text: "With ID", ID: "b"},
+		{Text: "Another without ID"},
+	}
+	ids := []string{"a", "b", "c"}
+	newID := func() string {
+		id := ids[0]
+		ids = ids[1:]
+		return id
+	}
+
+	assigned := AssignIDs(items, newID)
+
+	if assigned[0].ID != "a" || assigned[1].ID != "b" || assigned[2].ID != "c" {
+		t.Errorf("expected IDs a, b and c, got %q, %q and %q", assigned[0].ID, assigned[1].ID, assigned[2].ID)
+	}
+	if items[0].ID != "" {
+		t.Error("expected input items unchanged")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/toms74209200/gh-atat/internal/errs"
 	"github.com/toms74209200/gh-atat/internal/run"
 )
 
@@ -12,6 +13,6 @@ var version string
 func main() {
 	if err := run.Run(os.Args, version); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-		os.Exit(1)
+		os.Exit(errs.ExitCode(err))
 	}
 }